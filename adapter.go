@@ -24,3 +24,18 @@ type Adapter interface {
 
 	Apply(ctx context.Context, migration Migration) error
 }
+
+// TransactionBeginner is an optional interface for adapter that supports beginning transaction with options.
+// Adapter should returns error when the requested option is not supported by the database.
+type TransactionBeginner interface {
+	BeginWith(ctx context.Context, options TransactionOptions) (Adapter, error)
+}
+
+// Savepointer is an optional interface for transaction adapter that supports savepoint.
+// When implemented, nested transaction will be performed using savepoint,
+// so a failure in nested transaction only rolls back changes made inside it.
+type Savepointer interface {
+	Savepoint(ctx context.Context, name string) error
+	RollbackTo(ctx context.Context, name string) error
+	Release(ctx context.Context, name string) error
+}
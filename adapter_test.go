@@ -66,6 +66,11 @@ func (ta *testAdapter) Begin(ctx context.Context) (Adapter, error) {
 	return ta, args.Error(0)
 }
 
+func (ta *testAdapter) BeginWith(ctx context.Context, options TransactionOptions) (Adapter, error) {
+	args := ta.Called(options)
+	return ta, args.Error(0)
+}
+
 func (ta *testAdapter) Commit(ctx context.Context) error {
 	args := ta.Called()
 	return args.Error(0)
@@ -90,3 +95,29 @@ func (ta *testAdapter) Exec(ctx context.Context, stmt string, args []interface{}
 	mockArgs := ta.Called(ctx, stmt, args)
 	return int64(mockArgs.Int(0)), int64(mockArgs.Int(1)), mockArgs.Error(2)
 }
+
+type testSavepointAdapter struct {
+	testAdapter
+}
+
+var _ Savepointer = (*testSavepointAdapter)(nil)
+
+func (tsa *testSavepointAdapter) Begin(ctx context.Context) (Adapter, error) {
+	args := tsa.Called()
+	return tsa, args.Error(0)
+}
+
+func (tsa *testSavepointAdapter) Savepoint(ctx context.Context, name string) error {
+	args := tsa.Called(name)
+	return args.Error(0)
+}
+
+func (tsa *testSavepointAdapter) RollbackTo(ctx context.Context, name string) error {
+	args := tsa.Called(name)
+	return args.Error(0)
+}
+
+func (tsa *testSavepointAdapter) Release(ctx context.Context, name string) error {
+	args := tsa.Called(name)
+	return args.Error(0)
+}
@@ -0,0 +1,104 @@
+// Package adaptertest provides conformance test suite for rel.Adapter implementation.
+//
+// The suite creates its own tables using migration, then drives rel.Repository through
+// queries, mutations, aggregations, transactions and associations. Every adapter is expected to pass
+// the same suite, so application code behaves the same regardless of which adapter is used.
+// Only portable update fragments (`field = ?` and `field = field op ?`) are covered,
+// on conflict fragment is database specific and is not part of the suite.
+//
+//	func TestAdapter(t *testing.T) {
+//		adapter := open()
+//		defer adapter.Close()
+//
+//		adaptertest.Run(t, adapter)
+//	}
+package adaptertest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-rel/rel"
+)
+
+// Run conformance test suite against adapter.
+// Existing users, addresses, posts, tags and iterator_checkpoints tables will be dropped.
+func Run(t *testing.T, adapter rel.Adapter) {
+	var (
+		ctx  = context.TODO()
+		repo = rel.New(adapter)
+	)
+
+	migrate(ctx, t, adapter)
+	defer rollback(ctx, t, adapter)
+
+	t.Run("Query", func(t *testing.T) {
+		reset(t, repo)
+		testQuery(t, repo)
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		reset(t, repo)
+		testFilter(t, repo)
+	})
+
+	t.Run("Aggregate", func(t *testing.T) {
+		reset(t, repo)
+		testAggregate(t, repo)
+	})
+
+	t.Run("Paginate", func(t *testing.T) {
+		reset(t, repo)
+		testPaginate(t, repo)
+	})
+
+	t.Run("Insert", func(t *testing.T) {
+		reset(t, repo)
+		testInsert(t, repo)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		reset(t, repo)
+		testUpdate(t, repo)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		reset(t, repo)
+		testDelete(t, repo)
+	})
+
+	t.Run("OnConflict", func(t *testing.T) {
+		reset(t, repo)
+		testOnConflict(t, repo)
+	})
+
+	t.Run("ConstraintError", func(t *testing.T) {
+		reset(t, repo)
+		testConstraintError(t, repo)
+	})
+
+	t.Run("SoftDelete", func(t *testing.T) {
+		reset(t, repo)
+		testSoftDelete(t, repo)
+	})
+
+	t.Run("LockVersion", func(t *testing.T) {
+		reset(t, repo)
+		testLockVersion(t, repo)
+	})
+
+	t.Run("Preload", func(t *testing.T) {
+		reset(t, repo)
+		testPreload(t, repo)
+	})
+
+	t.Run("Cascade", func(t *testing.T) {
+		reset(t, repo)
+		testCascade(t, repo)
+	})
+
+	t.Run("Transaction", func(t *testing.T) {
+		reset(t, repo)
+		testTransaction(t, repo)
+	})
+}
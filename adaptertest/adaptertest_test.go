@@ -0,0 +1,11 @@
+package adaptertest
+
+import (
+	"testing"
+
+	"github.com/go-rel/rel/memadapter"
+)
+
+func TestRun(t *testing.T) {
+	Run(t, memadapter.New())
+}
@@ -0,0 +1,112 @@
+package adaptertest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func testAggregate(t *testing.T, repo rel.Repository) {
+	seed(t, repo)
+
+	tests := []struct {
+		query  rel.Query
+		mode   string
+		field  string
+		result int
+	}{
+		{rel.From("users"), "count", "id", 4},
+		{rel.From("users"), "count", "note", 2},
+		{rel.From("users").Where(where.Eq("gender", "male")), "count", "id", 2},
+		{rel.From("users"), "sum", "age", 110},
+		{rel.From("users").Where(where.Eq("gender", "female")), "avg", "age", 30},
+		{rel.From("users"), "min", "age", 20},
+		{rel.From("users"), "max", "age", 35},
+		{rel.From("users").Where(where.Eq("name", "unknown")), "count", "id", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.mode+"("+test.field+") "+test.query.String(), func(t *testing.T) {
+			result, err := repo.Aggregate(context.TODO(), test.query, test.mode, test.field)
+			assert.Nil(t, err)
+			assert.Equal(t, test.result, result)
+		})
+	}
+
+	t.Run("Count", func(t *testing.T) {
+		count, err := repo.Count(context.TODO(), "users", where.Gte("age", 25))
+		assert.Nil(t, err)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		exists, err := repo.Exists(context.TODO(), "users", where.Eq("name", "John"))
+		assert.Nil(t, err)
+		assert.True(t, exists)
+
+		exists, err = repo.Exists(context.TODO(), "users", where.Eq("name", "unknown"))
+		assert.Nil(t, err)
+		assert.False(t, exists)
+
+		// exists selects raw literal instead of column named 1.
+		var ones []int
+		assert.Nil(t, repo.Pluck(context.TODO(), "^1", &ones, rel.From("users").Where(where.Eq("name", "John"))))
+		assert.Equal(t, []int{1}, ones)
+	})
+
+	t.Run("Aggregates", func(t *testing.T) {
+		result, err := repo.Aggregates(context.TODO(), rel.From("users").Where(where.Gte("age", 25)),
+			rel.Count("*"), rel.CountDistinct("gender"), rel.Sum("age"), rel.Avg("age"), rel.Min("created_at"), rel.Max("age").As("oldest"))
+		assert.Nil(t, err)
+		assert.Equal(t, 3, result.Int("count"))
+		assert.Equal(t, 2, result.Int("count_distinct_gender"))
+		assert.Equal(t, 90, result.Int("sum_age"))
+		assert.Equal(t, 30.0, result.Float("avg_age"))
+		assert.False(t, result.Time("min_created_at").IsZero())
+		assert.Equal(t, 35, result.Int("oldest"))
+		assert.ElementsMatch(t, []string{"count", "count_distinct_gender", "sum_age", "avg_age", "min_created_at", "oldest"}, keys(result))
+	})
+
+	t.Run("Aggregates fraction", func(t *testing.T) {
+		result, err := repo.Aggregates(context.TODO(), rel.From("users").Where(where.Eq("gender", "male")), rel.Avg("age"))
+		assert.Nil(t, err)
+		assert.Equal(t, 25.0, result.Float("avg_age"))
+
+		result, err = repo.Aggregates(context.TODO(), rel.From("users").Where(where.In("name", "John", "Jane")), rel.Avg("age"))
+		assert.Nil(t, err)
+		assert.Equal(t, 22.5, result.Float("avg_age"))
+	})
+
+	t.Run("AggregateGroups", func(t *testing.T) {
+		results, err := repo.AggregateGroups(context.TODO(), rel.From("users").Group("gender").SortAsc("gender"),
+			rel.Count("*").As("total"), rel.Avg("age"), rel.Max("age"))
+		assert.Nil(t, err)
+		assert.Len(t, results, 2)
+
+		assert.Equal(t, "female", results[0]["gender"])
+		assert.Equal(t, 2, results[0].Int("total"))
+		assert.Equal(t, 30.0, results[0].Float("avg_age"))
+		assert.Equal(t, 35, results[0].Int("max_age"))
+
+		assert.Equal(t, "male", results[1]["gender"])
+		assert.Equal(t, 2, results[1].Int("total"))
+		assert.Equal(t, 25.0, results[1].Float("avg_age"))
+		assert.Equal(t, 30, results[1].Int("max_age"))
+
+		for _, result := range results {
+			assert.ElementsMatch(t, []string{"gender", "total", "avg_age", "max_age"}, keys(result))
+		}
+	})
+}
+
+func keys(result rel.AggregateResult) []string {
+	keys := make([]string, 0, len(result))
+	for key := range result {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
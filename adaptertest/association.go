@@ -0,0 +1,93 @@
+package adaptertest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/sort"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func testPreload(t *testing.T, repo rel.Repository) {
+	var (
+		ctx   = context.TODO()
+		users = seed(t, repo)
+	)
+
+	t.Run("Has many", func(t *testing.T) {
+		var user User
+		assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", users[0].ID)))
+		assert.Nil(t, repo.Preload(ctx, &user, "addresses"))
+		assert.Len(t, user.Addresses, 2)
+	})
+
+	t.Run("Has many collection", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result, sort.Asc("id")))
+		assert.Nil(t, repo.Preload(ctx, &result, "addresses"))
+		assert.Len(t, result[0].Addresses, 2)
+		assert.Len(t, result[1].Addresses, 1)
+		assert.Len(t, result[2].Addresses, 0)
+	})
+
+	t.Run("Has many with query", func(t *testing.T) {
+		var user User
+		assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", users[0].ID)))
+		assert.Nil(t, repo.Preload(ctx, &user, "addresses", where.Eq("name", "office")))
+		assert.Len(t, user.Addresses, 1)
+		assert.Equal(t, "office", user.Addresses[0].Name)
+	})
+
+	t.Run("Belongs to", func(t *testing.T) {
+		var addresses []Address
+		assert.Nil(t, repo.FindAll(ctx, &addresses, sort.Asc("id")))
+		assert.Nil(t, repo.Preload(ctx, &addresses, "user"))
+		assert.Len(t, addresses, 3)
+		assert.Equal(t, "John", addresses[0].User.Name)
+		assert.Equal(t, "Jane", addresses[2].User.Name)
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		var addresses []Address
+		assert.Nil(t, repo.FindAll(ctx, &addresses, sort.Asc("id")))
+		assert.Nil(t, repo.Preload(ctx, &addresses, "user"))
+		assert.Nil(t, repo.Preload(ctx, &addresses, "user.addresses"))
+		assert.Len(t, addresses[0].User.Addresses, 2)
+	})
+}
+
+func testCascade(t *testing.T, repo rel.Repository) {
+	var (
+		ctx   = context.TODO()
+		users = seed(t, repo)
+	)
+
+	t.Run("Update", func(t *testing.T) {
+		user := users[1]
+		user.Addresses[0].Name = "apartment"
+		user.Addresses = append(user.Addresses, Address{Name: "office"})
+
+		assert.Nil(t, repo.Update(ctx, &user))
+		assert.NotZero(t, user.Addresses[1].ID)
+		assert.Equal(t, 2, repo.MustCount(ctx, "addresses", where.Eq("user_id", user.ID)))
+		assert.Equal(t, 1, repo.MustCount(ctx, "addresses", where.Eq("user_id", user.ID).AndEq("name", "apartment")))
+	})
+
+	t.Run("Update without cascade", func(t *testing.T) {
+		user := users[1]
+		user.Addresses = nil
+
+		assert.Nil(t, repo.Update(ctx, &user, rel.Cascade(false)))
+		assert.Equal(t, 2, repo.MustCount(ctx, "addresses", where.Eq("user_id", user.ID)))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		user := users[0]
+
+		assert.Nil(t, repo.Delete(ctx, &user, rel.Cascade(true)))
+		assert.Equal(t, 0, repo.MustCount(ctx, "users", where.Eq("id", user.ID)))
+		assert.Equal(t, 0, repo.MustCount(ctx, "addresses", where.Eq("user_id", user.ID)))
+	})
+}
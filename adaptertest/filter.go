@@ -0,0 +1,48 @@
+package adaptertest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/sort"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func testFilter(t *testing.T, repo rel.Repository) {
+	seed(t, repo)
+
+	tests := []struct {
+		filter rel.FilterQuery
+		result []string
+	}{
+		{where.Eq("name", "John"), []string{"John"}},
+		{where.Ne("gender", "male"), []string{"Jane", "Mary"}},
+		{where.Lt("age", 25), []string{"John"}},
+		{where.Lte("age", 25), []string{"John", "Jane"}},
+		{where.Gt("age", 30), []string{"Mary"}},
+		{where.Gte("age", 30), []string{"Doe", "Mary"}},
+		{where.Nil("note"), []string{"Jane", "Doe"}},
+		{where.NotNil("note"), []string{"John", "Mary"}},
+		{where.In("name", "Jane", "Doe", "unknown"), []string{"Jane", "Doe"}},
+		{where.Nin("name", "Jane", "Doe"), []string{"John", "Mary"}},
+		{where.Like("name", "J%"), []string{"John", "Jane"}},
+		{where.Like("name", "_o%"), []string{"John", "Doe"}},
+		{where.NotLike("name", "%a%"), []string{"John", "Doe"}},
+		{where.Eq("gender", "male").AndGt("age", 20), []string{"Doe"}},
+		{where.Eq("name", "John").OrEq("name", "Mary"), []string{"John", "Mary"}},
+		{where.Not(where.Eq("gender", "male")), []string{"Jane", "Mary"}},
+		{where.Not(where.Eq("gender", "female"), where.Gt("age", 30)), []string{"John", "Jane", "Doe"}},
+		{where.And(where.Gte("age", 25), where.Or(where.Eq("name", "Jane"), where.NotNil("note"))), []string{"Jane", "Mary"}},
+		{where.Eq("note", "note").OrNil("note"), []string{"John", "Jane", "Doe"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filter.String(), func(t *testing.T) {
+			var result []User
+			assert.Nil(t, repo.FindAll(context.TODO(), &result, test.filter, sort.Asc("id")))
+			assert.Equal(t, test.result, names(result))
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package adaptertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/sort"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func testInsert(t *testing.T, repo rel.Repository) {
+	ctx := context.TODO()
+
+	t.Run("Insert", func(t *testing.T) {
+		var (
+			user   = User{Name: "John", Gender: "male", Age: 20, Note: strPtr("note")}
+			result User
+		)
+
+		assert.Nil(t, repo.Insert(ctx, &user))
+		assert.NotZero(t, user.ID)
+		assert.False(t, user.CreatedAt.IsZero())
+		assert.False(t, user.UpdatedAt.IsZero())
+
+		assert.Nil(t, repo.Find(ctx, &result, where.Eq("id", user.ID)))
+		assert.Equal(t, "John", result.Name)
+		assert.Equal(t, "male", result.Gender)
+		assert.Equal(t, 20, result.Age)
+		assert.Equal(t, "note", *result.Note)
+	})
+
+	t.Run("Insert with associations", func(t *testing.T) {
+		user := User{Name: "Jane", Addresses: []Address{{Name: "home"}, {Name: "office"}}}
+
+		assert.Nil(t, repo.Insert(ctx, &user))
+		assert.NotZero(t, user.Addresses[0].ID)
+		assert.NotZero(t, user.Addresses[1].ID)
+		assert.Equal(t, user.ID, *user.Addresses[0].UserID)
+		assert.Equal(t, 2, repo.MustCount(ctx, "addresses", where.Eq("user_id", user.ID)))
+	})
+
+	t.Run("Insert with belongs to", func(t *testing.T) {
+		address := Address{Name: "home", User: &User{Name: "Doe"}}
+
+		assert.Nil(t, repo.Insert(ctx, &address))
+		assert.NotZero(t, address.User.ID)
+		assert.Equal(t, address.User.ID, *address.UserID)
+	})
+
+	t.Run("InsertAll", func(t *testing.T) {
+		users := []User{{Name: "Mary", Age: 30}, {Name: "Paul", Age: 40}, {Name: "Rose", Age: 50}}
+
+		assert.Nil(t, repo.InsertAll(ctx, &users))
+		assert.NotZero(t, users[0].ID)
+		assert.NotEqual(t, users[0].ID, users[1].ID)
+		assert.NotEqual(t, users[1].ID, users[2].ID)
+
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result, where.In("id", users[0].ID, users[1].ID, users[2].ID), sort.Asc("age")))
+		assert.Equal(t, []string{"Mary", "Paul", "Rose"}, names(result))
+	})
+}
+
+func testUpdate(t *testing.T, repo rel.Repository) {
+	var (
+		ctx   = context.TODO()
+		users = seed(t, repo)
+	)
+
+	t.Run("Update", func(t *testing.T) {
+		var (
+			user   = users[0]
+			result User
+		)
+
+		user.Name = "Johnny"
+		user.Note = nil
+		assert.Nil(t, repo.Update(ctx, &user))
+
+		assert.Nil(t, repo.Find(ctx, &result, where.Eq("id", user.ID)))
+		assert.Equal(t, "Johnny", result.Name)
+		assert.Nil(t, result.Note)
+		assert.Equal(t, 20, result.Age)
+	})
+
+	t.Run("Update with mutators", func(t *testing.T) {
+		var (
+			user   = users[1]
+			result User
+		)
+
+		assert.Nil(t, repo.Update(ctx, &user, rel.Set("name", "Janet"), rel.Inc("age")))
+		assert.Nil(t, repo.Update(ctx, &user, rel.Dec("age")))
+		assert.Nil(t, repo.Update(ctx, &user, rel.IncBy("age", 10)))
+
+		assert.Nil(t, repo.Find(ctx, &result, where.Eq("id", user.ID)))
+		assert.Equal(t, "Janet", result.Name)
+		assert.Equal(t, 35, result.Age)
+	})
+
+	t.Run("Update not found", func(t *testing.T) {
+		user := User{ID: users[3].ID + 1000, Name: "unknown"}
+		assert.Equal(t, rel.NotFoundError{}, repo.Update(ctx, &user))
+	})
+
+	t.Run("UpdateAny", func(t *testing.T) {
+		updated, err := repo.UpdateAny(ctx, rel.From("users").Where(where.Eq("gender", "male")), rel.Set("note", "male"), rel.Inc("age"))
+		assert.Nil(t, err)
+		assert.Equal(t, 2, updated)
+
+		assert.Equal(t, 2, repo.MustCount(ctx, "users", where.Eq("note", "male")))
+		assert.Equal(t, 1, repo.MustCount(ctx, "users", where.Eq("age", 31)))
+	})
+
+	t.Run("UpdateAny fragment", func(t *testing.T) {
+		updated, err := repo.UpdateAny(ctx, rel.From("users").Where(where.Eq("gender", "female")),
+			rel.SetFragment("note = ?", "female"), rel.SetFragment("age = age * ?", 2))
+		assert.Nil(t, err)
+		assert.Equal(t, 2, updated)
+
+		assert.Equal(t, 2, repo.MustCount(ctx, "users", where.Eq("note", "female")))
+		assert.Equal(t, 2, repo.MustCount(ctx, "users", where.Eq("age", 70)))
+	})
+}
+
+func testDelete(t *testing.T, repo rel.Repository) {
+	var (
+		ctx   = context.TODO()
+		users = seed(t, repo)
+	)
+
+	t.Run("Delete", func(t *testing.T) {
+		user := users[3]
+		assert.Nil(t, repo.Delete(ctx, &user))
+		assert.Equal(t, 0, repo.MustCount(ctx, "users", where.Eq("id", user.ID)))
+		assert.Equal(t, rel.NotFoundError{}, repo.Delete(ctx, &user))
+	})
+
+	t.Run("DeleteAll", func(t *testing.T) {
+		addresses := users[0].Addresses
+		assert.Nil(t, repo.DeleteAll(ctx, &addresses))
+		assert.Equal(t, 0, repo.MustCount(ctx, "addresses", where.Eq("user_id", users[0].ID)))
+		assert.Equal(t, 1, repo.MustCount(ctx, "addresses"))
+	})
+
+	t.Run("DeleteAny", func(t *testing.T) {
+		deleted, err := repo.DeleteAny(ctx, rel.From("users").Where(where.Eq("gender", "male")))
+		assert.Nil(t, err)
+		assert.Equal(t, 2, deleted)
+		assert.Equal(t, 1, repo.MustCount(ctx, "users"))
+	})
+}
+
+func testOnConflict(t *testing.T, repo rel.Repository) {
+	var (
+		ctx = context.TODO()
+		tag = Tag{Slug: "go", Name: "Go"}
+	)
+
+	assert.Nil(t, repo.Insert(ctx, &tag))
+
+	t.Run("Ignore", func(t *testing.T) {
+		assert.Nil(t, repo.Insert(ctx, &Tag{ID: tag.ID, Slug: "golang", Name: "Golang"}, rel.OnConflictIgnore()))
+		assert.Equal(t, 1, repo.MustCount(ctx, "tags"))
+		assert.Equal(t, 1, repo.MustCount(ctx, "tags", where.Eq("name", "Go")))
+	})
+
+	t.Run("Key ignore", func(t *testing.T) {
+		assert.Nil(t, repo.Insert(ctx, &Tag{Slug: "go", Name: "Golang"}, rel.OnConflictKeyIgnore("slug")))
+		assert.Equal(t, 1, repo.MustCount(ctx, "tags"))
+	})
+
+	t.Run("Key replace", func(t *testing.T) {
+		assert.Nil(t, repo.Insert(ctx, &Tag{Slug: "go", Name: "Golang"}, rel.OnConflictKeyReplace("slug")))
+		assert.Equal(t, 1, repo.MustCount(ctx, "tags"))
+		assert.Equal(t, 1, repo.MustCount(ctx, "tags", where.Eq("name", "Golang")))
+	})
+
+	t.Run("InsertAll ignore", func(t *testing.T) {
+		tags := []Tag{{Slug: "go", Name: "Go"}, {Slug: "rust", Name: "Rust"}}
+		assert.Nil(t, repo.InsertAll(ctx, &tags, rel.OnConflictKeyIgnore("slug")))
+		assert.Equal(t, 2, repo.MustCount(ctx, "tags"))
+		assert.Equal(t, 1, repo.MustCount(ctx, "tags", where.Eq("name", "Golang")))
+	})
+
+	t.Run("No conflict handling", func(t *testing.T) {
+		assert.True(t, errors.Is(repo.Insert(ctx, &Tag{Slug: "go", Name: "Go"}), rel.ErrUniqueConstraint))
+	})
+}
+
+func testConstraintError(t *testing.T, repo rel.Repository) {
+	var (
+		ctx  = context.TODO()
+		user = User{Name: "John"}
+		tag  = Tag{Slug: "go", Name: "Go"}
+	)
+
+	repo.MustInsert(ctx, &user)
+	repo.MustInsert(ctx, &tag)
+
+	t.Run("Unique", func(t *testing.T) {
+		err := repo.Insert(ctx, &Tag{Slug: "go", Name: "Golang"})
+		assert.True(t, errors.Is(err, rel.ErrUniqueConstraint), "expected unique constraint error, got: %v", err)
+	})
+
+	t.Run("Unique on update", func(t *testing.T) {
+		other := Tag{Slug: "rust", Name: "Rust"}
+		repo.MustInsert(ctx, &other)
+
+		other.Slug = "go"
+		err := repo.Update(ctx, &other)
+		assert.True(t, errors.Is(err, rel.ErrUniqueConstraint), "expected unique constraint error, got: %v", err)
+	})
+
+	t.Run("Primary key", func(t *testing.T) {
+		err := repo.Insert(ctx, &User{ID: user.ID, Name: "Jane"})
+		assert.True(t, errors.Is(err, rel.ErrUniqueConstraint) || errors.Is(err, rel.ErrPrimaryKeyConstraint), "expected primary key constraint error, got: %v", err)
+	})
+
+	t.Run("Foreign key", func(t *testing.T) {
+		userID := user.ID + 1000
+		err := repo.Insert(ctx, &Address{UserID: &userID, Name: "home"})
+		assert.True(t, errors.Is(err, rel.ErrForeignKeyConstraint), "expected foreign key constraint error, got: %v", err)
+	})
+
+	t.Run("Foreign key on delete", func(t *testing.T) {
+		repo.MustInsert(ctx, &Address{UserID: &user.ID, Name: "home"})
+
+		err := repo.Delete(ctx, &user)
+		assert.True(t, errors.Is(err, rel.ErrForeignKeyConstraint), "expected foreign key constraint error, got: %v", err)
+	})
+}
+
+func testSoftDelete(t *testing.T, repo rel.Repository) {
+	var (
+		ctx   = context.TODO()
+		posts = []Post{{Title: "first"}, {Title: "second"}}
+		tags  = []Tag{{Slug: "go", Name: "Go"}, {Slug: "rust", Name: "Rust"}}
+	)
+
+	repo.MustInsertAll(ctx, &posts)
+	repo.MustInsertAll(ctx, &tags)
+
+	t.Run("DeletedAt", func(t *testing.T) {
+		assert.Nil(t, repo.Delete(ctx, &posts[0]))
+
+		var result []Post
+		assert.Nil(t, repo.FindAll(ctx, &result))
+		assert.Len(t, result, 1)
+		assert.Equal(t, "second", result[0].Title)
+
+		var deleted Post
+		assert.Nil(t, repo.Find(ctx, &deleted, where.Eq("id", posts[0].ID), rel.Unscoped(true)))
+		assert.NotNil(t, deleted.DeletedAt)
+	})
+
+	t.Run("Deleted", func(t *testing.T) {
+		assert.Nil(t, repo.Delete(ctx, &tags[1]))
+
+		var result []Tag
+		assert.Nil(t, repo.FindAll(ctx, &result))
+		assert.Len(t, result, 1)
+		assert.Equal(t, "go", result[0].Slug)
+
+		assert.Nil(t, repo.FindAll(ctx, &result, rel.Unscoped(true), sort.Asc("id")))
+		assert.Len(t, result, 2)
+		assert.True(t, result[1].Deleted)
+	})
+}
+
+func testLockVersion(t *testing.T, repo rel.Repository) {
+	var (
+		ctx  = context.TODO()
+		post = Post{Title: "first"}
+	)
+
+	repo.MustInsert(ctx, &post)
+
+	t.Run("Update", func(t *testing.T) {
+		stale := post
+
+		post.Title = "updated"
+		assert.Nil(t, repo.Update(ctx, &post))
+		assert.Equal(t, 1, post.LockVersion)
+
+		stale.Title = "stale"
+		assert.Equal(t, rel.NotFoundError{}, repo.Update(ctx, &stale))
+		assert.Equal(t, 0, stale.LockVersion)
+
+		var result Post
+		assert.Nil(t, repo.Find(ctx, &result, where.Eq("id", post.ID)))
+		assert.Equal(t, "updated", result.Title)
+		assert.Equal(t, 1, result.LockVersion)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		stale := post
+		stale.LockVersion = 0
+
+		assert.Equal(t, rel.NotFoundError{}, repo.Delete(ctx, &stale))
+		assert.Nil(t, repo.Delete(ctx, &post))
+
+		var result Post
+		assert.Nil(t, repo.Find(ctx, &result, where.Eq("id", post.ID), rel.Unscoped(true)))
+		assert.Equal(t, 2, result.LockVersion)
+	})
+}
@@ -0,0 +1,113 @@
+package adaptertest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/sort"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func testPaginate(t *testing.T, repo rel.Repository) {
+	var (
+		ctx       = context.TODO()
+		nullsLast bool
+	)
+
+	seed(t, repo)
+	seed(t, repo)
+
+	// detect how the database sorts null, so the suite works regardless of the adapter default.
+	var first User
+	if assert.Nil(t, repo.Find(ctx, &first, sort.Asc("note"), sort.Asc("id"))) {
+		nullsLast = first.Note != nil
+	}
+
+	tests := []struct {
+		name  string
+		query rel.Query
+	}{
+		{"primary", rel.From("users")},
+		{"desc", rel.From("users").SortDesc("age")},
+		{"mixed", rel.From("users").SortAsc("gender").SortDesc("age")},
+		{"nullable asc", rel.From("users").SortAsc("note")},
+		{"nullable desc", rel.From("users").SortDesc("note").SortAsc("name")},
+		{"filtered", rel.From("users").Where(where.Gte("age", 25)).SortDesc("note")},
+	}
+
+	for _, test := range tests {
+		var expected []User
+		if !assert.Nil(t, repo.FindAll(ctx, &expected, test.query.SortAsc("id"))) {
+			continue
+		}
+
+		for _, size := range []int{1, 3, len(expected)} {
+			t.Run(test.name, func(t *testing.T) {
+				var (
+					forward  []User
+					backward []User
+					page     rel.Page
+					options  = []rel.PaginateOption{rel.PageSize(size), rel.NullsLast(nullsLast), rel.PageSecret([]byte("secret"))}
+				)
+
+				for i := 0; i <= len(expected); i++ {
+					var result []User
+					page = repo.MustPaginate(ctx, &result, test.query, append(options, rel.After(page.Next))...)
+					assert.LessOrEqual(t, len(result), size)
+					assert.Equal(t, i > 0, page.HasPrevious())
+
+					forward = append(forward, result...)
+					if !page.HasNext() {
+						break
+					}
+				}
+
+				assert.Equal(t, ids(expected), ids(forward))
+
+				page = rel.Page{}
+				for i := 0; i <= len(expected); i++ {
+					var result []User
+					page = repo.MustPaginate(ctx, &result, test.query, append(options, rel.Before(page.Previous))...)
+					assert.Equal(t, i > 0, page.HasNext())
+
+					backward = append(result, backward...)
+					if !page.HasPrevious() {
+						break
+					}
+				}
+
+				assert.Equal(t, ids(expected), ids(backward))
+			})
+		}
+	}
+
+	t.Run("invalid token", func(t *testing.T) {
+		var (
+			result []User
+			secret = rel.PageSecret([]byte("secret"))
+			page   = repo.MustPaginate(ctx, &result, rel.From("users").SortDesc("age"), rel.PageSize(2), secret)
+		)
+
+		assert.True(t, page.HasNext())
+
+		_, err := repo.Paginate(ctx, &result, rel.From("users").SortAsc("age"), rel.After(page.Next), secret)
+		assert.Equal(t, rel.ErrInvalidPageToken, err)
+
+		_, err = repo.Paginate(ctx, &result, rel.From("users").SortDesc("age"), rel.After(page.Next), rel.PageSecret([]byte("other")))
+		assert.Equal(t, rel.ErrInvalidPageToken, err)
+
+		_, err = repo.Paginate(ctx, &result, rel.From("users").SortDesc("age"), rel.After(page.Next))
+		assert.Equal(t, rel.ErrPageSecretRequired, err)
+	})
+}
+
+func ids(users []User) []int {
+	result := make([]int, len(users))
+	for i := range users {
+		result[i] = users[i].ID
+	}
+
+	return result
+}
@@ -0,0 +1,482 @@
+package adaptertest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/join"
+	"github.com/go-rel/rel/sort"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func seed(t *testing.T, repo rel.Repository) []User {
+	users := []User{
+		{Name: "John", Gender: "male", Age: 20, Note: strPtr("note"), Addresses: []Address{{Name: "home"}, {Name: "office"}}},
+		{Name: "Jane", Gender: "female", Age: 25, Addresses: []Address{{Name: "home"}}},
+		{Name: "Doe", Gender: "male", Age: 30},
+		{Name: "Mary", Gender: "female", Age: 35, Note: strPtr("other note")},
+	}
+
+	for i := range users {
+		if err := repo.Insert(context.TODO(), &users[i]); err != nil {
+			t.Fatalf("adaptertest: failed to seed users: %v", err)
+		}
+	}
+
+	return users
+}
+
+func testQuery(t *testing.T, repo rel.Repository) {
+	var (
+		ctx   = context.TODO()
+		users = seed(t, repo)
+	)
+
+	t.Run("Find", func(t *testing.T) {
+		var user User
+		assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", users[1].ID)))
+		assert.Equal(t, "Jane", user.Name)
+		assert.Equal(t, 25, user.Age)
+		assert.Nil(t, user.Note)
+		assert.False(t, user.CreatedAt.IsZero())
+	})
+
+	t.Run("Find not found", func(t *testing.T) {
+		var user User
+		assert.Equal(t, rel.NotFoundError{}, repo.Find(ctx, &user, where.Eq("name", "unknown")))
+	})
+
+	t.Run("FindAll sort", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result, sort.Desc("age")))
+		assert.Len(t, result, 4)
+		assert.Equal(t, "Mary", result[0].Name)
+		assert.Equal(t, "John", result[3].Name)
+	})
+
+	t.Run("FindAll with", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result, rel.With("seniors", rel.From("users").Where(where.Gte("age", 30))).From("seniors"), sort.Asc("age")))
+		assert.Equal(t, []string{"Doe", "Mary"}, names(result))
+	})
+
+	t.Run("FindAll join with", func(t *testing.T) {
+		var result []Address
+		assert.Nil(t, repo.FindAll(ctx, &result,
+			rel.With("males", rel.From("users").Where(where.Eq("gender", "male"))).
+				From("addresses").
+				JoinOn("males", "addresses.user_id", "males.id").
+				Where(where.Eq("males.name", "John")),
+		))
+		assert.Len(t, result, 2)
+	})
+
+	t.Run("FindAll multiple sort", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result, sort.Asc("gender"), sort.Desc("age")))
+		assert.Equal(t, []string{"Mary", "Jane", "Doe", "John"}, names(result))
+	})
+
+	t.Run("FindAll limit offset", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result, sort.Asc("age"), rel.Limit(2), rel.Offset(1)))
+		assert.Equal(t, []string{"Jane", "Doe"}, names(result))
+	})
+
+	t.Run("FindAll select", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result, rel.Select("id", "name"), sort.Asc("id")))
+		assert.Len(t, result, 4)
+		assert.Equal(t, "John", result[0].Name)
+		assert.Equal(t, 0, result[0].Age)
+	})
+
+	t.Run("FindAll join", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result,
+			rel.Select("users.*").Distinct(),
+			join.On("addresses", "addresses.user_id", "users.id"),
+			where.Eq("addresses.name", "home"),
+			sort.Asc("users.id"),
+		))
+		assert.Equal(t, []string{"John", "Jane"}, names(result))
+	})
+
+	t.Run("FindAll left join", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result,
+			rel.Select("users.*"),
+			join.LeftOn("addresses", "addresses.user_id", "users.id"),
+			where.Nil("addresses.id"),
+			sort.Asc("users.id"),
+		))
+		assert.Equal(t, []string{"Doe", "Mary"}, names(result))
+	})
+
+	t.Run("FindAll group", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result,
+			rel.Select("gender", "count(id) AS age"),
+			rel.From("users").Group("gender").Having(where.Gt("count(id)", 1)),
+			sort.Asc("gender"),
+		))
+		assert.Len(t, result, 2)
+		assert.Equal(t, "female", result[0].Gender)
+		assert.Equal(t, 2, result[0].Age)
+	})
+
+	t.Run("FindAll subquery", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result,
+			where.In("id", rel.Select("user_id").From("addresses").Where(where.Eq("name", "office"))),
+		))
+		assert.Equal(t, []string{"John"}, names(result))
+	})
+
+	t.Run("FindAll derived table", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result,
+			rel.FromSub(rel.From("users").Where(where.Eq("gender", "female")).As("females")).
+				Where(where.Gt("females.age", 30)),
+		))
+		assert.Equal(t, []string{"Mary"}, names(result))
+	})
+
+	t.Run("FindAll correlated sub query", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result,
+			where.Gt("age", rel.Select("avg(u.age)").From("users u").Where(where.Eq("u.gender", rel.Ref("users.gender")))),
+			sort.Asc("id"),
+		))
+		assert.Equal(t, []string{"Doe", "Mary"}, names(result))
+	})
+
+	t.Run("FindAll exists", func(t *testing.T) {
+		var (
+			result    []User
+			addresses = rel.From("addresses").Where(where.Eq("addresses.user_id", rel.Ref("users.id")))
+		)
+
+		assert.Nil(t, repo.FindAll(ctx, &result, where.Exists(addresses), sort.Asc("id")))
+		assert.Equal(t, []string{"John", "Jane"}, names(result))
+
+		assert.Nil(t, repo.FindAll(ctx, &result, where.NotExists(addresses), sort.Asc("id")))
+		assert.Equal(t, []string{"Doe", "Mary"}, names(result))
+
+		assert.Nil(t, repo.FindAll(ctx, &result, where.Not(where.Exists(addresses.Where(where.Eq("addresses.name", "office")))), sort.Asc("id")))
+		assert.Equal(t, []string{"Jane", "Doe", "Mary"}, names(result))
+	})
+
+	t.Run("FindAll select sub query", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result,
+			rel.Select("id", "name").From("users").
+				SelectSub(rel.Select("count(id)").From("addresses").Where(where.Eq("addresses.user_id", rel.Ref("users.id"))).As("age")),
+			sort.Asc("id"),
+		))
+		assert.Equal(t, []string{"John", "Jane", "Doe", "Mary"}, names(result))
+		assert.Equal(t, []int{2, 1, 0, 0}, []int{result[0].Age, result[1].Age, result[2].Age, result[3].Age})
+	})
+
+	t.Run("FindAll union", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result,
+			rel.From("users").Where(where.Eq("gender", "male")).
+				Union(rel.From("users").Where(where.Gte("age", 30))),
+			sort.Asc("age"),
+		))
+		assert.Equal(t, []string{"John", "Doe", "Mary"}, names(result))
+	})
+
+	t.Run("FindAll except", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result,
+			rel.From("users").Where(where.Eq("gender", "male")).
+				Except(rel.From("users").Where(where.Gte("age", 30))),
+		))
+		assert.Equal(t, []string{"John"}, names(result))
+	})
+
+	t.Run("Scan", func(t *testing.T) {
+		var result struct {
+			Name   string `db:"name"`
+			Gender string `db:"gender"`
+		}
+
+		assert.Nil(t, repo.Scan(ctx, &result, rel.From("users").Where(where.Eq("name", "Jane"))))
+		assert.Equal(t, "Jane", result.Name)
+		assert.Equal(t, "female", result.Gender)
+
+		assert.True(t, errors.Is(repo.Scan(ctx, &result, rel.From("users"), rel.Strict(true)), rel.ErrUnknownColumn))
+		assert.Equal(t, rel.NotFoundError{}, repo.Scan(ctx, &result, rel.From("users").Where(where.Eq("name", "unknown"))))
+	})
+
+	t.Run("ScanAll", func(t *testing.T) {
+		var result []struct {
+			Gender string `db:"gender"`
+			Total  int    `db:"total"`
+		}
+
+		assert.Nil(t, repo.ScanAll(ctx, &result,
+			rel.Select("gender", "count(id) AS total").From("users").Group("gender").SortAsc("gender"),
+			rel.Strict(true),
+		))
+		assert.Len(t, result, 2)
+		assert.Equal(t, "female", result[0].Gender)
+		assert.Equal(t, 2, result[0].Total)
+		assert.Equal(t, "male", result[1].Gender)
+		assert.Equal(t, 2, result[1].Total)
+	})
+
+	t.Run("Pluck", func(t *testing.T) {
+		var names []string
+		assert.Nil(t, repo.Pluck(ctx, "name", &names, rel.From("users").Where(where.Eq("gender", "female")).SortAsc("id")))
+		assert.Equal(t, []string{"Jane", "Mary"}, names)
+
+		var notes []*string
+		assert.Nil(t, repo.Pluck(ctx, "note", &notes, rel.From("users").SortAsc("id")))
+		assert.Len(t, notes, 4)
+		assert.Equal(t, "note", *notes[0])
+		assert.Nil(t, notes[1])
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		exists, err := repo.Exists(ctx, "users", where.Eq("name", "Jane"))
+		assert.Nil(t, err)
+		assert.True(t, exists)
+
+		exists, err = repo.Exists(ctx, "users", where.Eq("name", "unknown"))
+		assert.Nil(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("First and Last", func(t *testing.T) {
+		var user User
+		assert.Nil(t, repo.First(ctx, &user, where.Eq("gender", "male"), sort.Desc("age")))
+		assert.Equal(t, "John", user.Name)
+
+		assert.Nil(t, repo.Last(ctx, &user, where.Eq("gender", "male")))
+		assert.Equal(t, "Doe", user.Name)
+
+		assert.Equal(t, rel.NotFoundError{}, repo.Last(ctx, &user, where.Eq("name", "unknown")))
+	})
+
+	t.Run("Find map", func(t *testing.T) {
+		var result rel.Map
+		assert.Nil(t, repo.Find(ctx, &result, rel.Select("id", "name", "age", "note").From("users").Where(where.Eq("name", "Jane"))))
+		assert.EqualValues(t, users[1].ID, result["id"])
+		assert.Equal(t, "Jane", result["name"])
+		assert.EqualValues(t, 25, result["age"])
+		assert.Nil(t, result["note"])
+	})
+
+	t.Run("FindAll map", func(t *testing.T) {
+		var result []rel.Map
+		assert.Nil(t, repo.FindAll(ctx, &result, rel.From("users"), where.Eq("gender", "male"), sort.Asc("id")))
+		assert.Len(t, result, 2)
+		assert.Equal(t, "John", result[0]["name"])
+		assert.Equal(t, "note", result[0]["note"])
+		assert.Equal(t, "Doe", result[1]["name"])
+		assert.IsType(t, time.Time{}, result[1]["created_at"])
+	})
+
+	t.Run("Iterate", func(t *testing.T) {
+		var (
+			result []User
+			it     = repo.Iterate(ctx, rel.From("users"), rel.BatchSize(3))
+		)
+
+		defer it.Close()
+		for {
+			var user User
+			if err := it.Next(&user); err == io.EOF {
+				break
+			} else if !assert.Nil(t, err) {
+				break
+			}
+
+			result = append(result, user)
+		}
+
+		assert.Len(t, result, 4)
+	})
+
+	t.Run("Iterate map", func(t *testing.T) {
+		var (
+			result []string
+			it     = repo.Iterate(ctx, rel.From("users"), rel.BatchSize(3))
+		)
+
+		defer it.Close()
+		for {
+			var record rel.Map
+			if err := it.Next(&record); err == io.EOF {
+				break
+			} else if !assert.Nil(t, err) {
+				break
+			}
+
+			result = append(result, record["name"].(string))
+		}
+
+		assert.Equal(t, []string{"John", "Jane", "Doe", "Mary"}, result)
+	})
+
+	t.Run("Iterate sort by", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			options []rel.IteratorOption
+			result  []string
+		}{
+			{
+				name:    "desc",
+				options: []rel.IteratorOption{rel.SortBy(rel.SortDesc("age")), rel.BatchSize(3)},
+				result:  []string{"Mary", "Doe", "Jane", "John"},
+			},
+			{
+				name:    "start and finish",
+				options: []rel.IteratorOption{rel.SortBy(rel.SortDesc("age")), rel.Start(30), rel.Finish(25), rel.BatchSize(1)},
+				result:  []string{"Doe", "Jane"},
+			},
+			{
+				name:    "tie breaker",
+				options: []rel.IteratorOption{rel.SortBy(rel.SortAsc("gender")), rel.BatchSize(1)},
+				result:  []string{"Jane", "Mary", "John", "Doe"},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				var (
+					result []string
+					it     = repo.Iterate(ctx, rel.From("users"), test.options...)
+				)
+
+				defer it.Close()
+				for {
+					var user User
+					if err := it.Next(&user); err == io.EOF {
+						break
+					} else if !assert.Nil(t, err) {
+						break
+					}
+
+					result = append(result, user.Name)
+				}
+
+				assert.Equal(t, test.result, result)
+			})
+		}
+	})
+	t.Run("Iterate preload", func(t *testing.T) {
+		var (
+			result = map[string]int{}
+			it     = repo.Iterate(ctx, rel.From("users"), rel.BatchSize(3), rel.IteratePreload("addresses"))
+		)
+
+		defer it.Close()
+		for {
+			var user User
+			if err := it.Next(&user); err == io.EOF {
+				break
+			} else if !assert.Nil(t, err) {
+				break
+			}
+
+			result[user.Name] = len(user.Addresses)
+		}
+
+		assert.Equal(t, map[string]int{"John": 2, "Jane": 1, "Doe": 0, "Mary": 0}, result)
+	})
+
+	t.Run("Iterate checkpoints", func(t *testing.T) {
+		var (
+			store   = rel.NewRepositoryCheckpointStore(repo)
+			iterate = func(n int) []string {
+				var (
+					result []string
+					it     = repo.Iterate(ctx, rel.From("users"), rel.BatchSize(3), rel.Checkpoints(store, "users"))
+				)
+
+				defer it.Close()
+				for len(result) < n {
+					var user User
+					if err := it.Next(&user); err == io.EOF {
+						break
+					} else if !assert.Nil(t, err) {
+						break
+					}
+
+					result = append(result, user.Name)
+				}
+
+				return result
+			}
+		)
+
+		// checkpoint is saved when the second batch is fetched, so it resumes after the first batch.
+		assert.Equal(t, []string{"John", "Jane", "Doe", "Mary"}, iterate(4))
+		assert.Equal(t, []string{"Mary"}, iterate(10))
+		assert.Empty(t, iterate(10))
+
+		checkpoint, err := store.Load(ctx, "users")
+		assert.Nil(t, err)
+		assert.NotEmpty(t, checkpoint)
+
+		var (
+			user User
+			it   = repo.Iterate(ctx, rel.From("users"), rel.SortBy(rel.SortDesc("age")), rel.Resume(checkpoint))
+		)
+
+		defer it.Close()
+		assert.Equal(t, rel.ErrInvalidCheckpoint, it.Next(&user))
+	})
+
+	t.Run("IterateParallel", func(t *testing.T) {
+		var (
+			mutex  sync.Mutex
+			result []string
+		)
+
+		err := repo.IterateParallel(ctx, rel.From("users"), 3, func(ctx context.Context, user *User) error {
+			mutex.Lock()
+			result = append(result, user.Name)
+			mutex.Unlock()
+			return nil
+		}, rel.BatchSize(1))
+
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{"John", "Jane", "Doe", "Mary"}, result)
+	})
+
+	t.Run("IterateParallel error", func(t *testing.T) {
+		var (
+			errStop = errors.New("stop")
+		)
+
+		err := repo.IterateParallel(ctx, rel.From("users"), 2, func(ctx context.Context, user *User) error {
+			if user.Name == "Doe" {
+				return errStop
+			}
+
+			return nil
+		}, rel.BatchSize(1))
+
+		assert.Equal(t, errStop, err)
+	})
+}
+
+func names(users []User) []string {
+	result := make([]string, len(users))
+	for i := range users {
+		result[i] = users[i].Name
+	}
+
+	return result
+}
@@ -0,0 +1,120 @@
+package adaptertest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-rel/rel"
+)
+
+// User fixture.
+type User struct {
+	ID        int
+	Name      string
+	Gender    string
+	Age       int
+	Note      *string
+	Addresses []Address `autosave:"true"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Address fixture.
+type Address struct {
+	ID        int
+	UserID    *int
+	User      *User `autosave:"true"`
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Post fixture, it's soft deleted using deleted_at and versioned using lock_version.
+type Post struct {
+	ID          int
+	Title       string
+	Views       int
+	LockVersion int
+	DeletedAt   *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Tag fixture, it's soft deleted using deleted flag and has unique slug.
+type Tag struct {
+	ID      int
+	Slug    string
+	Name    string
+	Deleted bool
+}
+
+func migrate(ctx context.Context, t *testing.T, adapter rel.Adapter) {
+	rollback(ctx, t, adapter)
+
+	users := rel.Table{Op: rel.SchemaCreate, Name: "users"}
+	users.ID("id")
+	users.String("name")
+	users.String("gender")
+	users.Int("age")
+	users.String("note")
+	users.DateTime("created_at")
+	users.DateTime("updated_at")
+
+	addresses := rel.Table{Op: rel.SchemaCreate, Name: "addresses"}
+	addresses.ID("id")
+	addresses.Int("user_id")
+	addresses.String("name")
+	addresses.DateTime("created_at")
+	addresses.DateTime("updated_at")
+	addresses.ForeignKey("user_id", "users", "id")
+
+	posts := rel.Table{Op: rel.SchemaCreate, Name: "posts"}
+	posts.ID("id")
+	posts.String("title")
+	posts.Int("views")
+	posts.Int("lock_version")
+	posts.DateTime("deleted_at")
+	posts.DateTime("created_at")
+	posts.DateTime("updated_at")
+
+	tags := rel.Table{Op: rel.SchemaCreate, Name: "tags"}
+	tags.ID("id")
+	tags.String("slug", rel.Unique(true))
+	tags.String("name", rel.Required(true))
+	tags.Bool("deleted")
+
+	checkpoints := rel.Table{Op: rel.SchemaCreate, Name: "iterator_checkpoints"}
+	checkpoints.String("name", rel.Primary(true))
+	checkpoints.Text("checkpoint")
+	checkpoints.DateTime("updated_at")
+
+	for _, table := range []rel.Table{users, addresses, posts, tags, checkpoints} {
+		if err := adapter.Apply(ctx, table); err != nil {
+			t.Fatalf("adaptertest: failed to create table %s: %v", table.Name, err)
+		}
+	}
+}
+
+func rollback(ctx context.Context, t *testing.T, adapter rel.Adapter) {
+	for _, name := range []string{"iterator_checkpoints", "tags", "posts", "addresses", "users"} {
+		if err := adapter.Apply(ctx, rel.Table{Op: rel.SchemaDrop, Name: name, Optional: true}); err != nil {
+			t.Fatalf("adaptertest: failed to drop table %s: %v", name, err)
+		}
+	}
+}
+
+// reset deletes all records, so every spec starts with empty tables.
+func reset(t *testing.T, repo rel.Repository) {
+	ctx := context.TODO()
+
+	for _, name := range []string{"iterator_checkpoints", "tags", "posts", "addresses", "users"} {
+		if _, err := repo.DeleteAny(ctx, rel.From(name)); err != nil {
+			t.Fatalf("adaptertest: failed to reset table %s: %v", name, err)
+		}
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,184 @@
+package adaptertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+var errRollback = errors.New("adaptertest: rollback")
+
+func testTransaction(t *testing.T, repo rel.Repository) {
+	ctx := context.TODO()
+
+	t.Run("Commit", func(t *testing.T) {
+		err := repo.Transaction(ctx, func(ctx context.Context) error {
+			repo.MustInsert(ctx, &User{Name: "commit"})
+			assert.Equal(t, 1, repo.MustCount(ctx, "users", where.Eq("name", "commit")))
+			return nil
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, repo.MustCount(ctx, "users", where.Eq("name", "commit")))
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		err := repo.Transaction(ctx, func(ctx context.Context) error {
+			repo.MustInsert(ctx, &User{Name: "rollback"})
+			assert.Equal(t, 1, repo.MustCount(ctx, "users", where.Eq("name", "rollback")))
+			return errRollback
+		})
+
+		assert.Equal(t, errRollback, err)
+		assert.Equal(t, 0, repo.MustCount(ctx, "users", where.Eq("name", "rollback")))
+	})
+
+	t.Run("Rollback on panic", func(t *testing.T) {
+		err := repo.Transaction(ctx, func(ctx context.Context) error {
+			repo.MustInsert(ctx, &User{Name: "panic"})
+			repo.MustInsert(ctx, &Tag{Slug: "go"}, rel.Set("name", nil))
+			return nil
+		})
+
+		assert.True(t, errors.Is(err, rel.ErrNotNullConstraint), "expected not null constraint error, got: %v", err)
+		assert.Equal(t, 0, repo.MustCount(ctx, "users", where.Eq("name", "panic")))
+	})
+
+	t.Run("Rollback update and delete", func(t *testing.T) {
+		user := User{Name: "existing", Age: 10}
+		repo.MustInsert(ctx, &user)
+
+		err := repo.Transaction(ctx, func(ctx context.Context) error {
+			repo.MustUpdate(ctx, &user, rel.Set("age", 20))
+			repo.MustDeleteAny(ctx, rel.From("users").Where(where.Eq("name", "commit")))
+			return errRollback
+		})
+
+		assert.Equal(t, errRollback, err)
+		assert.Equal(t, 1, repo.MustCount(ctx, "users", where.Eq("name", "existing").AndEq("age", 10)))
+		assert.Equal(t, 1, repo.MustCount(ctx, "users", where.Eq("name", "commit")))
+	})
+
+	t.Run("Isolation", func(t *testing.T) {
+		err := repo.Transaction(ctx, func(trxCtx context.Context) error {
+			repo.MustInsert(trxCtx, &User{Name: "isolated"})
+			assert.Equal(t, 1, repo.MustCount(trxCtx, "users", where.Eq("name", "isolated")))
+			assert.Equal(t, 0, repo.MustCount(ctx, "users", where.Eq("name", "isolated")))
+			return errRollback
+		})
+
+		assert.Equal(t, errRollback, err)
+	})
+
+	t.Run("Row lock", func(t *testing.T) {
+		var (
+			users = []User{{Name: "lock 1"}, {Name: "lock 2"}}
+			query = rel.From("users").Where(where.Like("name", "lock %")).SortAsc("id")
+		)
+
+		repo.MustInsertAll(ctx, &users)
+
+		err := repo.Transaction(ctx, func(trxCtx context.Context) error {
+			var locked User
+			repo.MustFind(trxCtx, &locked, query, rel.RowLockForUpdate().SkipLocked())
+			assert.Equal(t, users[0].ID, locked.ID)
+
+			// other transaction is started using context outside of the current transaction.
+			return repo.Transaction(ctx, func(otherCtx context.Context) error {
+				var other User
+				repo.MustFind(otherCtx, &other, query, rel.RowLockForUpdate().SkipLocked())
+				assert.Equal(t, users[1].ID, other.ID)
+
+				err := repo.Find(otherCtx, &other, where.Eq("id", users[0].ID), rel.RowLockForUpdate().NoWait())
+				assert.True(t, errors.Is(err, rel.ErrLockNotAvailable), "expected lock not available error, got: %v", err)
+
+				return errRollback
+			})
+		})
+
+		assert.Equal(t, errRollback, err)
+	})
+
+	t.Run("Write conflict", func(t *testing.T) {
+		var (
+			user      = User{Name: "conflict"}
+			committed = 0
+		)
+
+		repo.MustInsert(ctx, &user)
+		query := rel.From("users").Where(where.Eq("id", user.ID))
+
+		err := repo.Transaction(ctx, func(trxCtx context.Context) error {
+			assert.Equal(t, 1, repo.MustCount(trxCtx, "users", where.Eq("id", user.ID)))
+
+			// other transaction is started using context outside of the current transaction.
+			assert.Nil(t, repo.Transaction(ctx, func(otherCtx context.Context) error {
+				repo.MustUpdateAny(otherCtx, query, rel.Inc("age"))
+				return nil
+			}))
+
+			committed++
+			repo.MustUpdateAny(trxCtx, query, rel.Inc("age"))
+			return nil
+		})
+
+		if err != nil {
+			committed--
+			assert.True(t, errors.Is(err, rel.ErrSerializationFailure), "expected serialization failure, got: %v", err)
+		}
+
+		// the update of either transaction must not be lost.
+		repo.MustFind(ctx, &user, query)
+		assert.Equal(t, committed+1, user.Age)
+	})
+
+	t.Run("Unique conflict", func(t *testing.T) {
+		err := repo.Transaction(ctx, func(trxCtx context.Context) error {
+			assert.Equal(t, 0, repo.MustCount(trxCtx, "tags", where.Eq("slug", "conflict")))
+
+			// other transaction is started using context outside of the current transaction.
+			assert.Nil(t, repo.Transaction(ctx, func(otherCtx context.Context) error {
+				repo.MustInsert(otherCtx, &Tag{Slug: "conflict", Name: "other"})
+				return nil
+			}))
+
+			repo.MustInsert(trxCtx, &Tag{Slug: "conflict", Name: "current"})
+			return nil
+		})
+
+		assert.True(t, errors.Is(err, rel.ErrUniqueConstraint), "expected unique constraint error, got: %v", err)
+		assert.Equal(t, 1, repo.MustCount(ctx, "tags", where.Eq("slug", "conflict")))
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		err := repo.Transaction(ctx, func(ctx context.Context) error {
+			if _, ok := repo.Adapter(ctx).(rel.Savepointer); !ok {
+				t.Skip("adaptertest: adapter does not support savepoint")
+			}
+
+			repo.MustInsert(ctx, &User{Name: "outer"})
+
+			err := repo.Transaction(ctx, func(ctx context.Context) error {
+				repo.MustInsert(ctx, &User{Name: "inner"})
+				return errRollback
+			})
+
+			assert.Equal(t, errRollback, err)
+			assert.Equal(t, 0, repo.MustCount(ctx, "users", where.Eq("name", "inner")))
+
+			return repo.Transaction(ctx, func(ctx context.Context) error {
+				repo.MustInsert(ctx, &User{Name: "inner committed"})
+				return nil
+			})
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, repo.MustCount(ctx, "users", where.Eq("name", "outer")))
+		assert.Equal(t, 0, repo.MustCount(ctx, "users", where.Eq("name", "inner")))
+		assert.Equal(t, 1, repo.MustCount(ctx, "users", where.Eq("name", "inner committed")))
+	})
+}
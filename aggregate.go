@@ -0,0 +1,168 @@
+package rel
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Aggregation defines an aggregate function over a field, used by Aggregates and AggregateGroups.
+// Supported mode: count, sum, avg, max, min.
+type Aggregation struct {
+	Mode     string
+	Field    string
+	Distinct bool
+	Alias    string
+}
+
+// As set the alias of aggregation result.
+func (a Aggregation) As(alias string) Aggregation {
+	a.Alias = alias
+	return a
+}
+
+// Name returns the key of aggregation result.
+// Defaults to mode and field joined by underscore, eg: sum_amount.
+func (a Aggregation) Name() string {
+	if a.Alias != "" {
+		return a.Alias
+	}
+
+	var (
+		name = a.Mode
+	)
+
+	if a.Distinct {
+		name += "_distinct"
+	}
+
+	if a.Field != "*" {
+		name += "_" + strings.ReplaceAll(a.Field, ".", "_")
+	}
+
+	return name
+}
+
+// expr returns select expression of aggregation.
+func (a Aggregation) expr() SelectExpr {
+	var (
+		expr = Expr(a.Mode)
+	)
+
+	if a.Field != "*" {
+		expr.Arguments = []interface{}{a.Field}
+	}
+
+	expr.Distinct = a.Distinct
+	return expr.As(a.Name())
+}
+
+// String representation.
+func (a Aggregation) String() string {
+	var (
+		str string
+	)
+
+	switch {
+	case a.Distinct && a.Mode == "count":
+		str = "rel.CountDistinct(\"" + a.Field + "\")"
+	case a.Mode == "count" || a.Mode == "sum" || a.Mode == "avg" || a.Mode == "max" || a.Mode == "min":
+		str = "rel." + strings.ToUpper(a.Mode[:1]) + a.Mode[1:] + "(\"" + a.Field + "\")"
+	default:
+		str = "rel.Aggregation{Mode: \"" + a.Mode + "\", Field: \"" + a.Field + "\", Distinct: " + strconv.FormatBool(a.Distinct) + "}"
+	}
+
+	if a.Alias != "" {
+		str += ".As(\"" + a.Alias + "\")"
+	}
+
+	return str
+}
+
+// Count aggregation, use * to count all records.
+func Count(field string) Aggregation {
+	return Aggregation{Mode: "count", Field: field}
+}
+
+// CountDistinct aggregation.
+func CountDistinct(field string) Aggregation {
+	return Aggregation{Mode: "count", Field: field, Distinct: true}
+}
+
+// Sum aggregation.
+func Sum(field string) Aggregation {
+	return Aggregation{Mode: "sum", Field: field}
+}
+
+// Avg aggregation.
+func Avg(field string) Aggregation {
+	return Aggregation{Mode: "avg", Field: field}
+}
+
+// Max aggregation.
+func Max(field string) Aggregation {
+	return Aggregation{Mode: "max", Field: field}
+}
+
+// Min aggregation.
+func Min(field string) Aggregation {
+	return Aggregation{Mode: "min", Field: field}
+}
+
+// AggregateResult holds values of aggregations keyed by its name.
+// Result of grouped aggregation also contains values of group fields keyed by the field name.
+// Values are stored as returned by database, decimal value is stored as string to avoid losing precision.
+type AggregateResult map[string]interface{}
+
+// Int returns value as int, float value is truncated.
+// It returns zero if value is null or cannot be converted.
+func (ar AggregateResult) Int(name string) int {
+	var (
+		result int
+	)
+
+	if f, ok := ar[name].(float64); ok {
+		return int(f)
+	}
+
+	if err := convertAssign(&result, ar[name]); err != nil {
+		return int(ar.Float(name))
+	}
+
+	return result
+}
+
+// Float returns value as float64.
+// It returns zero if value is null or cannot be converted.
+func (ar AggregateResult) Float(name string) float64 {
+	var (
+		result float64
+	)
+
+	convertAssign(&result, ar[name])
+	return result
+}
+
+// Time returns value as time.Time, useful for min and max of time field.
+// It returns zero time if value is null or cannot be converted.
+func (ar AggregateResult) Time(name string) time.Time {
+	var (
+		result time.Time
+	)
+
+	convertAssign(&result, ar[name])
+	return result
+}
+
+func aggregationExprs(aggregations []Aggregation) []SelectExpr {
+	if len(aggregations) == 0 {
+		panic("rel: at least one aggregation is required")
+	}
+
+	exprs := make([]SelectExpr, len(aggregations))
+	for i := range aggregations {
+		exprs[i] = aggregations[i].expr()
+	}
+
+	return exprs
+}
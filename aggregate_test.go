@@ -0,0 +1,63 @@
+package rel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregation(t *testing.T) {
+	tests := []struct {
+		aggregation Aggregation
+		name        string
+		expr        SelectExpr
+		str         string
+	}{
+		{Count("*"), "count", Expr("count").As("count"), "rel.Count(\"*\")"},
+		{CountDistinct("user_id"), "count_distinct_user_id", ExprDistinct("count", "user_id").As("count_distinct_user_id"), "rel.CountDistinct(\"user_id\")"},
+		{Sum("orders.amount"), "sum_orders_amount", Expr("sum", "orders.amount").As("sum_orders_amount"), "rel.Sum(\"orders.amount\")"},
+		{Avg("amount").As("average"), "average", Expr("avg", "amount").As("average"), "rel.Avg(\"amount\").As(\"average\")"},
+		{Max("created_at"), "max_created_at", Expr("max", "created_at").As("max_created_at"), "rel.Max(\"created_at\")"},
+		{Min("created_at"), "min_created_at", Expr("min", "created_at").As("min_created_at"), "rel.Min(\"created_at\")"},
+		{Aggregation{Mode: "stddev", Field: "amount"}, "stddev_amount", Expr("stddev", "amount").As("stddev_amount"), "rel.Aggregation{Mode: \"stddev\", Field: \"amount\", Distinct: false}"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.str, func(t *testing.T) {
+			assert.Equal(t, test.name, test.aggregation.Name())
+			assert.Equal(t, test.expr, test.aggregation.expr())
+			assert.Equal(t, test.str, test.aggregation.String())
+		})
+	}
+}
+
+func TestAggregationExprs_empty(t *testing.T) {
+	assert.PanicsWithValue(t, "rel: at least one aggregation is required", func() {
+		aggregationExprs(nil)
+	})
+}
+
+func TestAggregateResult(t *testing.T) {
+	var (
+		now    = time.Now().Truncate(time.Second).Local()
+		result = AggregateResult{
+			"count":   int64(3),
+			"avg":     float64(27.5),
+			"decimal": "1234.56",
+			"max":     now,
+			"null":    nil,
+		}
+	)
+
+	assert.Equal(t, 3, result.Int("count"))
+	assert.Equal(t, float64(3), result.Float("count"))
+	assert.Equal(t, 27, result.Int("avg"))
+	assert.Equal(t, 27.5, result.Float("avg"))
+	assert.Equal(t, 1234, result.Int("decimal"))
+	assert.Equal(t, 1234.56, result.Float("decimal"))
+	assert.Equal(t, now, result.Time("max"))
+	assert.Equal(t, 0, result.Int("null"))
+	assert.Equal(t, float64(0), result.Float("unknown"))
+	assert.True(t, result.Time("count").IsZero())
+}
@@ -0,0 +1,306 @@
+// Package cache provides rel.Adapter that caches query result.
+//
+// Query and aggregate results are cached using canonical encoding of the query as the key,
+// and invalidated when records in any of the queried tables are inserted, updated or deleted through the adapter.
+// Result that is read while any of its tables is invalidated by concurrent write is not cached.
+// Queries inside transaction, locking queries, raw sql queries and queries with rel.NoCache are never cached.
+package cache
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-rel/rel"
+)
+
+// DefaultSize of in-memory store when no store is specified.
+var DefaultSize = 1000
+
+// Adapter that caches query result.
+type Adapter struct {
+	adapter      rel.Adapter
+	store        Store
+	instrumenter rel.Instrumenter
+	trx          *transaction
+}
+
+var (
+	_ rel.Adapter             = (*Adapter)(nil)
+	_ rel.TransactionBeginner = (*Adapter)(nil)
+)
+
+// transaction records tables written inside it, so it can be invalidated after commit.
+type transaction struct {
+	mu     sync.Mutex
+	parent *transaction
+	tables map[string]struct{}
+	all    bool
+}
+
+func (t *transaction) invalidate(tables ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if tables == nil {
+		t.all = true
+	}
+
+	for _, table := range tables {
+		t.tables[table] = struct{}{}
+	}
+}
+
+// Close database connection.
+func (a *Adapter) Close() error {
+	return a.adapter.Close()
+}
+
+// Instrumentation set instrumenter for this adapter and the wrapped adapter.
+func (a *Adapter) Instrumentation(instrumenter rel.Instrumenter) {
+	a.instrumenter = instrumenter
+	a.adapter.Instrumentation(instrumenter)
+}
+
+// Ping database.
+func (a *Adapter) Ping(ctx context.Context) error {
+	return a.adapter.Ping(ctx)
+}
+
+// Aggregate record using given query, result is cached.
+func (a *Adapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string) (int, error) {
+	if a.trx != nil || !cacheable(query) {
+		return a.adapter.Aggregate(ctx, query, mode, field)
+	}
+
+	k := "aggregate:" + mode + "(" + field + "):" + key(query)
+	if result, ok := a.get(ctx, k); ok && len(result.Rows) == 1 && len(result.Rows[0]) == 1 {
+		var count int
+		return count, scan(&count, result.Rows[0][0])
+	}
+
+	var (
+		tbls       = tables(query)
+		generation = a.store.Generation(ctx, tbls)
+	)
+
+	count, err := a.adapter.Aggregate(ctx, query, mode, field)
+	if err != nil {
+		return 0, err
+	}
+
+	a.store.Set(ctx, k, Result{Rows: [][]interface{}{{count}}}, tbls, generation)
+	return count, nil
+}
+
+// Query performs query operation, result is cached.
+func (a *Adapter) Query(ctx context.Context, query rel.Query) (rel.Cursor, error) {
+	if a.trx != nil || !cacheable(query) {
+		return a.adapter.Query(ctx, query)
+	}
+
+	k := "query:" + key(query)
+	if result, ok := a.get(ctx, k); ok {
+		return newCursor(result), nil
+	}
+
+	var (
+		tbls       = tables(query)
+		generation = a.store.Generation(ctx, tbls)
+	)
+
+	cur, err := a.adapter.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := read(cur)
+	if err != nil {
+		return nil, err
+	}
+
+	a.store.Set(ctx, k, result, tbls, generation)
+	return newCursor(result), nil
+}
+
+// Insert inserts a record to database and invalidates the table.
+func (a *Adapter) Insert(ctx context.Context, query rel.Query, primaryField string, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (interface{}, error) {
+	defer a.invalidate(ctx, query.Table)
+	return a.adapter.Insert(ctx, query, primaryField, mutates, onConflict)
+}
+
+// InsertAll inserts multiple records to database and invalidates the table.
+func (a *Adapter) InsertAll(ctx context.Context, query rel.Query, primaryField string, fields []string, bulkMutates []map[string]rel.Mutate, onConflict rel.OnConflict) ([]interface{}, error) {
+	defer a.invalidate(ctx, query.Table)
+	return a.adapter.InsertAll(ctx, query, primaryField, fields, bulkMutates, onConflict)
+}
+
+// Update updates records and invalidates the table.
+func (a *Adapter) Update(ctx context.Context, query rel.Query, primaryField string, mutates map[string]rel.Mutate) (int, error) {
+	defer a.invalidate(ctx, query.Table)
+	return a.adapter.Update(ctx, query, primaryField, mutates)
+}
+
+// Delete deletes records and invalidates the table.
+func (a *Adapter) Delete(ctx context.Context, query rel.Query) (int, error) {
+	defer a.invalidate(ctx, query.Table)
+	return a.adapter.Delete(ctx, query)
+}
+
+// Exec raw statement and invalidates all cached results.
+func (a *Adapter) Exec(ctx context.Context, stmt string, args []interface{}) (int64, int64, error) {
+	defer a.invalidate(ctx)
+	return a.adapter.Exec(ctx, stmt, args)
+}
+
+// Begin a transaction.
+// Queries inside transaction are not cached, and tables written inside it are invalidated after it's committed.
+func (a *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	trx, err := a.adapter.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.wrap(trx), nil
+}
+
+// BeginWith begins a transaction with options.
+func (a *Adapter) BeginWith(ctx context.Context, options rel.TransactionOptions) (rel.Adapter, error) {
+	tb, ok := a.adapter.(rel.TransactionBeginner)
+	if !ok {
+		return nil, rel.ErrTransactionOptionNotSupported
+	}
+
+	trx, err := tb.BeginWith(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.wrap(trx), nil
+}
+
+// Commit current transaction, and invalidates tables written inside it.
+func (a *Adapter) Commit(ctx context.Context) error {
+	if err := a.adapter.Commit(ctx); err != nil || a.trx == nil {
+		return err
+	}
+
+	a.trx.mu.Lock()
+	defer a.trx.mu.Unlock()
+
+	var tables []string
+	for table := range a.trx.tables {
+		tables = append(tables, table)
+	}
+
+	if parent := a.trx.parent; parent != nil {
+		if a.trx.all {
+			parent.invalidate()
+		} else {
+			parent.invalidate(tables...)
+		}
+	} else if a.trx.all {
+		a.store.Clear(ctx)
+	} else if len(tables) > 0 {
+		a.store.Invalidate(ctx, tables...)
+	}
+
+	return nil
+}
+
+// Rollback current transaction.
+func (a *Adapter) Rollback(ctx context.Context) error {
+	return a.adapter.Rollback(ctx)
+}
+
+// Apply migration and invalidates the migrated table.
+func (a *Adapter) Apply(ctx context.Context, migration rel.Migration) error {
+	switch v := migration.(type) {
+	case rel.Table:
+		defer a.invalidate(ctx, v.Name)
+	case rel.Index:
+		defer a.invalidate(ctx, v.Table)
+	default:
+		defer a.invalidate(ctx)
+	}
+
+	return a.adapter.Apply(ctx, migration)
+}
+
+// Store used by this adapter.
+func (a *Adapter) Store() Store {
+	return a.store
+}
+
+func (a *Adapter) get(ctx context.Context, key string) (Result, bool) {
+	finish := a.instrumenter.Observe(ctx, "adapter-cache", key)
+	result, ok := a.store.Get(ctx, key)
+	finish(nil)
+
+	return result, ok
+}
+
+// invalidate tables, or all cached result when no table is given.
+// invalidation inside transaction is deferred until it's committed.
+func (a *Adapter) invalidate(ctx context.Context, tables ...string) {
+	for i := range tables {
+		tables[i] = tableName(tables[i])
+	}
+
+	switch {
+	case a.trx != nil:
+		a.trx.invalidate(tables...)
+	case tables == nil:
+		a.store.Clear(ctx)
+	default:
+		a.store.Invalidate(ctx, tables...)
+	}
+}
+
+// wrap transaction adapter.
+func (a *Adapter) wrap(trx rel.Adapter) rel.Adapter {
+	adapter := &Adapter{
+		adapter:      trx,
+		store:        a.store,
+		instrumenter: a.instrumenter,
+		trx:          &transaction{parent: a.trx, tables: make(map[string]struct{})},
+	}
+
+	if sp, ok := trx.(rel.Savepointer); ok {
+		return &savepointAdapter{Adapter: adapter, savepointer: sp}
+	}
+
+	return adapter
+}
+
+// savepointAdapter is used when the wrapped transaction supports savepoint.
+type savepointAdapter struct {
+	*Adapter
+	savepointer rel.Savepointer
+}
+
+var _ rel.Savepointer = (*savepointAdapter)(nil)
+
+func (sa *savepointAdapter) Savepoint(ctx context.Context, name string) error {
+	return sa.savepointer.Savepoint(ctx, name)
+}
+
+func (sa *savepointAdapter) RollbackTo(ctx context.Context, name string) error {
+	return sa.savepointer.RollbackTo(ctx, name)
+}
+
+func (sa *savepointAdapter) Release(ctx context.Context, name string) error {
+	return sa.savepointer.Release(ctx, name)
+}
+
+// New adapter that caches query result of the given adapter.
+// In-memory LRU store with DefaultSize is used when store is nil.
+func New(adapter rel.Adapter, store Store) *Adapter {
+	if store == nil {
+		store = NewLRU(DefaultSize)
+	}
+
+	return &Adapter{
+		adapter: adapter,
+		store:   store,
+	}
+}
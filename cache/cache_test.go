@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/adaptertest"
+	"github.com/go-rel/rel/memadapter"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+var errRollback = errors.New("cache: rollback")
+
+type User struct {
+	ID   int
+	Name string
+}
+
+type Address struct {
+	ID     int
+	UserID int
+	Name   string
+}
+
+func setup(t *testing.T) (*Adapter, rel.Repository, map[string]int) {
+	var (
+		ctx     = context.TODO()
+		adapter = New(memadapter.New(), nil)
+		repo    = rel.New(adapter)
+		ops     = make(map[string]int)
+	)
+
+	repo.MustInsert(ctx, &User{Name: "John"})
+	repo.MustInsert(ctx, &Address{UserID: 1, Name: "home"})
+
+	repo.Instrumentation(func(ctx context.Context, op string, message string) func(err error) {
+		ops[op]++
+		return func(err error) {}
+	})
+
+	return adapter, repo, ops
+}
+
+func TestAdapter(t *testing.T) {
+	adaptertest.Run(t, New(memadapter.New(), nil))
+}
+
+func TestAdapter_Query(t *testing.T) {
+	var (
+		ctx          = context.TODO()
+		_, repo, ops = setup(t)
+		user         User
+	)
+
+	assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", 1)))
+	assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", 1)))
+	assert.Equal(t, "John", user.Name)
+	assert.Equal(t, 1, ops["adapter-query"])
+	assert.Equal(t, 2, ops["adapter-cache"])
+
+	assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", 1), rel.NoCache(true)))
+	assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", 1), rel.ForUpdate()))
+	assert.Equal(t, 3, ops["adapter-query"])
+
+	assert.Equal(t, 1, repo.MustCount(ctx, "users"))
+	assert.Equal(t, 1, repo.MustCount(ctx, "users"))
+	assert.Equal(t, 1, ops["adapter-aggregate"])
+}
+
+func TestAdapter_invalidate(t *testing.T) {
+	var (
+		ctx              = context.TODO()
+		adapter, repo, _ = setup(t)
+		user             User
+		addresses        []Address
+	)
+
+	assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", 1)))
+	assert.Nil(t, repo.FindAll(ctx, &addresses, rel.From("addresses").JoinOn("users", "addresses.user_id", "users.id").Where(where.Eq("users.name", "John"))))
+	assert.Equal(t, 1, repo.MustCount(ctx, "users"))
+	assert.Equal(t, 3, adapter.Store().(*LRU).Len())
+
+	repo.MustUpdate(ctx, &user, rel.Set("name", "Doe"))
+	assert.Equal(t, 0, adapter.Store().(*LRU).Len())
+
+	assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", 1)))
+	assert.Equal(t, "Doe", user.Name)
+	assert.Nil(t, repo.FindAll(ctx, &addresses, rel.From("addresses").JoinOn("users", "addresses.user_id", "users.id").Where(where.Eq("users.name", "John"))))
+	assert.Len(t, addresses, 0)
+
+	repo.MustInsert(ctx, &Address{UserID: 1, Name: "office"})
+	assert.Equal(t, 1, adapter.Store().(*LRU).Len())
+
+	repo.MustDeleteAny(ctx, rel.From("users"))
+	assert.Equal(t, 0, adapter.Store().(*LRU).Len())
+	assert.Equal(t, 0, repo.MustCount(ctx, "users"))
+}
+
+// writeAfterRead performs write after the wrapped adapter read the result, but before the result is cached.
+type writeAfterRead struct {
+	rel.Adapter
+	write func()
+}
+
+func (w *writeAfterRead) Query(ctx context.Context, query rel.Query) (rel.Cursor, error) {
+	cur, err := w.Adapter.Query(ctx, query)
+	if write := w.write; write != nil {
+		w.write = nil
+		write()
+	}
+
+	return cur, err
+}
+
+func TestAdapter_concurrentWrite(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		wrapped = &writeAfterRead{Adapter: memadapter.New()}
+		adapter = New(wrapped, nil)
+		repo    = rel.New(adapter)
+		user    = User{Name: "John"}
+	)
+
+	repo.MustInsert(ctx, &user)
+
+	wrapped.write = func() {
+		repo.MustUpdate(ctx, &User{ID: user.ID, Name: "Doe"})
+	}
+
+	assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", user.ID)))
+	assert.Equal(t, "John", user.Name)
+	assert.Equal(t, 0, adapter.Store().(*LRU).Len())
+
+	assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", user.ID)))
+	assert.Equal(t, "Doe", user.Name)
+}
+
+func TestAdapter_transaction(t *testing.T) {
+	var (
+		ctx              = context.TODO()
+		adapter, repo, _ = setup(t)
+		user             User
+	)
+
+	assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", 1)))
+
+	assert.Nil(t, repo.Transaction(ctx, func(ctx context.Context) error {
+		repo.MustUpdate(ctx, &user, rel.Set("name", "Doe"))
+		assert.Equal(t, 1, adapter.Store().(*LRU).Len())
+
+		assert.Equal(t, errRollback, repo.Transaction(ctx, func(ctx context.Context) error {
+			repo.MustInsert(ctx, &Address{UserID: 1, Name: "office"})
+			return errRollback
+		}))
+
+		var found User
+		assert.Nil(t, repo.Find(ctx, &found, where.Eq("id", 1)))
+		assert.Equal(t, "Doe", found.Name)
+		return nil
+	}))
+
+	assert.Equal(t, 0, adapter.Store().(*LRU).Len())
+	assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", 1)))
+	assert.Equal(t, "Doe", user.Name)
+
+	assert.Equal(t, errRollback, repo.Transaction(ctx, func(ctx context.Context) error {
+		repo.MustDelete(ctx, &user)
+		return errRollback
+	}))
+
+	assert.Equal(t, 1, adapter.Store().(*LRU).Len())
+}
+
+func TestAdapter_exec(t *testing.T) {
+	var (
+		ctx              = context.TODO()
+		adapter, repo, _ = setup(t)
+	)
+
+	assert.Equal(t, 1, repo.MustCount(ctx, "users"))
+
+	_, _, err := repo.Adapter(ctx).Exec(ctx, "DELETE FROM users", nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, adapter.Store().(*LRU).Len())
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+
+	"github.com/go-rel/rel"
+)
+
+// cursor of cached result.
+type cursor struct {
+	fields []string
+	rows   [][]interface{}
+	index  int
+}
+
+var _ rel.Cursor = (*cursor)(nil)
+
+// read all rows from cursor, cursor is closed after all rows are read.
+func read(cur rel.Cursor) (Result, error) {
+	defer cur.Close()
+
+	fields, err := cur.Fields()
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Fields: fields}
+	for cur.Next() {
+		var (
+			row  = make([]interface{}, len(fields))
+			dest = make([]interface{}, len(fields))
+		)
+
+		for i := range dest {
+			dest[i] = &row[i]
+		}
+
+		if err := cur.Scan(dest...); err != nil {
+			return Result{}, err
+		}
+
+		result.Rows = append(result.Rows, row)
+	}
+
+	return result, nil
+}
+
+func newCursor(result Result) *cursor {
+	return &cursor{fields: result.Fields, rows: result.Rows, index: -1}
+}
+
+func (c *cursor) Close() error {
+	c.rows = nil
+	return nil
+}
+
+func (c *cursor) Fields() ([]string, error) {
+	return c.fields, nil
+}
+
+func (c *cursor) Next() bool {
+	c.index++
+	return c.index < len(c.rows)
+}
+
+func (c *cursor) Scan(dest ...interface{}) error {
+	if c.index < 0 || c.index >= len(c.rows) {
+		return errors.New("cache: scan called without calling next")
+	}
+
+	row := c.rows[c.index]
+	if len(dest) != len(row) {
+		return errors.New("cache: invalid number of scan destination")
+	}
+
+	for i := range dest {
+		if err := scan(dest[i], row[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *cursor) NopScanner() interface{} {
+	return &sql.RawBytes{}
+}
+
+func scan(dest interface{}, value interface{}) error {
+	if s, ok := dest.(sql.Scanner); ok {
+		return s.Scan(value)
+	}
+
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Ptr {
+		if value == nil {
+			rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+			return nil
+		}
+
+		ptr := reflect.New(rv.Elem().Type().Elem())
+		if err := scan(ptr.Interface(), value); err != nil {
+			return err
+		}
+
+		rv.Elem().Set(ptr)
+		return nil
+	}
+
+	return rel.Nullable(dest).(sql.Scanner).Scan(value)
+}
@@ -0,0 +1,312 @@
+package cache
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-rel/rel"
+)
+
+// cacheable returns true when query result can be cached.
+// Raw sql, locking query, join fragment and raw filter may refer to unknown tables or requires fresh data.
+func cacheable(query rel.Query) bool {
+	if query.NoCacheQuery || query.LockQuery != "" || query.SQLQuery.Statement != "" || query.Table == "" {
+		return false
+	}
+
+	for i := range query.JoinQuery {
+		if query.JoinQuery[i].Table == "" {
+			return false
+		}
+	}
+
+	return !rawFilter(query.WhereQuery) && !rawFilter(query.GroupQuery.Filter)
+}
+
+// rawFilter reports whether filter contains fragment or raw field.
+func rawFilter(filter rel.FilterQuery) bool {
+	if filter.Type == rel.FilterFragmentOp || strings.HasPrefix(filter.Field, "^") {
+		return true
+	}
+
+	for i := range filter.Inner {
+		if rawFilter(filter.Inner[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// key returns canonical encoding of query.
+func key(query rel.Query) string {
+	var e encoder
+	e.query(query)
+	return e.String()
+}
+
+// tables returns name of tables that are used by query, including tables of sub queries.
+func tables(query rel.Query) []string {
+	var (
+		result []string
+		seen   = make(map[string]struct{})
+	)
+
+	var add func(q rel.Query)
+	var addFilter func(f rel.FilterQuery)
+	var addValue func(v interface{})
+
+	addTable := func(table string) {
+		if table = tableName(table); table == "" {
+			return
+		}
+
+		if _, ok := seen[table]; !ok {
+			seen[table] = struct{}{}
+			result = append(result, table)
+		}
+	}
+
+	addValue = func(v interface{}) {
+		switch v := v.(type) {
+		case rel.Query:
+			add(v)
+		case rel.SubQuery:
+			add(v.Query)
+		case []interface{}:
+			for i := range v {
+				addValue(v[i])
+			}
+		}
+	}
+
+	addFilter = func(f rel.FilterQuery) {
+		addValue(f.Value)
+		for i := range f.Inner {
+			addFilter(f.Inner[i])
+		}
+	}
+
+	add = func(q rel.Query) {
+		for i := range q.WithQuery {
+			add(q.WithQuery[i].Query)
+			add(q.WithQuery[i].RecursiveQuery)
+		}
+
+		if q.FromQuery != nil {
+			add(q.FromQuery.Query)
+		} else {
+			addTable(q.Table)
+		}
+
+		for i := range q.SelectQuery.SubQueries {
+			add(q.SelectQuery.SubQueries[i].Query)
+		}
+
+		for i := range q.JoinQuery {
+			addTable(q.JoinQuery[i].Table)
+			addFilter(q.JoinQuery[i].Filter)
+			addValue(q.JoinQuery[i].Arguments)
+		}
+
+		addFilter(q.WhereQuery)
+		addFilter(q.GroupQuery.Filter)
+
+		for i := range q.CombineQuery {
+			add(q.CombineQuery[i].Query)
+		}
+	}
+
+	add(query)
+	return result
+}
+
+type encoder struct {
+	strings.Builder
+}
+
+func (e *encoder) query(q rel.Query) {
+	e.WriteByte('{')
+	for _, with := range q.WithQuery {
+		e.WriteString("with:")
+		e.WriteString(strconv.Quote(with.Name))
+		e.query(with.Query)
+		if with.Recursive {
+			e.WriteString("recursive:")
+			e.query(with.RecursiveQuery)
+		}
+
+		e.WriteByte(' ')
+	}
+
+	e.WriteString("table:")
+	e.WriteString(strconv.Quote(q.Table))
+	if q.FromQuery != nil {
+		e.query(q.FromQuery.Query)
+	}
+
+	e.WriteString(" select:")
+	if q.SelectQuery.Fields == nil {
+		e.WriteString("*")
+	}
+	e.strings(q.SelectQuery.Fields)
+	for _, expr := range q.SelectQuery.Expressions {
+		e.WriteString(" expr:")
+		e.WriteString(strconv.Quote(expr.Func))
+		e.value(expr.Arguments)
+		if expr.Distinct {
+			e.WriteString(" distinct")
+		}
+		if expr.Window != nil {
+			e.WriteString(" over:")
+			e.strings(expr.Window.Partition)
+			e.sorts(expr.Window.Sort)
+		}
+
+		e.WriteString(" as:")
+		e.WriteString(strconv.Quote(expr.Alias))
+	}
+
+	for _, sub := range q.SelectQuery.SubQueries {
+		e.WriteString(" sub:")
+		e.WriteString(strconv.Quote(sub.Alias))
+		e.query(sub.Query)
+	}
+
+	if q.SelectQuery.OnlyDistinct {
+		e.WriteString(" distinct")
+	}
+
+	for _, join := range q.JoinQuery {
+		e.WriteString(" join:")
+		e.strings([]string{join.Mode, join.Table, join.From, join.To})
+		e.filter(join.Filter)
+		e.value(join.Arguments)
+	}
+
+	e.WriteString(" where:")
+	e.filter(q.WhereQuery)
+
+	e.WriteString(" group:")
+	e.strings(q.GroupQuery.Fields)
+	e.filter(q.GroupQuery.Filter)
+
+	for _, combine := range q.CombineQuery {
+		e.WriteByte(' ')
+		e.WriteString(combine.Op.String())
+		e.WriteByte(':')
+		e.query(combine.Query)
+	}
+
+	e.WriteString(" sort:")
+	e.sorts(q.SortQuery)
+
+	e.WriteString(" offset:")
+	e.WriteString(strconv.Itoa(int(q.OffsetQuery)))
+	e.WriteString(" limit:")
+	e.WriteString(strconv.Itoa(int(q.LimitQuery)))
+
+	if q.UnscopedQuery {
+		e.WriteString(" unscoped")
+	}
+
+	e.WriteByte('}')
+}
+
+func (e *encoder) sorts(sorts []rel.SortQuery) {
+	for _, sort := range sorts {
+		e.WriteString(strconv.Quote(sort.Field))
+		e.WriteString(strconv.Itoa(sort.Sort))
+	}
+}
+
+func (e *encoder) strings(values []string) {
+	e.WriteByte('[')
+	for i := range values {
+		if i > 0 {
+			e.WriteByte(',')
+		}
+
+		e.WriteString(strconv.Quote(values[i]))
+	}
+	e.WriteByte(']')
+}
+
+func (e *encoder) filter(f rel.FilterQuery) {
+	if f.None() {
+		e.WriteString("-")
+		return
+	}
+
+	e.WriteString(f.Type.String())
+	e.WriteByte('(')
+	e.WriteString(strconv.Quote(f.Field))
+	e.WriteByte(',')
+	e.value(f.Value)
+
+	for i := range f.Inner {
+		e.WriteByte(',')
+		e.filter(f.Inner[i])
+	}
+
+	e.WriteByte(')')
+}
+
+func (e *encoder) value(v interface{}) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		if value, err := valuer.Value(); err == nil {
+			v = value
+		}
+	}
+
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			v = nil
+		} else {
+			v = rv.Elem().Interface()
+		}
+	}
+
+	switch v := v.(type) {
+	case nil:
+		e.WriteString("nil")
+	case rel.Query:
+		e.query(v)
+	case rel.SubQuery:
+		e.WriteString(v.Prefix)
+		e.query(v.Query)
+	case []interface{}:
+		e.WriteByte('[')
+		for i := range v {
+			if i > 0 {
+				e.WriteByte(',')
+			}
+
+			e.value(v[i])
+		}
+		e.WriteByte(']')
+	case string:
+		e.WriteString(strconv.Quote(v))
+	case []byte:
+		e.WriteString("bytes:")
+		e.WriteString(strconv.Quote(string(v)))
+	case time.Time:
+		e.WriteString("time:")
+		e.WriteString(v.UTC().Format(time.RFC3339Nano))
+	default:
+		fmt.Fprintf(e, "%T:%v", v, v)
+	}
+}
+
+// tableName strips alias from table.
+func tableName(table string) string {
+	if fields := strings.Fields(table); len(fields) > 0 {
+		return fields[0]
+	}
+
+	return table
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKey(t *testing.T) {
+	var (
+		id  = 1
+		now = time.Now()
+	)
+
+	assert.Equal(t, key(rel.From("users").Where(where.Eq("id", 1))), key(rel.From("users").Where(where.Eq("id", &id))))
+	assert.Equal(t, key(rel.From("users").Where(where.Eq("created_at", now))), key(rel.From("users").Where(where.Eq("created_at", now.UTC()))))
+
+	assert.NotEqual(t, key(rel.From("users").Where(where.Eq("id", 1))), key(rel.From("users").Where(where.Eq("id", "1"))))
+	assert.NotEqual(t, key(rel.From("users").Where(where.Like("name", "a%"))), key(rel.From("users").Where(where.Like("name", "b%"))))
+	assert.NotEqual(t, key(rel.From("users").Limit(1)), key(rel.From("users").Limit(1).Offset(1)))
+	assert.NotEqual(t, key(rel.From("users").SortAsc("id")), key(rel.From("users").SortDesc("id")))
+	assert.NotEqual(t, key(rel.From("users")), key(rel.From("users").Unscoped()))
+	assert.NotEqual(t, key(rel.With("u", rel.From("users")).From("u")), key(rel.With("u", rel.From("admins")).From("u")))
+	assert.NotEqual(t,
+		key(rel.From("users").SelectExpr(rel.RowNumber().Over(rel.PartitionBy("gender")).As("rank"))),
+		key(rel.From("users").SelectExpr(rel.RowNumber().Over(rel.PartitionBy("gender").SortAsc("age")).As("rank"))),
+	)
+	assert.NotEqual(t,
+		key(rel.From("users").SelectExpr(rel.Expr("count", "gender"))),
+		key(rel.From("users").SelectExpr(rel.ExprDistinct("count", "gender"))),
+	)
+	assert.NotEqual(t, key(rel.FromSub(rel.From("users").As("u"))), key(rel.FromSub(rel.From("admins").As("u"))))
+	assert.NotEqual(t, key(rel.From("users").Where(where.Eq("id", rel.Ref("users.id")))), key(rel.From("users").Where(where.Eq("id", "users.id"))))
+}
+
+func TestTables(t *testing.T) {
+	query := rel.With("active_users", rel.From("profiles")).From("users as u").
+		Join("addresses").
+		Where(where.In("id", rel.Select("user_id").From("orders"))).
+		Where(where.Eq("role_id", rel.Any(rel.Select("id").From("roles")))).
+		Where(where.In("tag_id", 1, 2))
+
+	assert.Equal(t, []string{"profiles", "users", "addresses", "orders", "roles"}, tables(query))
+
+	query = rel.FromSub(rel.From("users").As("u")).
+		SelectSub(rel.Select("count(id)").From("orders").Where(where.Eq("user_id", rel.Ref("u.id"))).As("order_count"))
+
+	assert.Equal(t, []string{"users", "orders"}, tables(query))
+}
+
+func TestCacheable(t *testing.T) {
+	assert.True(t, cacheable(rel.From("users").Where(where.Eq("id", 1)).SortAsc("id")))
+	assert.False(t, cacheable(rel.From("users").NoCache()))
+	assert.False(t, cacheable(rel.From("users").Lock("FOR UPDATE")))
+	assert.False(t, cacheable(rel.Build("users", rel.SQL("SELECT 1"))))
+	assert.False(t, cacheable(rel.From("users").Joinf("JOIN addresses ON true")))
+	assert.False(t, cacheable(rel.From("users").Where(where.Eq("id", 1).And(where.Fragment("age > ?", 20)))))
+	assert.False(t, cacheable(rel.From("users").Where(where.Not(where.Eq("^lower(name)", "john")))))
+	assert.False(t, cacheable(rel.From("users").Group("age").Having(where.Fragment("count(*) > ?", 1))))
+	assert.False(t, cacheable(rel.From("users").Group("age").Having(where.Gt("^count(*)", 1))))
+}
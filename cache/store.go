@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// Result of query stored in cache.
+type Result struct {
+	Fields []string
+	Rows   [][]interface{}
+}
+
+// Store is a storage for cached query result.
+// Result stored using Set must be invalidated when any of its tables is invalidated.
+//
+// Generation returns a value that changes every time any of the tables is invalidated or the store is cleared,
+// it's recorded before the result is read from database. Set must drop the result when the generation of its tables
+// is changed since then, so result that is read before a concurrent write is never cached after the invalidation.
+type Store interface {
+	Get(ctx context.Context, key string) (Result, bool)
+	Generation(ctx context.Context, tables []string) uint64
+	Set(ctx context.Context, key string, result Result, tables []string, generation uint64)
+	Invalidate(ctx context.Context, tables ...string)
+	Clear(ctx context.Context)
+}
+
+type lruEntry struct {
+	key    string
+	result Result
+	tables []string
+}
+
+// LRU is an in-memory store that evicts the least recently used result when it's full.
+type LRU struct {
+	mu          sync.Mutex
+	size        int
+	list        *list.List
+	items       map[string]*list.Element
+	tables      map[string]map[string]struct{}
+	generations map[string]uint64
+	clears      uint64
+}
+
+var _ Store = (*LRU)(nil)
+
+// Get cached result.
+func (l *LRU) Get(ctx context.Context, key string) (Result, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.items[key]; ok {
+		l.list.MoveToFront(elem)
+		return elem.Value.(*lruEntry).result, true
+	}
+
+	return Result{}, false
+}
+
+// Generation of the tables, it's the sum of invalidation count of every table and clear count of the store.
+func (l *LRU) Generation(ctx context.Context, tables []string) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.generation(tables)
+}
+
+func (l *LRU) generation(tables []string) uint64 {
+	generation := l.clears
+	for _, table := range tables {
+		generation += l.generations[table]
+	}
+
+	return generation
+}
+
+// Set result to cache, result is dropped when any of its tables is invalidated since the generation.
+func (l *LRU) Set(ctx context.Context, key string, result Result, tables []string, generation uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.generation(tables) != generation {
+		return
+	}
+
+	if elem, ok := l.items[key]; ok {
+		l.remove(elem)
+	}
+
+	l.items[key] = l.list.PushFront(&lruEntry{key: key, result: result, tables: tables})
+	for _, table := range tables {
+		if l.tables[table] == nil {
+			l.tables[table] = make(map[string]struct{})
+		}
+
+		l.tables[table][key] = struct{}{}
+	}
+
+	for l.list.Len() > l.size {
+		l.remove(l.list.Back())
+	}
+}
+
+// Invalidate results that use any of the tables.
+func (l *LRU) Invalidate(ctx context.Context, tables ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, table := range tables {
+		l.generations[table]++
+		for key := range l.tables[table] {
+			if elem, ok := l.items[key]; ok {
+				l.remove(elem)
+			}
+		}
+	}
+}
+
+// Clear all results.
+func (l *LRU) Clear(ctx context.Context) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.clears++
+	l.list.Init()
+	l.items = make(map[string]*list.Element)
+	l.tables = make(map[string]map[string]struct{})
+}
+
+// Len returns number of cached results.
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.list.Len()
+}
+
+func (l *LRU) remove(elem *list.Element) {
+	entry := l.list.Remove(elem).(*lruEntry)
+	delete(l.items, entry.key)
+
+	for _, table := range entry.tables {
+		delete(l.tables[table], entry.key)
+		if len(l.tables[table]) == 0 {
+			delete(l.tables, table)
+		}
+	}
+}
+
+// NewLRU in-memory store that holds at most size results.
+func NewLRU(size int) *LRU {
+	if size <= 0 {
+		size = DefaultSize
+	}
+
+	return &LRU{
+		size:        size,
+		list:        list.New(),
+		items:       make(map[string]*list.Element),
+		tables:      make(map[string]map[string]struct{}),
+		generations: make(map[string]uint64),
+	}
+}
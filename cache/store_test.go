@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	var (
+		ctx   = context.TODO()
+		store = NewLRU(2)
+	)
+
+	store.Set(ctx, "a", Result{Fields: []string{"a"}}, []string{"users"}, 0)
+	store.Set(ctx, "b", Result{Fields: []string{"b"}}, []string{"users", "addresses"}, 0)
+
+	result, ok := store.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a"}, result.Fields)
+
+	// b is the least recently used.
+	store.Set(ctx, "c", Result{Fields: []string{"c"}}, []string{"addresses"}, 0)
+	assert.Equal(t, 2, store.Len())
+
+	_, ok = store.Get(ctx, "b")
+	assert.False(t, ok)
+
+	store.Set(ctx, "c", Result{Fields: []string{"c2"}}, []string{"addresses"}, 0)
+	result, ok = store.Get(ctx, "c")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"c2"}, result.Fields)
+
+	store.Invalidate(ctx, "addresses")
+	assert.Equal(t, 1, store.Len())
+
+	_, ok = store.Get(ctx, "a")
+	assert.True(t, ok)
+
+	store.Clear(ctx)
+	assert.Equal(t, 0, store.Len())
+	assert.Equal(t, DefaultSize, NewLRU(0).size)
+}
+
+func TestLRU_generation(t *testing.T) {
+	var (
+		ctx        = context.TODO()
+		store      = NewLRU(2)
+		generation = store.Generation(ctx, []string{"users"})
+	)
+
+	store.Invalidate(ctx, "addresses")
+	assert.Equal(t, generation, store.Generation(ctx, []string{"users"}))
+
+	// result read before the table is invalidated is dropped.
+	store.Invalidate(ctx, "users")
+	store.Set(ctx, "a", Result{Fields: []string{"a"}}, []string{"users"}, generation)
+	assert.Equal(t, 0, store.Len())
+
+	generation = store.Generation(ctx, []string{"users"})
+	store.Clear(ctx)
+	store.Set(ctx, "a", Result{Fields: []string{"a"}}, []string{"users"}, generation)
+	assert.Equal(t, 0, store.Len())
+
+	store.Set(ctx, "a", Result{Fields: []string{"a"}}, []string{"users"}, store.Generation(ctx, []string{"users"}))
+	assert.Equal(t, 1, store.Len())
+}
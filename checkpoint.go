@@ -0,0 +1,87 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// CheckpointStore persists iterator checkpoint by name, so long running iteration can be resumed after restart.
+// Load returns empty string when there's no checkpoint saved using the name.
+type CheckpointStore interface {
+	Load(ctx context.Context, name string) (string, error)
+	Save(ctx context.Context, name string, checkpoint string) error
+}
+
+type memoryCheckpointStore struct {
+	mutex       sync.RWMutex
+	checkpoints map[string]string
+}
+
+func (s *memoryCheckpointStore) Load(ctx context.Context, name string) (string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.checkpoints[name], nil
+}
+
+func (s *memoryCheckpointStore) Save(ctx context.Context, name string, checkpoint string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.checkpoints[name] = checkpoint
+	return nil
+}
+
+// NewMemoryCheckpointStore returns checkpoint store that keeps checkpoints in memory.
+// Checkpoints are lost when the process exits, it's useful for testing or to resume iteration within the same process.
+func NewMemoryCheckpointStore() CheckpointStore {
+	return &memoryCheckpointStore{
+		checkpoints: make(map[string]string),
+	}
+}
+
+// IteratorCheckpoint is a record used by repository checkpoint store.
+// It's stored in iterator_checkpoints table with name (primary), checkpoint and updated_at column.
+type IteratorCheckpoint struct {
+	Name       string `db:",primary"`
+	Checkpoint string
+	UpdatedAt  time.Time
+}
+
+type repositoryCheckpointStore struct {
+	repo Repository
+}
+
+func (s repositoryCheckpointStore) Load(ctx context.Context, name string) (string, error) {
+	var (
+		record IteratorCheckpoint
+	)
+
+	if err := s.repo.Find(ctx, &record, Eq("name", name)); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	return record.Checkpoint, nil
+}
+
+func (s repositoryCheckpointStore) Save(ctx context.Context, name string, checkpoint string) error {
+	updated, err := s.repo.UpdateAny(ctx, From("iterator_checkpoints").Where(Eq("name", name)),
+		Set("checkpoint", checkpoint), Set("updated_at", Now()))
+	if err != nil || updated > 0 {
+		return err
+	}
+
+	return s.repo.Insert(ctx, &IteratorCheckpoint{Name: name, Checkpoint: checkpoint})
+}
+
+// NewRepositoryCheckpointStore returns checkpoint store that keeps checkpoints in iterator_checkpoints table.
+// The table must be created using migration before the store is used.
+func NewRepositoryCheckpointStore(repo Repository) CheckpointStore {
+	return repositoryCheckpointStore{repo: repo}
+}
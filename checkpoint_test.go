@@ -0,0 +1,128 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMemoryCheckpointStore(t *testing.T) {
+	var (
+		ctx   = context.TODO()
+		store = NewMemoryCheckpointStore()
+	)
+
+	checkpoint, err := store.Load(ctx, "users")
+	assert.Nil(t, err)
+	assert.Equal(t, "", checkpoint)
+
+	assert.Nil(t, store.Save(ctx, "users", "checkpoint"))
+
+	checkpoint, err = store.Load(ctx, "users")
+	assert.Nil(t, err)
+	assert.Equal(t, "checkpoint", checkpoint)
+}
+
+func TestRepositoryCheckpointStore_Load(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		store   = NewRepositoryCheckpointStore(New(adapter))
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"name", "checkpoint"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan("users", "checkpoint").Once()
+	cur.On("Next").Return(false).Once()
+
+	adapter.On("Query", From("iterator_checkpoints").Where(Eq("name", "users")).Limit(1)).Return(cur, nil).Once()
+
+	checkpoint, err := store.Load(context.TODO(), "users")
+	assert.Nil(t, err)
+	assert.Equal(t, "checkpoint", checkpoint)
+	assert.False(t, cur.Next())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepositoryCheckpointStore_Load_notFound(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		store   = NewRepositoryCheckpointStore(New(adapter))
+		cur     = createCursor(0)
+	)
+
+	adapter.On("Query", From("iterator_checkpoints").Where(Eq("name", "users")).Limit(1)).Return(cur, nil).Once()
+
+	checkpoint, err := store.Load(context.TODO(), "users")
+	assert.Nil(t, err)
+	assert.Equal(t, "", checkpoint)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepositoryCheckpointStore_Load_error(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		store   = NewRepositoryCheckpointStore(New(adapter))
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", From("iterator_checkpoints").Where(Eq("name", "users")).Limit(1)).Return(&testCursor{}, err).Once()
+
+	checkpoint, lerr := store.Load(context.TODO(), "users")
+	assert.Equal(t, err, lerr)
+	assert.Equal(t, "", checkpoint)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepositoryCheckpointStore_Save(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		store   = NewRepositoryCheckpointStore(New(adapter))
+		query   = From("iterator_checkpoints").Where(Eq("name", "users"))
+	)
+
+	adapter.On("Update", query, "", mock.Anything).Return(1, nil).Once()
+
+	assert.Nil(t, store.Save(context.TODO(), "users", "checkpoint"))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepositoryCheckpointStore_Save_insert(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		store   = NewRepositoryCheckpointStore(New(adapter))
+		query   = From("iterator_checkpoints").Where(Eq("name", "users"))
+	)
+
+	adapter.On("Update", query, "", mock.Anything).Return(0, nil).Once()
+	adapter.On("Insert", From("iterator_checkpoints"), mock.Anything, OnConflict{}).Return("users", nil).Once()
+
+	assert.Nil(t, store.Save(context.TODO(), "users", "checkpoint"))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepositoryCheckpointStore_Save_error(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		store   = NewRepositoryCheckpointStore(New(adapter))
+		query   = From("iterator_checkpoints").Where(Eq("name", "users"))
+		err     = errors.New("error")
+	)
+
+	adapter.On("Update", query, "", mock.Anything).Return(0, err).Once()
+
+	assert.Equal(t, err, store.Save(context.TODO(), "users", "checkpoint"))
+
+	adapter.AssertExpectations(t)
+}
@@ -0,0 +1,49 @@
+package rel
+
+// CombineOp defines enumeration of set operations used to combine queries.
+type CombineOp int
+
+func (co CombineOp) String() string {
+	return [...]string{
+		"Union",
+		"UnionAll",
+		"Intersect",
+		"Except",
+	}[co]
+}
+
+const (
+	// CombineUnionOp combines distinct result of queries.
+	CombineUnionOp CombineOp = iota
+	// CombineUnionAllOp combines all result of queries including duplicates.
+	CombineUnionAllOp
+	// CombineIntersectOp returns distinct result that exists in both queries.
+	CombineIntersectOp
+	// CombineExceptOp returns distinct result of the first query that doesn't exists in the other query.
+	CombineExceptOp
+)
+
+// CombineQuery defines query to be combined with the main query using set operation.
+// Sort, offset and limit of the main query applies to the combined result.
+type CombineQuery struct {
+	Op    CombineOp
+	Query Query
+}
+
+// Build query.
+func (cq CombineQuery) Build(query *Query) {
+	query.CombineQuery = append(query.CombineQuery, cq)
+}
+
+// String representation.
+func (cq CombineQuery) String() string {
+	return "." + cq.Op.String() + "(" + cq.Query.String() + ")"
+}
+
+// NewCombine query using set operation.
+func NewCombine(op CombineOp, query Query) CombineQuery {
+	return CombineQuery{
+		Op:    op,
+		Query: query,
+	}
+}
@@ -0,0 +1,19 @@
+package rel_test
+
+import (
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineOp_String(t *testing.T) {
+	assert.Equal(t, "Union", rel.CombineUnionOp.String())
+	assert.Equal(t, "UnionAll", rel.CombineUnionAllOp.String())
+	assert.Equal(t, "Intersect", rel.CombineIntersectOp.String())
+	assert.Equal(t, "Except", rel.CombineExceptOp.String())
+}
+
+func TestCombineQuery_String(t *testing.T) {
+	assert.Equal(t, ".Union(rel.From(\"admins\"))", rel.NewCombine(rel.CombineUnionOp, rel.From("admins")).String())
+}
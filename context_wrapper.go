@@ -2,6 +2,7 @@ package rel
 
 import (
 	"context"
+	"strconv"
 )
 
 type contextKey int8
@@ -11,7 +12,11 @@ type contextWrapper struct {
 	adapter Adapter
 }
 
-var ctxKey contextKey
+var (
+	ctxKey       contextKey
+	savepointKey contextKey = 1
+	callbackKey  contextKey = 2
+)
 
 // fetchContext and use adapter passed by context if exists.
 // it stores contextData values to struct for fast repeated access.
@@ -33,3 +38,20 @@ func wrapContext(ctx context.Context, adapter Adapter) contextWrapper {
 		adapter: adapter,
 	}
 }
+
+// inTransaction returns true if context is wrapped by a transaction.
+func (cw contextWrapper) inTransaction() bool {
+	_, ok := cw.ctx.Value(ctxKey).(Adapter)
+	return ok
+}
+
+// savepoint returns name of new savepoint and wraps its depth inside context.
+func (cw contextWrapper) savepoint() (contextWrapper, string) {
+	depth, _ := cw.ctx.Value(savepointKey).(int)
+	depth++
+
+	return contextWrapper{
+		ctx:     context.WithValue(cw.ctx, savepointKey, depth),
+		adapter: cw.adapter,
+	}, "rel_savepoint_" + strconv.Itoa(depth)
+}
@@ -14,10 +14,10 @@ type Cursor interface {
 	NopScanner() interface{} // TODO: conflict with manual scanners interface
 }
 
-func scanOne(cur Cursor, doc *Document) error {
+func scanOne(cur Cursor, doc *Document, options ...ScanOption) error {
 	defer cur.Close()
 
-	fields, err := cur.Fields()
+	fields, err := scanFields(cur, doc.meta, options)
 	if err != nil {
 		return err
 	}
@@ -33,10 +33,10 @@ func scanOne(cur Cursor, doc *Document) error {
 	return cur.Scan(scanners...)
 }
 
-func scanAll(cur Cursor, col *Collection) error {
+func scanAll(cur Cursor, col *Collection, options ...ScanOption) error {
 	defer cur.Close()
 
-	fields, err := cur.Fields()
+	fields, err := scanFields(cur, col.meta, options)
 	if err != nil {
 		return err
 	}
@@ -55,6 +55,94 @@ func scanAll(cur Cursor, col *Collection) error {
 	return nil
 }
 
+func scanMap(cur Cursor, m *Map) error {
+	defer cur.Close()
+
+	fields, err := cur.Fields()
+	if err != nil {
+		return err
+	}
+
+	if !cur.Next() {
+		return NotFoundError{}
+	}
+
+	*m = make(Map, len(fields))
+	return cur.Scan(m.scanners(fields)...)
+}
+
+func scanMaps(cur Cursor, maps *[]Map) error {
+	defer cur.Close()
+
+	fields, err := cur.Fields()
+	if err != nil {
+		return err
+	}
+
+	*maps = (*maps)[:0]
+	for cur.Next() {
+		m := make(Map, len(fields))
+		if err := cur.Scan(m.scanners(fields)...); err != nil {
+			return err
+		}
+
+		*maps = append(*maps, m)
+	}
+
+	return nil
+}
+
+// scanPluck scans the first column of every row into slice pointed by rv.
+func scanPluck(cur Cursor, rv reflect.Value) error {
+	defer cur.Close()
+
+	fields, err := cur.Fields()
+	if err != nil {
+		return err
+	}
+
+	var (
+		rt       = rv.Type().Elem()
+		result   = reflect.MakeSlice(rv.Type(), 0, 0)
+		scanners = make([]interface{}, len(fields))
+	)
+
+	for i := 1; i < len(fields); i++ {
+		scanners[i] = cur.NopScanner()
+	}
+
+	for cur.Next() {
+		ev := reflect.New(rt)
+		if len(scanners) > 0 {
+			scanners[0] = Nullable(ev.Interface())
+		}
+
+		if err := cur.Scan(scanners...); err != nil {
+			return err
+		}
+
+		result = reflect.Append(result, ev.Elem())
+	}
+
+	rv.Set(result)
+	return nil
+}
+
+func scanFields(cur Cursor, meta DocumentMeta, options []ScanOption) ([]string, error) {
+	fields, err := cur.Fields()
+	if err != nil {
+		return nil, err
+	}
+
+	if config := applyScanOptions(options); config.strict {
+		if err := checkFields(meta, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	return fields, nil
+}
+
 func scanMulti(cur Cursor, keyField string, keyType reflect.Type, cols map[interface{}][]slice) error {
 	defer cur.Close()
 
@@ -28,6 +28,22 @@ var (
 	// ErrForeignKeyConstraint is an auxiliary variable for error handling.
 	// This is only to be used when checking error with errors.Is(err, ErrForeignKeyConstraint).
 	ErrForeignKeyConstraint = ConstraintError{Type: ForeignKeyConstraint}
+
+	// ErrSerializationFailure is an auxiliary variable for error handling.
+	// This is only to be used when checking error with errors.Is(err, ErrSerializationFailure).
+	ErrSerializationFailure = ConcurrencyError{Type: SerializationFailure}
+
+	// ErrDeadlock is an auxiliary variable for error handling.
+	// This is only to be used when checking error with errors.Is(err, ErrDeadlock).
+	ErrDeadlock = ConcurrencyError{Type: Deadlock}
+
+	// ErrLockTimeout is an auxiliary variable for error handling.
+	// This is only to be used when checking error with errors.Is(err, ErrLockTimeout).
+	ErrLockTimeout = ConcurrencyError{Type: LockTimeout}
+
+	// ErrLockNotAvailable is an auxiliary variable for error handling.
+	// This is only to be used when checking error with errors.Is(err, ErrLockNotAvailable).
+	ErrLockNotAvailable = ConcurrencyError{Type: LockNotAvailable}
 )
 
 // NotFoundError returned whenever Find returns no result.
@@ -106,3 +122,64 @@ func (ce ConstraintError) Error() string {
 
 	return ce.Type.String() + "Error"
 }
+
+// ConcurrencyErrorType defines the type of concurrency error.
+type ConcurrencyErrorType int8
+
+const (
+	// SerializationFailure error type.
+	SerializationFailure ConcurrencyErrorType = iota
+	// Deadlock error type.
+	Deadlock
+	// LockTimeout error type.
+	LockTimeout
+	// LockNotAvailable error type, returned when row lock with NOWAIT can't be acquired immediately.
+	LockNotAvailable
+)
+
+// String representation of the concurrency error type.
+func (cet ConcurrencyErrorType) String() string {
+	switch cet {
+	case SerializationFailure:
+		return "SerializationFailure"
+	case Deadlock:
+		return "Deadlock"
+	case LockTimeout:
+		return "LockTimeout"
+	case LockNotAvailable:
+		return "LockNotAvailable"
+	default:
+		return ""
+	}
+}
+
+// ConcurrencyError returned whenever transaction fails because of concurrent access,
+// such as serialization failure, deadlock, lock timeout and unavailable lock.
+// Transaction that fails with this error is safe to be retried.
+type ConcurrencyError struct {
+	Type ConcurrencyErrorType
+	Err  error
+}
+
+// Is returns true when target error have the same type.
+func (ce ConcurrencyError) Is(target error) bool {
+	if err, ok := target.(ConcurrencyError); ok {
+		return ce.Type == err.Type
+	}
+
+	return false
+}
+
+// Unwrap internal error returned by database driver.
+func (ce ConcurrencyError) Unwrap() error {
+	return ce.Err
+}
+
+// Error message.
+func (ce ConcurrencyError) Error() string {
+	if ce.Err != nil {
+		return ce.Type.String() + "Error: " + ce.Err.Error()
+	}
+
+	return ce.Type.String() + "Error"
+}
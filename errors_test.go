@@ -95,3 +95,30 @@ func TestConstraintError_Is(t *testing.T) {
 		})
 	}
 }
+
+func TestConcurrencyErrorType(t *testing.T) {
+	assert.Equal(t, "SerializationFailure", SerializationFailure.String())
+	assert.Equal(t, "Deadlock", Deadlock.String())
+	assert.Equal(t, "LockTimeout", LockTimeout.String())
+	assert.Equal(t, "LockNotAvailable", LockNotAvailable.String())
+	assert.Equal(t, "", ConcurrencyErrorType(100).String())
+}
+
+func TestConcurrencyError(t *testing.T) {
+	err := ConcurrencyError{Type: Deadlock, Err: errors.New("deadlock detected")}
+	assert.NotNil(t, err.Unwrap())
+	assert.Equal(t, "DeadlockError: deadlock detected", err.Error())
+
+	err = ConcurrencyError{Type: Deadlock}
+	assert.Nil(t, err.Unwrap())
+	assert.Equal(t, "DeadlockError", err.Error())
+}
+
+func TestConcurrencyError_Is(t *testing.T) {
+	assert.True(t, ConcurrencyError{Type: SerializationFailure, Err: errors.New("error")}.Is(ErrSerializationFailure))
+	assert.True(t, ErrLockTimeout.Is(ConcurrencyError{Type: LockTimeout}))
+	assert.False(t, ErrDeadlock.Is(ErrLockTimeout))
+	assert.True(t, ErrLockNotAvailable.Is(ConcurrencyError{Type: LockNotAvailable}))
+	assert.False(t, ErrLockTimeout.Is(ErrLockNotAvailable))
+	assert.False(t, ErrDeadlock.Is(ErrUniqueConstraint))
+}
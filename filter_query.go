@@ -26,6 +26,8 @@ func (fo FilterOp) String() string {
 		"Like",
 		"NotLike",
 		"Fragment",
+		"Exists",
+		"NotExists",
 	}[fo]
 }
 
@@ -68,6 +70,11 @@ const (
 
 	// FilterFragmentOp is filter type for custom filter.
 	FilterFragmentOp
+
+	// FilterExistsOp is filter type for sub query existence check.
+	FilterExistsOp
+	// FilterNotExistsOp is filter type for sub query non-existence check.
+	FilterNotExistsOp
 )
 
 // FilterQuery defines details of a condition type.
@@ -124,6 +131,8 @@ func (fq FilterQuery) String() string {
 		builder.WriteString(fq.Field)
 		builder.WriteString("\", ")
 		builder.WriteString(fmtifaces(fq.Value.([]interface{})))
+	case FilterExistsOp, FilterNotExistsOp:
+		builder.WriteString(fmtiface(fq.Value))
 	case FilterFragmentOp:
 		v := fq.Value.([]interface{})
 		builder.WriteByte('"')
@@ -251,6 +260,16 @@ func (fq FilterQuery) AndNotLike(field string, pattern string) FilterQuery {
 	return fq.and(NotLike(field, pattern))
 }
 
+// AndExists append exists expression using and.
+func (fq FilterQuery) AndExists(sub Query) FilterQuery {
+	return fq.and(Exists(sub))
+}
+
+// AndNotExists append not exists expression using and.
+func (fq FilterQuery) AndNotExists(sub Query) FilterQuery {
+	return fq.and(NotExists(sub))
+}
+
 // AndFragment append fragment using and.
 func (fq FilterQuery) AndFragment(expr string, values ...interface{}) FilterQuery {
 	return fq.and(FilterFragment(expr, values...))
@@ -316,6 +335,16 @@ func (fq FilterQuery) OrNotLike(field string, pattern string) FilterQuery {
 	return fq.or(NotLike(field, pattern))
 }
 
+// OrExists append exists expression using or.
+func (fq FilterQuery) OrExists(sub Query) FilterQuery {
+	return fq.or(Exists(sub))
+}
+
+// OrNotExists append not exists expression using or.
+func (fq FilterQuery) OrNotExists(sub Query) FilterQuery {
+	return fq.or(NotExists(sub))
+}
+
 // OrFragment append fragment using or.
 func (fq FilterQuery) OrFragment(expr string, values ...interface{}) FilterQuery {
 	return fq.or(FilterFragment(expr, values...))
@@ -368,6 +397,10 @@ func Not(inner ...FilterQuery) FilterQuery {
 			fq.Type = FilterNinOp
 		case FilterLikeOp:
 			fq.Type = FilterNotLikeOp
+		case FilterExistsOp:
+			fq.Type = FilterNotExistsOp
+		case FilterNotExistsOp:
+			fq.Type = FilterExistsOp
 		default:
 			return FilterQuery{
 				Type:  FilterNotOp,
@@ -572,6 +605,24 @@ func NotLike(field string, pattern string) FilterQuery {
 	}
 }
 
+// Exists check whether sub query returns any row.
+// Sub query may refer to fields of the outer query using Ref.
+func Exists(sub Query) FilterQuery {
+	return FilterQuery{
+		Type:  FilterExistsOp,
+		Value: sub,
+	}
+}
+
+// NotExists check whether sub query returns no row.
+// Sub query may refer to fields of the outer query using Ref.
+func NotExists(sub Query) FilterQuery {
+	return FilterQuery{
+		Type:  FilterNotExistsOp,
+		Value: sub,
+	}
+}
+
 // FilterFragment add custom filter.
 func FilterFragment(expr string, values ...interface{}) FilterQuery {
 	return FilterQuery{
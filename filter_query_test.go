@@ -433,6 +433,16 @@ func TestFilterQuery_Not(t *testing.T) {
 			FilterLikeOp,
 			FilterNotLikeOp,
 		},
+		{
+			`Not Exists`,
+			FilterExistsOp,
+			FilterNotExistsOp,
+		},
+		{
+			`Not NotExists`,
+			FilterNotExistsOp,
+			FilterExistsOp,
+		},
 		{
 			`And Op`,
 			FilterAndOp,
@@ -601,6 +611,28 @@ func TestFilterQuery_AndFragment(t *testing.T) {
 	}, FilterQuery{}.AndFragment("expr", "value"))
 }
 
+func TestFilterQuery_AndExists(t *testing.T) {
+	assert.Equal(t, FilterQuery{
+		Inner: []FilterQuery{
+			{
+				Type:  FilterExistsOp,
+				Value: From("orders"),
+			},
+		},
+	}, FilterQuery{}.AndExists(From("orders")))
+}
+
+func TestFilterQuery_AndNotExists(t *testing.T) {
+	assert.Equal(t, FilterQuery{
+		Inner: []FilterQuery{
+			{
+				Type:  FilterNotExistsOp,
+				Value: From("orders"),
+			},
+		},
+	}, FilterQuery{}.AndNotExists(From("orders")))
+}
+
 func TestFilterQuery_OrEq(t *testing.T) {
 	assert.Equal(t, FilterQuery{
 		Type: FilterOrOp,
@@ -768,6 +800,30 @@ func TestFilterQuery_OrFragment(t *testing.T) {
 	}, FilterQuery{}.OrFragment("expr", "value"))
 }
 
+func TestFilterQuery_OrExists(t *testing.T) {
+	assert.Equal(t, FilterQuery{
+		Type: FilterOrOp,
+		Inner: []FilterQuery{
+			{
+				Type:  FilterExistsOp,
+				Value: From("orders"),
+			},
+		},
+	}, FilterQuery{}.OrExists(From("orders")))
+}
+
+func TestFilterQuery_OrNotExists(t *testing.T) {
+	assert.Equal(t, FilterQuery{
+		Type: FilterOrOp,
+		Inner: []FilterQuery{
+			{
+				Type:  FilterNotExistsOp,
+				Value: From("orders"),
+			},
+		},
+	}, FilterQuery{}.OrNotExists(From("orders")))
+}
+
 func TestEq(t *testing.T) {
 	assert.Equal(t, FilterQuery{
 		Type:  FilterEqOp,
@@ -918,6 +974,26 @@ func TestFilterFragment(t *testing.T) {
 	}, FilterFragment("expr", "value"))
 }
 
+func TestExists(t *testing.T) {
+	sub := From("orders").Where(Eq("orders.user_id", Ref("users.id")))
+
+	assert.Equal(t, FilterQuery{
+		Type:  FilterExistsOp,
+		Value: sub,
+	}, Exists(sub))
+	assert.Equal(t, "where.Exists(rel.From(\"orders\").Where(where.Eq(\"orders.user_id\", rel.Ref(\"users.id\"))))", Exists(sub).String())
+}
+
+func TestNotExists(t *testing.T) {
+	sub := From("orders")
+
+	assert.Equal(t, FilterQuery{
+		Type:  FilterNotExistsOp,
+		Value: sub,
+	}, NotExists(sub))
+	assert.Equal(t, "where.NotExists(rel.From(\"orders\"))", NotExists(sub).String())
+}
+
 func TestFilterDocument(t *testing.T) {
 	var (
 		user = User{ID: 1}
@@ -0,0 +1,196 @@
+package rel
+
+import (
+	"context"
+	"reflect"
+)
+
+// BeforeInserter is implemented by record that needs to run logic before it's inserted.
+// Changes made to the record are included in the insert.
+type BeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// AfterInserter is implemented by record that needs to run logic after it's inserted.
+type AfterInserter interface {
+	AfterInsert(ctx context.Context) error
+}
+
+// BeforeUpdater is implemented by record that needs to run logic before it's updated.
+// Mutation can be modified to alter what will be updated.
+type BeforeUpdater interface {
+	BeforeUpdate(ctx context.Context, mutation *Mutation) error
+}
+
+// AfterUpdater is implemented by record that needs to run logic after it's updated.
+type AfterUpdater interface {
+	AfterUpdate(ctx context.Context) error
+}
+
+// BeforeDeleter is implemented by record that needs to run logic before it's deleted.
+type BeforeDeleter interface {
+	BeforeDelete(ctx context.Context) error
+}
+
+// AfterDeleter is implemented by record that needs to run logic after it's deleted.
+type AfterDeleter interface {
+	AfterDelete(ctx context.Context) error
+}
+
+// AfterFinder is implemented by record that needs to run logic after it's loaded from database.
+type AfterFinder interface {
+	AfterFind(ctx context.Context) error
+}
+
+var (
+	beforeInserterType = reflect.TypeOf((*BeforeInserter)(nil)).Elem()
+	afterInserterType  = reflect.TypeOf((*AfterInserter)(nil)).Elem()
+	beforeUpdaterType  = reflect.TypeOf((*BeforeUpdater)(nil)).Elem()
+	afterUpdaterType   = reflect.TypeOf((*AfterUpdater)(nil)).Elem()
+	beforeDeleterType  = reflect.TypeOf((*BeforeDeleter)(nil)).Elem()
+	afterDeleterType   = reflect.TypeOf((*AfterDeleter)(nil)).Elem()
+	afterFinderType    = reflect.TypeOf((*AfterFinder)(nil)).Elem()
+)
+
+func hasHook(meta DocumentMeta, hooks ...reflect.Type) bool {
+	rt := reflect.PtrTo(meta.rt)
+	for i := range hooks {
+		if rt.Implements(hooks[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasInsertHook(meta DocumentMeta) bool {
+	return hasHook(meta, beforeInserterType, afterInserterType)
+}
+
+func hasUpdateHook(meta DocumentMeta) bool {
+	return hasHook(meta, beforeUpdaterType, afterUpdaterType)
+}
+
+func hasDeleteHook(meta DocumentMeta) bool {
+	return hasHook(meta, beforeDeleterType, afterDeleterType)
+}
+
+// eachDocument calls fn for every non nil record in collection.
+func eachDocument(col *Collection, fn func(doc *Document) error) error {
+	for i := 0; i < col.Len(); i++ {
+		if rv := col.rv.Index(i); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			continue
+		}
+
+		if err := fn(col.Get(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func beforeInsert(ctx context.Context, doc *Document, mutation *Mutation) error {
+	if hook, ok := doc.v.(BeforeInserter); ok {
+		values := fieldValues(doc)
+		if err := hook.BeforeInsert(ctx); err != nil {
+			return err
+		}
+
+		refreshMutates(doc, mutation, values)
+	}
+
+	return nil
+}
+
+func afterInsert(ctx context.Context, doc *Document) error {
+	if hook, ok := doc.v.(AfterInserter); ok {
+		return hook.AfterInsert(ctx)
+	}
+
+	return nil
+}
+
+func beforeUpdate(ctx context.Context, doc *Document, mutation *Mutation) error {
+	if hook, ok := doc.v.(BeforeUpdater); ok {
+		values := fieldValues(doc)
+		if err := hook.BeforeUpdate(ctx, mutation); err != nil {
+			return err
+		}
+
+		refreshMutates(doc, mutation, values)
+	}
+
+	return nil
+}
+
+func afterUpdate(ctx context.Context, doc *Document) error {
+	if hook, ok := doc.v.(AfterUpdater); ok {
+		return hook.AfterUpdate(ctx)
+	}
+
+	return nil
+}
+
+func beforeDelete(ctx context.Context, doc *Document) error {
+	if hook, ok := doc.v.(BeforeDeleter); ok {
+		return hook.BeforeDelete(ctx)
+	}
+
+	return nil
+}
+
+func afterDelete(ctx context.Context, doc *Document) error {
+	if hook, ok := doc.v.(AfterDeleter); ok {
+		return hook.AfterDelete(ctx)
+	}
+
+	return nil
+}
+
+func afterFind(ctx context.Context, records slice) error {
+	if !hasHook(records.Meta(), afterFinderType) {
+		return nil
+	}
+
+	for i := 0; i < records.Len(); i++ {
+		if hook, ok := records.Get(i).v.(AfterFinder); ok {
+			if err := hook.AfterFind(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// fieldValues returns current value of every field of the record.
+func fieldValues(doc *Document) map[string]interface{} {
+	var (
+		fields = doc.Fields()
+		values = make(map[string]interface{}, len(fields))
+	)
+
+	for _, field := range fields {
+		if value, ok := doc.Value(field); ok {
+			values[field] = value
+		}
+	}
+
+	return values
+}
+
+// refreshMutates saves fields that are changed by hook directly on the record,
+// set mutate is added for changed field that is not in the mutation, other type of mutate is left as is.
+func refreshMutates(doc *Document, mutation *Mutation, values map[string]interface{}) {
+	for field, old := range values {
+		value, _ := doc.Value(field)
+		if reflect.DeepEqual(old, value) {
+			continue
+		}
+
+		if mut, ok := mutation.Mutates[field]; !ok || mut.Type == ChangeSetOp {
+			mutation.Add(Set(field, value))
+		}
+	}
+}
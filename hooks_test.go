@@ -0,0 +1,451 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var hookCalls []string
+
+type hookRecord struct {
+	ID    int
+	Name  string
+	Slug  string
+	Items []hookItem `autosave:"true"`
+	Fail  string     `db:"-"`
+}
+
+func (hr *hookRecord) call(hook string) error {
+	hookCalls = append(hookCalls, "record."+hook)
+	if hr.Fail == hook {
+		return errors.New("rel: " + hook + " error")
+	}
+
+	return nil
+}
+
+func (hr *hookRecord) BeforeInsert(ctx context.Context) error {
+	hr.Slug = "slug-" + hr.Name
+	return hr.call("BeforeInsert")
+}
+
+func (hr *hookRecord) AfterInsert(ctx context.Context) error {
+	return hr.call("AfterInsert")
+}
+
+func (hr *hookRecord) BeforeUpdate(ctx context.Context, mutation *Mutation) error {
+	mutation.Add(Set("slug", "slug-"+hr.Name))
+	return hr.call("BeforeUpdate")
+}
+
+func (hr *hookRecord) AfterUpdate(ctx context.Context) error {
+	return hr.call("AfterUpdate")
+}
+
+func (hr *hookRecord) BeforeDelete(ctx context.Context) error {
+	return hr.call("BeforeDelete")
+}
+
+func (hr *hookRecord) AfterDelete(ctx context.Context) error {
+	return hr.call("AfterDelete")
+}
+
+func (hr *hookRecord) AfterFind(ctx context.Context) error {
+	return hr.call("AfterFind")
+}
+
+type hookItem struct {
+	ID           int
+	HookRecordID int
+	Name         string
+}
+
+func (hi *hookItem) BeforeInsert(ctx context.Context) error {
+	hookCalls = append(hookCalls, "item.BeforeInsert")
+	return nil
+}
+
+func (hi *hookItem) AfterInsert(ctx context.Context) error {
+	hookCalls = append(hookCalls, "item.AfterInsert")
+	return nil
+}
+
+func (hi *hookItem) BeforeDelete(ctx context.Context) error {
+	hookCalls = append(hookCalls, "item.BeforeDelete")
+	return nil
+}
+
+func (hi *hookItem) AfterDelete(ctx context.Context) error {
+	hookCalls = append(hookCalls, "item.AfterDelete")
+	return nil
+}
+
+func TestRepository_Insert_hooks(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		record  = hookRecord{Name: "name"}
+	)
+
+	hookCalls = nil
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("hook_records"), map[string]Mutate{
+		"name": Set("name", "name"),
+		"slug": Set("slug", "slug-name"),
+	}, OnConflict{}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &record))
+	assert.Equal(t, 1, record.ID)
+	assert.Equal(t, "slug-name", record.Slug)
+	assert.Equal(t, []string{"record.BeforeInsert", "record.AfterInsert"}, hookCalls)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_hooksWithMutates(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		record  = hookRecord{}
+	)
+
+	hookCalls = nil
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("hook_records"), map[string]Mutate{
+		"name": Set("name", "name"),
+		"slug": Set("slug", "slug-name"),
+	}, OnConflict{}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &record, Set("name", "name")))
+	assert.Equal(t, 1, record.ID)
+	assert.Equal(t, "slug-name", record.Slug)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_hooksCascade(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		record  = hookRecord{Name: "name", Items: []hookItem{{Name: "a"}, {Name: "b"}}}
+	)
+
+	hookCalls = nil
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", From("hook_records"), mock.Anything, OnConflict{}).Return(1, nil).Once()
+	adapter.On("InsertAll", From("hook_items"), mock.Anything, mock.Anything, OnConflict{}).Return([]interface{}{1, 2}, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Insert(context.TODO(), &record))
+	assert.Equal(t, []string{
+		"record.BeforeInsert",
+		"item.BeforeInsert",
+		"item.BeforeInsert",
+		"item.AfterInsert",
+		"item.AfterInsert",
+		"record.AfterInsert",
+	}, hookCalls)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_hooksError(t *testing.T) {
+	tests := []string{"BeforeInsert", "AfterInsert"}
+
+	for _, hook := range tests {
+		t.Run(hook, func(t *testing.T) {
+			var (
+				adapter = &testAdapter{}
+				repo    = New(adapter)
+				record  = hookRecord{Name: "name", Fail: hook}
+			)
+
+			adapter.On("Begin").Return(nil).Once()
+			if hook == "AfterInsert" {
+				adapter.On("Insert", From("hook_records"), mock.Anything, OnConflict{}).Return(1, nil).Once()
+			}
+			adapter.On("Rollback").Return(nil).Once()
+
+			assert.Equal(t, errors.New("rel: "+hook+" error"), repo.Insert(context.TODO(), &record))
+
+			adapter.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRepository_InsertAll_hooks(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		records = []hookRecord{{Name: "a"}, {Name: "b"}}
+	)
+
+	hookCalls = nil
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("InsertAll", From("hook_records"), mock.Anything, []map[string]Mutate{
+		{"name": Set("name", "a"), "slug": Set("slug", "slug-a")},
+		{"name": Set("name", "b"), "slug": Set("slug", "slug-b")},
+	}, OnConflict{}).Return([]interface{}{1, 2}, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.InsertAll(context.TODO(), &records))
+	assert.Equal(t, []string{
+		"record.BeforeInsert",
+		"record.BeforeInsert",
+		"record.AfterInsert",
+		"record.AfterInsert",
+	}, hookCalls)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_hooks(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		record  = hookRecord{ID: 1, Name: "name"}
+	)
+
+	hookCalls = nil
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("hook_records").Where(Eq("id", 1)), "id", map[string]Mutate{
+		"name": Set("name", "updated"),
+		"slug": Set("slug", "slug-updated"),
+	}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Update(context.TODO(), &record, Set("name", "updated")))
+	assert.Equal(t, []string{"record.BeforeUpdate", "record.AfterUpdate"}, hookCalls)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_hooksError(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		record  = hookRecord{ID: 1, Name: "name", Fail: "BeforeUpdate"}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, errors.New("rel: BeforeUpdate error"), repo.Update(context.TODO(), &record))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete_hooks(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		record  = hookRecord{ID: 1, Name: "name", Items: []hookItem{{ID: 1, HookRecordID: 1}}}
+	)
+
+	hookCalls = nil
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", From("hook_items").Where(Eq("hook_record_id", 1).AndIn("id", 1))).Return(1, nil).Once()
+	adapter.On("Delete", From("hook_records").Where(Eq("id", 1))).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Delete(context.TODO(), &record, Cascade(true)))
+	assert.Equal(t, []string{
+		"record.BeforeDelete",
+		"item.BeforeDelete",
+		"item.AfterDelete",
+		"record.AfterDelete",
+	}, hookCalls)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete_hooksError(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		record  = hookRecord{ID: 1, Name: "name", Fail: "AfterDelete"}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", From("hook_records").Where(Eq("id", 1))).Return(1, nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, errors.New("rel: AfterDelete error"), repo.Delete(context.TODO(), &record))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_DeleteAll_hooks(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		records = []hookRecord{{ID: 1}, {ID: 2}}
+	)
+
+	hookCalls = nil
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", From("hook_records").Where(In("id", 1, 2))).Return(2, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.DeleteAll(context.TODO(), &records))
+	assert.Equal(t, []string{
+		"record.BeforeDelete",
+		"record.BeforeDelete",
+		"record.AfterDelete",
+		"record.AfterDelete",
+	}, hookCalls)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Find_hooks(t *testing.T) {
+	var (
+		record  hookRecord
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("hook_records").Limit(1)
+		cur     = createCursor(1)
+	)
+
+	hookCalls = nil
+
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Find(context.TODO(), &record, query))
+	assert.Equal(t, []string{"record.AfterFind"}, hookCalls)
+	assert.False(t, cur.Next())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_FindAll_hooks(t *testing.T) {
+	var (
+		records []hookRecord
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("hook_records")
+		cur     = createCursor(2)
+	)
+
+	hookCalls = nil
+
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &records, query))
+	assert.Equal(t, []string{"record.AfterFind", "record.AfterFind"}, hookCalls)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Iterate_hooks(t *testing.T) {
+	var (
+		records []hookRecord
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("hook_records")
+		cur     = createCursor(2)
+	)
+
+	hookCalls = nil
+
+	adapter.On("Query", query.SortAsc("id").Limit(1000)).Return(cur, nil).Once()
+
+	it := repo.Iterate(context.TODO(), query)
+	for {
+		var record hookRecord
+		if err := it.Next(&record); err == io.EOF {
+			break
+		} else {
+			assert.Nil(t, err)
+		}
+
+		records = append(records, record)
+	}
+	it.Close()
+
+	assert.Len(t, records, 2)
+	assert.Equal(t, []string{"record.AfterFind", "record.AfterFind"}, hookCalls)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Iterate_hooksError(t *testing.T) {
+	var (
+		record  = hookRecord{Fail: "AfterFind"}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("hook_records")
+		cur     = createCursor(1)
+	)
+
+	hookCalls = nil
+
+	adapter.On("Query", query.SortAsc("id").Limit(1000)).Return(cur, nil).Once()
+
+	it := repo.Iterate(context.TODO(), query)
+	assert.Equal(t, errors.New("rel: AfterFind error"), it.Next(&record))
+	it.Close()
+
+	// the last next is not called because of the error.
+	cur.Next()
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Iterate_preloadHooks(t *testing.T) {
+	var (
+		records []hookRecord
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("hook_records")
+		cur     = createCursor(2)
+		icur    = &testCursor{}
+	)
+
+	hookCalls = nil
+
+	icur.On("Close").Return(nil).Once()
+	icur.On("Fields").Return([]string{"id", "hook_record_id"}, nil).Once()
+	icur.On("Next").Return(false).Once()
+
+	adapter.On("Query", query.SortAsc("id").Limit(1000)).Return(cur, nil).Once()
+	adapter.On("Query", From("hook_items").Where(In("hook_record_id", 10))).Return(icur, nil).Once()
+
+	it := repo.Iterate(context.TODO(), query, IteratePreload("items"))
+	for {
+		var record hookRecord
+		if err := it.Next(&record); err == io.EOF {
+			break
+		} else {
+			assert.Nil(t, err)
+		}
+
+		records = append(records, record)
+	}
+	it.Close()
+
+	assert.Len(t, records, 2)
+	assert.Equal(t, []string{"record.AfterFind", "record.AfterFind"}, hookCalls)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+	icur.AssertExpectations(t)
+}
@@ -2,14 +2,25 @@ package rel
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"reflect"
+	"strings"
 )
 
+// ErrInvalidCheckpoint returned when iterator checkpoint is malformed, tampered, or it's created for different sort.
+var ErrInvalidCheckpoint = errors.New("rel: invalid iterator checkpoint")
+
 // Iterator allows iterating through all record in database in batch.
 type Iterator interface {
 	io.Closer
 	Next(record interface{}) error
+
+	// Checkpoint returns the position of the last record returned by Next as string,
+	// which can be used to resume the iteration later using Resume option.
+	// Empty string is returned when no record is returned yet and the iteration is not resumed.
+	Checkpoint() (string, error)
 }
 
 // IteratorOption is used to configure iteration behaviour, such as batch size, start id and finish id.
@@ -45,6 +56,7 @@ func (s start) String() string {
 }
 
 // Start specifies the primary value to start from (inclusive).
+// When SortBy is used, the values are matched to the sort fields instead.
 func Start(id ...interface{}) IteratorOption {
 	return start(id)
 }
@@ -61,21 +73,256 @@ func (f finish) String() string {
 }
 
 // Finish specifies the primary value to finish at (inclusive).
+// When SortBy is used, the values are matched to the sort fields instead.
 func Finish(id ...interface{}) IteratorOption {
 	return finish(id)
 }
 
+type sortBy []SortQuery
+
+func (sb sortBy) apply(i *iterator) {
+	i.sorts = sb
+}
+
+// String representation.
+func (sb sortBy) String() string {
+	var builder strings.Builder
+	builder.WriteString("rel.SortBy(")
+	for j, sq := range sb {
+		if j > 0 {
+			builder.WriteString(", ")
+		}
+
+		if sq.Asc() {
+			builder.WriteString("rel.SortAsc(\"")
+		} else {
+			builder.WriteString("rel.SortDesc(\"")
+		}
+		builder.WriteString(sq.Field)
+		builder.WriteString("\")")
+	}
+	builder.WriteByte(')')
+
+	return builder.String()
+}
+
+// SortBy iterates records in the order of given sort, primary fields are appended as the tie breaker.
+// Batches are fetched using the sort values of the last record instead of offset,
+// so the sort fields should be indexed. Nullable sort field must be defined as pointer,
+// use NullsLast option when the database sorts null as the largest value.
+func SortBy(sorts ...SortQuery) IteratorOption {
+	return sortBy(sorts)
+}
+
+// partition limits iteration to a range of primary value, it's used by parallel iteration.
+// Batches of a partition are always fetched using primary value of the last record.
+type partition struct {
+	start  int
+	finish int
+}
+
+func (p partition) apply(i *iterator) {
+	i.start = []interface{}{p.start}
+	i.finish = []interface{}{p.finish}
+	i.sorts = nil
+	i.checkpoint = ""
+	i.store = nil
+	i.partitioned = true
+}
+
+// String representation.
+func (p partition) String() string {
+	return fmt.Sprintf("rel.partition(%d, %d)", p.start, p.finish)
+}
+
+// partitions splits the range of primary value (inclusive) into at most n partitions with similar size.
+func partitions(min int, max int, n int) []partition {
+	span := max - min + 1
+	if n > span {
+		n = span
+	}
+
+	var (
+		result = make([]partition, n)
+		size   = span / n
+		rest   = span % n
+		start  = min
+	)
+
+	for j := range result {
+		finish := start + size - 1
+		if j < rest {
+			finish++
+		}
+
+		result[j] = partition{start: start, finish: finish}
+		start = finish + 1
+	}
+
+	return result
+}
+
+func isInteger(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+// unqualified returns field name without table prefix.
+func unqualified(field string) string {
+	return field[strings.LastIndexByte(field, '.')+1:]
+}
+
+func hasField(fields []string, field string) bool {
+	for i := range fields {
+		if fields[i] == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// iterateFunc wraps callback of parallel iteration, which must be a func(context.Context, *T) error.
+type iterateFunc struct {
+	fn reflect.Value
+	rt reflect.Type
+}
+
+func newIterateFunc(fn interface{}) iterateFunc {
+	var (
+		rv = reflect.ValueOf(fn)
+		rt = rv.Type()
+	)
+
+	if rt.Kind() != reflect.Func || rt.NumIn() != 2 || rt.In(0) != contextType || rt.In(1).Kind() != reflect.Ptr ||
+		rt.NumOut() != 1 || rt.Out(0) != errorType {
+		panic("rel: fn must be a func(context.Context, *T) error")
+	}
+
+	return iterateFunc{fn: rv, rt: rt.In(1).Elem()}
+}
+
+// record returns new pointer to the record type accepted by the callback.
+func (f iterateFunc) record() interface{} {
+	return reflect.New(f.rt).Interface()
+}
+
+func (f iterateFunc) call(ctx context.Context, record interface{}) error {
+	err, _ := f.fn.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(record)})[0].Interface().(error)
+	return err
+}
+
+type resume string
+
+func (r resume) apply(i *iterator) {
+	i.checkpoint = string(r)
+}
+
+// String representation.
+func (r resume) String() string {
+	return fmt.Sprintf("rel.Resume(%q)", string(r))
+}
+
+// Resume iteration after the record of the checkpoint (exclusive).
+// Empty checkpoint starts the iteration from the beginning.
+// Checkpoint can only be used to resume iteration with the same sort.
+// Unlike page token, checkpoint is not signed, so it shouldn't be accepted from untrusted client.
+func Resume(checkpoint string) IteratorOption {
+	return resume(checkpoint)
+}
+
+type checkpoints struct {
+	store CheckpointStore
+	name  string
+}
+
+func (c checkpoints) apply(i *iterator) {
+	i.store = c.store
+	i.name = c.name
+}
+
+// String representation.
+func (c checkpoints) String() string {
+	return fmt.Sprintf("rel.Checkpoints(%q)", c.name)
+}
+
+// Checkpoints resumes iteration from the checkpoint saved in the store using given name,
+// and saves the checkpoint to the store every time a batch is completely consumed.
+// Checkpoint is saved before the next batch is fetched and at the end of iteration,
+// so records of the last batch may be iterated again when the iteration is stopped in the middle of batch.
+func Checkpoints(store CheckpointStore, name string) IteratorOption {
+	return checkpoints{store: store, name: name}
+}
+
+type iteratePreload []string
+
+func (ip iteratePreload) apply(i *iterator) {
+	i.preloads = append(i.preloads, ip...)
+}
+
+// String representation.
+func (ip iteratePreload) String() string {
+	return fmt.Sprintf("rel.IteratePreload(\"%s\")", strings.Join(ip, "\", \""))
+}
+
+// IteratePreload loads associations of every batch using one query for each association,
+// the whole batch is buffered before the records are returned by Next.
+// Nested association can be preloaded using dot separated field, eg: items.product.
+func IteratePreload(fields ...string) IteratorOption {
+	return iteratePreload(fields)
+}
+
+type mapPrimary []string
+
+func (mp mapPrimary) apply(i *iterator) {
+	i.mapPrimary = mp
+}
+
+// String representation.
+func (mp mapPrimary) String() string {
+	return fmt.Sprintf("rel.MapPrimary(\"%s\")", strings.Join(mp, "\", \""))
+}
+
+// MapPrimary specifies the primary fields of the table when iterating into map. Defaults to id.
+func MapPrimary(fields ...string) IteratorOption {
+	return mapPrimary(fields)
+}
+
 type iterator struct {
-	ctx       context.Context
-	start     []interface{}
-	finish    []interface{}
-	batchSize int
-	current   int
-	query     Query
-	adapter   Adapter
-	cursor    Cursor
-	fields    []string
-	closed    bool
+	ctx         context.Context
+	start       []interface{}
+	finish      []interface{}
+	batchSize   int
+	current     int
+	query       Query
+	adapter     Adapter
+	cursor      Cursor
+	fields      []string
+	closed      bool
+	sorts       []SortQuery
+	keyset      paginator
+	keys        []SortQuery
+	last        []interface{}
+	offset      bool
+	partitioned bool
+	checkpoint  string
+	store       CheckpointStore
+	name        string
+	preloads    []string
+	mapPrimary  []string
+	preload     func(cw contextWrapper, records slice, field string, queriers []Querier) error
+	buffer      *Collection
+	index       int
 }
 
 func (i *iterator) Close() error {
@@ -94,29 +341,153 @@ func (i *iterator) Next(record interface{}) error {
 		}
 	}
 
+	if i.buffer != nil {
+		return i.nextBuffered(record)
+	}
+
 	if !i.cursor.Next() {
+		if err := i.save(i.ctx); err != nil {
+			return err
+		}
+
 		return io.EOF
 	}
 
+	if m, ok := record.(*Map); ok {
+		*m = make(Map, len(i.fields))
+		i.current++
+		if err := i.cursor.Scan(m.scanners(i.fields)...); err != nil {
+			return err
+		}
+
+		return i.remember(func(field string) (interface{}, bool) {
+			value, ok := (*m)[field]
+			return value, ok
+		})
+	}
+
 	var (
 		doc      = NewDocument(record)
 		scanners = doc.Scanners(i.fields)
 	)
 
 	i.current++
-	return i.cursor.Scan(scanners...)
+	if err := i.cursor.Scan(scanners...); err != nil {
+		return err
+	}
+
+	if err := i.remember(doc.Value); err != nil {
+		return err
+	}
+
+	return afterFind(i.ctx, doc)
+}
+
+// nextBuffered returns record from the buffered batch.
+func (i *iterator) nextBuffered(record interface{}) error {
+	if i.index >= i.buffer.Len() {
+		if err := i.save(i.ctx); err != nil {
+			return err
+		}
+
+		return io.EOF
+	}
+
+	doc := i.buffer.Get(i.index)
+	reflect.ValueOf(record).Elem().Set(doc.ReflectValue())
+
+	i.index++
+	i.current++
+	return i.remember(doc.Value)
+}
+
+func (i *iterator) Checkpoint() (string, error) {
+	if i.last == nil {
+		return i.checkpoint, nil
+	}
+
+	return i.keyset.encodeValues(i.keys, i.last, false)
+}
+
+// save checkpoint to the store, it's called when all records fetched so far are consumed.
+func (i *iterator) save(ctx context.Context) error {
+	if i.store == nil || i.last == nil {
+		return nil
+	}
+
+	checkpoint, err := i.Checkpoint()
+	if err != nil {
+		return err
+	}
+
+	return i.store.Save(ctx, i.name, checkpoint)
+}
+
+// remember sort values of the last record, used to fetch the next batch and to create checkpoint.
+func (i *iterator) remember(value func(field string) (interface{}, bool)) error {
+	if i.offset {
+		return nil
+	}
+
+	if i.last == nil {
+		i.last = make([]interface{}, len(i.keys))
+	}
+
+	for j, key := range i.keys {
+		v, ok := value(key.Field)
+		if !ok {
+			v, ok = value(unqualified(key.Field))
+		}
+
+		if !ok {
+			return errors.New("rel: cannot iterate using field (" + key.Field + ") that doesn't exist in the record")
+		}
+
+		i.last[j] = v
+	}
+
+	return nil
+}
+
+// useKeyset checks whether every sort key is returned by the query, iteration without sort, checkpoint and partition
+// falls back to offset when the primary fields are not selected.
+func (i *iterator) useKeyset(fields []string) error {
+	for _, key := range i.keys {
+		if !hasField(fields, key.Field) && !hasField(fields, unqualified(key.Field)) {
+			if len(i.sorts) == 0 && i.checkpoint == "" && i.store == nil && !i.partitioned {
+				i.offset = true
+				return nil
+			}
+
+			return errors.New("rel: cannot iterate using field (" + key.Field + ") that doesn't exist in the record")
+		}
+	}
+
+	return nil
 }
 
 func (i *iterator) fetch(ctx context.Context, record interface{}) error {
 	if i.current == 0 {
-		i.init(record)
+		if err := i.init(ctx, record); err != nil {
+			return err
+		}
 	} else {
 		i.cursor.Close()
+
+		if err := i.save(ctx); err != nil {
+			return err
+		}
 	}
 
-	i.query = i.query.Limit(i.batchSize).Offset(i.current)
+	query := i.query.Limit(i.batchSize)
+	if i.offset {
+		query = query.Offset(i.current)
+	} else if i.last != nil {
+		filter, _ := i.keyset.filter(i.keys, i.last)
+		query = whereCombined(query, filter)
+	}
 
-	cursor, err := i.adapter.Query(ctx, i.query)
+	cursor, err := i.adapter.Query(ctx, query)
 	if err != nil {
 		return err
 	}
@@ -129,30 +500,172 @@ func (i *iterator) fetch(ctx context.Context, record interface{}) error {
 	i.cursor = cursor
 	i.fields = fields
 
+	if i.current == 0 {
+		if err := i.useKeyset(fields); err != nil {
+			return err
+		}
+	}
+
+	if len(i.preloads) > 0 {
+		return i.fill(ctx, record)
+	}
+
 	return nil
 }
 
-func (i *iterator) init(record interface{}) {
+// fill buffer with records of current batch and preload its associations.
+func (i *iterator) fill(ctx context.Context, record interface{}) error {
+	if _, ok := record.(*Map); ok {
+		panic("rel: cannot preload association when iterating into map")
+	}
+
 	var (
-		doc = NewDocument(record)
+		rt  = reflect.TypeOf(record).Elem()
+		col = NewCollection(reflect.New(reflect.SliceOf(rt)).Interface())
+		cw  = contextWrapper{ctx: ctx, adapter: i.adapter}
 	)
 
-	if i.query.Table == "" {
-		i.query.Table = doc.Table()
+	for i.cursor.Next() {
+		if err := i.cursor.Scan(col.Add().Scanners(i.fields)...); err != nil {
+			return err
+		}
+	}
+
+	if col.Len() > 0 {
+		for _, field := range i.preloads {
+			if err := i.preload(cw, col, field, nil); err != nil {
+				return err
+			}
+		}
+
+		if err := afterFind(ctx, col); err != nil {
+			return err
+		}
 	}
 
+	i.buffer = col
+	i.index = 0
+	return nil
+}
+
+func (i *iterator) init(ctx context.Context, record interface{}) error {
+	var (
+		primaryFields []string
+	)
+
+	if _, ok := record.(*Map); ok {
+		if i.query.Table == "" {
+			panic("rel: table is required to iterate into map, use rel.From to specify it")
+		}
+
+		primaryFields = i.mapPrimary
+		if len(primaryFields) == 0 {
+			primaryFields = []string{"id"}
+		}
+	} else {
+		doc := NewDocument(record)
+		if i.query.Table == "" {
+			i.query.Table = doc.Table()
+		}
+
+		primaryFields = doc.PrimaryFields()
+	}
+
+	query := i.query
+	query.SortQuery = i.sorts
+	i.keys = i.keyset.keys(query, primaryFields)
+
 	if len(i.start) > 0 {
-		i.query = i.query.Where(filterDocumentPrimary(doc.PrimaryFields(), i.start, FilterGteOp))
+		i.query = whereCombined(i.query, filterBound(i.keys, i.start, true))
 	}
 
 	if len(i.finish) > 0 {
-		i.query = i.query.Where(filterDocumentPrimary(doc.PrimaryFields(), i.finish, FilterLteOp))
+		i.query = whereCombined(i.query, filterBound(i.keys, i.finish, false))
+	}
+
+	if i.checkpoint == "" && i.store != nil {
+		checkpoint, err := i.store.Load(ctx, i.name)
+		if err != nil {
+			return err
+		}
+
+		i.checkpoint = checkpoint
+	}
+
+	if i.checkpoint != "" {
+		i.keyset.token = i.checkpoint
+		values, err := i.keyset.decode(i.keys)
+		if err != nil {
+			return ErrInvalidCheckpoint
+		}
+
+		filter, ok := i.keyset.filter(i.keys, values)
+		if !ok {
+			return ErrInvalidCheckpoint
+		}
+
+		i.query = whereCombined(i.query, filter)
+	}
+
+	i.query.SortQuery = i.keys
+	return nil
+}
+
+// filterBound returns filter of records that come after start or before finish in the order of keys (inclusive).
+// The values are compared lexicographically, so only the last given value is compared inclusively.
+func filterBound(keys []SortQuery, values []interface{}, start bool) FilterQuery {
+	var (
+		equals []FilterQuery
+		inner  []FilterQuery
+	)
+
+	if len(values) > len(keys) {
+		values = values[:len(keys)]
+	}
+
+	for j, value := range values {
+		var (
+			after = keys[j].Asc() == start
+			last  = j == len(values)-1
+			next  FilterQuery
+		)
+
+		switch {
+		case after && last:
+			next = Gte(keys[j].Field, value)
+		case after:
+			next = Gt(keys[j].Field, value)
+		case last:
+			next = Lte(keys[j].Field, value)
+		default:
+			next = Lt(keys[j].Field, value)
+		}
+
+		inner = append(inner, And(append(append([]FilterQuery(nil), equals...), next)...))
+		equals = append(equals, Eq(keys[j].Field, value))
+	}
+
+	return Or(inner...)
+}
+
+// whereCombined applies filter to the query and every combined queries.
+func whereCombined(query Query, filter FilterQuery) Query {
+	query = query.Where(filter)
+	if len(query.CombineQuery) == 0 {
+		return query
+	}
+
+	combines := make([]CombineQuery, len(query.CombineQuery))
+	for j, cq := range query.CombineQuery {
+		cq.Query = cq.Query.Where(filter)
+		combines[j] = cq
 	}
 
-	i.query = i.query.SortAsc(doc.PrimaryFields()...)
+	query.CombineQuery = combines
+	return query
 }
 
-func newIterator(ctx context.Context, adapter Adapter, query Query, options []IteratorOption) Iterator {
+func newIterator(ctx context.Context, adapter Adapter, query Query, options []IteratorOption) *iterator {
 	it := &iterator{
 		ctx:       ctx,
 		batchSize: 1000,
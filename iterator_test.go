@@ -24,8 +24,8 @@ func TestIterator(t *testing.T) {
 
 	query = query.From("users").SortAsc("id").Limit(5)
 	adapter.On("Query", query).Return(cur1, nil).Once()
-	adapter.On("Query", query.Offset(5)).Return(cur2, nil).Once()
-	adapter.On("Query", query.Offset(10)).Return(cur3, nil).Once()
+	adapter.On("Query", query.Where(Gt("id", 10))).Return(cur2, nil).Once()
+	adapter.On("Query", query.Where(Gt("id", 10))).Return(cur3, nil).Once()
 
 	recordsCount := 0
 	for {
@@ -53,6 +53,150 @@ func TestIterator(t *testing.T) {
 	cur3.AssertExpectations(t)
 }
 
+func TestIterator_map(t *testing.T) {
+	var (
+		records []Map
+		adapter = &testAdapter{}
+		query   = From("users")
+		cur     = createCursor(2)
+		it      = newIterator(context.TODO(), adapter, query, []IteratorOption{Start(1)})
+	)
+
+	adapter.On("Query", query.Where(Gte("id", 1)).SortAsc("id").Limit(1000)).Return(cur, nil).Once()
+
+	for {
+		var record Map
+		if err := it.Next(&record); err == io.EOF {
+			break
+		} else {
+			assert.Nil(t, err)
+		}
+
+		records = append(records, record)
+	}
+	it.Close()
+
+	assert.Equal(t, []Map{{"id": 10}, {"id": 10}}, records)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestIterator_mapPrimary(t *testing.T) {
+	var (
+		record  Map
+		adapter = &testAdapter{}
+		query   = From("countries")
+		cur1    = &testCursor{}
+		cur2    = createCursor(0)
+		options = []IteratorOption{BatchSize(1), SortBy(SortAsc("name")), MapPrimary("code")}
+		it      = newIterator(context.TODO(), adapter, query, options)
+	)
+
+	query = query.SortAsc("name").SortAsc("code").Limit(1)
+	adapter.On("Query", query).Return(cur1, nil).Once()
+	adapter.On("Query", query.Where(Or(Gt("name", "Indonesia"), And(Eq("name", "Indonesia"), Gt("code", "ID"))))).Return(cur2, nil).Once()
+
+	cur1.On("Fields").Return([]string{"code", "name"}, nil).Once()
+	cur1.On("Next").Return(true).Once()
+	cur1.MockScan("ID", "Indonesia").Once()
+	cur1.On("Close").Return(nil).Once()
+
+	assert.Nil(t, it.Next(&record))
+	assert.Equal(t, Map{"code": "ID", "name": "Indonesia"}, record)
+	assert.Equal(t, io.EOF, it.Next(&record))
+	it.Close()
+
+	adapter.AssertExpectations(t)
+	cur1.AssertExpectations(t)
+	cur2.AssertExpectations(t)
+}
+
+func TestIterator_mapWithoutPrimary(t *testing.T) {
+	var (
+		record  Map
+		adapter = &testAdapter{}
+		query   = From("users").Select("name")
+		cur1    = &testCursor{}
+		cur2    = &testCursor{}
+		it      = newIterator(context.TODO(), adapter, query, []IteratorOption{BatchSize(1)})
+	)
+
+	adapter.On("Query", query.SortAsc("id").Limit(1)).Return(cur1, nil).Once()
+	adapter.On("Query", query.SortAsc("id").Limit(1).Offset(1)).Return(cur2, nil).Once()
+
+	cur1.On("Fields").Return([]string{"name"}, nil).Once()
+	cur1.On("Next").Return(true).Once()
+	cur1.MockScan("Luffy").Once()
+	cur1.On("Close").Return(nil).Once()
+
+	cur2.On("Fields").Return([]string{"name"}, nil).Once()
+	cur2.On("Next").Return(false).Once()
+	cur2.On("Close").Return(nil).Once()
+
+	assert.Nil(t, it.Next(&record))
+	assert.Equal(t, Map{"name": "Luffy"}, record)
+	assert.Equal(t, io.EOF, it.Next(&record))
+	it.Close()
+
+	adapter.AssertExpectations(t)
+	cur1.AssertExpectations(t)
+	cur2.AssertExpectations(t)
+}
+
+func TestIterator_resumeWithoutPrimary(t *testing.T) {
+	var (
+		record  Map
+		adapter = &testAdapter{}
+		query   = From("users").Select("name")
+		cur     = &testCursor{}
+		it      = newIterator(context.TODO(), adapter, query, []IteratorOption{Checkpoints(NewMemoryCheckpointStore(), "users")})
+	)
+
+	adapter.On("Query", query.SortAsc("id").Limit(1000)).Return(cur, nil).Once()
+
+	cur.On("Fields").Return([]string{"name"}, nil).Once()
+	cur.On("Close").Return(nil).Once()
+
+	assert.Equal(t, errors.New("rel: cannot iterate using field (id) that doesn't exist in the record"), it.Next(&record))
+	it.Close()
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestIterator_partitionWithoutPrimary(t *testing.T) {
+	var (
+		user    User
+		adapter = &testAdapter{}
+		query   = From("users").Select("name")
+		cur     = &testCursor{}
+		it      = newIterator(context.TODO(), adapter, query, []IteratorOption{partition{start: 1, finish: 3}})
+	)
+
+	adapter.On("Query", query.Where(Gte("id", 1), Lte("id", 3)).SortAsc("id").Limit(1000)).Return(cur, nil).Once()
+
+	cur.On("Fields").Return([]string{"name"}, nil).Once()
+	cur.On("Close").Return(nil).Once()
+
+	assert.Equal(t, errors.New("rel: cannot iterate using field (id) that doesn't exist in the record"), it.Next(&user))
+	it.Close()
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestIterator_mapWithoutTable(t *testing.T) {
+	var (
+		record Map
+		it     = newIterator(context.TODO(), &testAdapter{}, Query{}, nil)
+	)
+
+	assert.PanicsWithValue(t, "rel: table is required to iterate into map, use rel.From to specify it", func() {
+		it.Next(&record)
+	})
+}
+
 func TestIterator_setTableName(t *testing.T) {
 	var (
 		user    User
@@ -106,6 +250,246 @@ func TestIterator_setStartAndFinishID(t *testing.T) {
 	cur.AssertExpectations(t)
 }
 
+func TestIterator_combinedQueryStartAndFinishID(t *testing.T) {
+	var (
+		user    User
+		adapter = &testAdapter{}
+		query   = From("users").Union(From("admins"))
+		cur     = createCursor(1)
+		options = []IteratorOption{Start(10), Finish(20)}
+		it      = newIterator(context.TODO(), adapter, query, options)
+	)
+
+	adapter.On("Query", From("users").
+		Where(Gte("id", 10).AndLte("id", 20)).
+		Union(From("admins").Where(Gte("id", 10).AndLte("id", 20))).
+		SortAsc("id").Limit(1000)).Return(cur, nil).Once()
+
+	for {
+		if err := it.Next(&user); err == io.EOF {
+			break
+		} else {
+			assert.Nil(t, err)
+		}
+	}
+	it.Close()
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestIterator_sortBy(t *testing.T) {
+	var (
+		user    User
+		adapter = &testAdapter{}
+		query   = From("users")
+		cur1    = createCursor(2)
+		cur2    = createCursor(1)
+		options = []IteratorOption{SortBy(SortDesc("id")), BatchSize(2)}
+		it      = newIterator(context.TODO(), adapter, query, options)
+	)
+
+	query = query.SortDesc("id").Limit(2)
+	adapter.On("Query", query).Return(cur1, nil).Once()
+	adapter.On("Query", query.Where(Lt("id", 10))).Return(cur2, nil).Once()
+
+	recordsCount := 0
+	for {
+		if err := it.Next(&user); err == io.EOF {
+			break
+		} else {
+			assert.Nil(t, err)
+		}
+
+		recordsCount++
+	}
+	it.Close()
+
+	assert.Equal(t, 3, recordsCount)
+
+	cur1.Next()
+
+	adapter.AssertExpectations(t)
+	cur1.AssertExpectations(t)
+	cur2.AssertExpectations(t)
+}
+
+func TestIterator_sortByWithStartAndFinish(t *testing.T) {
+	var (
+		user    User
+		adapter = &testAdapter{}
+		query   = From("users")
+		cur1    = &testCursor{}
+		cur2    = createCursor(0)
+		options = []IteratorOption{SortBy(SortDesc("age")), Start(30, 5), Finish(20), BatchSize(2), NullsLast(true)}
+		it      = newIterator(context.TODO(), adapter, query, options)
+	)
+
+	query = query.Where(Or(Lt("age", 30), And(Eq("age", 30), Gte("id", 5))), Gte("age", 20)).SortDesc("age").SortAsc("id").Limit(2)
+	adapter.On("Query", query).Return(cur1, nil).Once()
+	adapter.On("Query", query.Where(Or(Lt("age", 25), And(Eq("age", 25), Gt("id", 10))))).Return(cur2, nil).Once()
+
+	cur1.On("Fields").Return([]string{"id", "age"}, nil).Once()
+	cur1.On("Next").Return(true).Twice()
+	cur1.MockScan(10, 25).Twice()
+	cur1.On("Next").Return(false).Once()
+	cur1.On("Close").Return(nil).Once()
+
+	recordsCount := 0
+	for {
+		if err := it.Next(&user); err == io.EOF {
+			break
+		} else {
+			assert.Nil(t, err)
+		}
+
+		recordsCount++
+	}
+	it.Close()
+
+	assert.Equal(t, 2, recordsCount)
+
+	cur1.Next()
+
+	adapter.AssertExpectations(t)
+	cur1.AssertExpectations(t)
+	cur2.AssertExpectations(t)
+}
+
+func TestIterator_sortByUnknownField(t *testing.T) {
+	var (
+		user    User
+		adapter = &testAdapter{}
+		query   = From("users")
+		cur     = createCursor(1)
+		it      = newIterator(context.TODO(), adapter, query, []IteratorOption{SortBy(SortAsc("unknown"))})
+	)
+
+	adapter.On("Query", query.SortAsc("unknown").SortAsc("id").Limit(1000)).Return(cur, nil).Once()
+
+	assert.Equal(t, errors.New("rel: cannot iterate using field (unknown) that doesn't exist in the record"), it.Next(&user))
+	it.Close()
+
+	adapter.AssertExpectations(t)
+}
+
+func TestIterator_checkpoint(t *testing.T) {
+	var (
+		user    User
+		adapter = &testAdapter{}
+		query   = From("users")
+		cur1    = createCursor(1)
+		cur2    = createCursor(0)
+		it      = newIterator(context.TODO(), adapter, query, nil)
+	)
+
+	adapter.On("Query", query.SortAsc("id").Limit(1000)).Return(cur1, nil).Once()
+	adapter.On("Query", query.Where(Gt("id", int64(10))).SortAsc("id").Limit(1000)).Return(cur2, nil).Once()
+
+	checkpoint, err := it.Checkpoint()
+	assert.Nil(t, err)
+	assert.Equal(t, "", checkpoint)
+
+	assert.Nil(t, it.Next(&user))
+	assert.Equal(t, io.EOF, it.Next(&user))
+	it.Close()
+
+	checkpoint, err = it.Checkpoint()
+	assert.Nil(t, err)
+	assert.NotEqual(t, "", checkpoint)
+
+	it = newIterator(context.TODO(), adapter, query, []IteratorOption{Resume(checkpoint)})
+	assert.Equal(t, io.EOF, it.Next(&user))
+	it.Close()
+
+	resumed, err := it.Checkpoint()
+	assert.Nil(t, err)
+	assert.Equal(t, checkpoint, resumed)
+
+	adapter.AssertExpectations(t)
+	cur1.AssertExpectations(t)
+	cur2.AssertExpectations(t)
+}
+
+func TestIterator_resumeInvalidCheckpoint(t *testing.T) {
+	var (
+		user    User
+		adapter = &testAdapter{}
+		query   = From("users")
+		cur     = createCursor(1)
+		it      = newIterator(context.TODO(), adapter, query, nil)
+	)
+
+	adapter.On("Query", query.SortAsc("id").Limit(1000)).Return(cur, nil).Once()
+
+	assert.Nil(t, it.Next(&user))
+	checkpoint, _ := it.Checkpoint()
+	it.Close()
+
+	tests := []struct {
+		name    string
+		options []IteratorOption
+	}{
+		{name: "malformed", options: []IteratorOption{Resume("checkpoint")}},
+		{name: "tampered", options: []IteratorOption{Resume("x" + checkpoint)}},
+		{name: "different sort", options: []IteratorOption{Resume(checkpoint), SortBy(SortDesc("id"))}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			it := newIterator(context.TODO(), adapter, query, test.options)
+			assert.Equal(t, ErrInvalidCheckpoint, it.Next(&user))
+		})
+	}
+
+	adapter.AssertExpectations(t)
+}
+
+func TestIterator_checkpoints(t *testing.T) {
+	var (
+		user    User
+		ctx     = context.TODO()
+		store   = NewMemoryCheckpointStore()
+		adapter = &testAdapter{}
+		query   = From("users")
+		cur1    = createCursor(2)
+		cur2    = createCursor(1)
+		cur3    = createCursor(0)
+		options = []IteratorOption{BatchSize(2), Checkpoints(store, "users")}
+	)
+
+	adapter.On("Query", query.SortAsc("id").Limit(2)).Return(cur1, nil).Once()
+	adapter.On("Query", query.Where(Gt("id", 10)).SortAsc("id").Limit(2)).Return(cur2, nil).Once()
+
+	it := newIterator(ctx, adapter, query, options)
+	assert.Nil(t, it.Next(&user))
+	assert.Nil(t, it.Next(&user))
+
+	checkpoint, _ := store.Load(ctx, "users")
+	assert.Equal(t, "", checkpoint)
+
+	// checkpoint is saved when the next batch is fetched.
+	assert.Nil(t, it.Next(&user))
+
+	checkpoint, _ = store.Load(ctx, "users")
+	assert.NotEqual(t, "", checkpoint)
+	it.Close()
+
+	adapter.On("Query", query.Where(Gt("id", int64(10))).SortAsc("id").Limit(2)).Return(cur3, nil).Once()
+
+	it = newIterator(ctx, adapter, query, options)
+	assert.Equal(t, io.EOF, it.Next(&user))
+	it.Close()
+
+	cur1.Next()
+	cur2.Next()
+
+	adapter.AssertExpectations(t)
+	cur1.AssertExpectations(t)
+	cur2.AssertExpectations(t)
+	cur3.AssertExpectations(t)
+}
+
 func TestIterator_cursorFieldsError(t *testing.T) {
 	var (
 		user    User
@@ -162,6 +546,26 @@ func TestIterator_queryError(t *testing.T) {
 	adapter.AssertExpectations(t)
 }
 
+func TestPartitions(t *testing.T) {
+	tests := []struct {
+		min    int
+		max    int
+		n      int
+		result []partition
+	}{
+		{min: 1, max: 10, n: 1, result: []partition{{1, 10}}},
+		{min: 1, max: 10, n: 3, result: []partition{{1, 4}, {5, 7}, {8, 10}}},
+		{min: 5, max: 6, n: 4, result: []partition{{5, 5}, {6, 6}}},
+		{min: 3, max: 3, n: 2, result: []partition{{3, 3}}},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprint(test.min, test.max, test.n), func(t *testing.T) {
+			assert.Equal(t, test.result, partitions(test.min, test.max, test.n))
+		})
+	}
+}
+
 func TestIteratorOption_String(t *testing.T) {
 	assert.Equal(t, "rel.BatchSize(10)", fmt.Sprint(BatchSize(10)))
 	assert.Equal(t, "rel.Start(20)", fmt.Sprint(Start(20)))
@@ -170,4 +574,10 @@ func TestIteratorOption_String(t *testing.T) {
 	assert.Equal(t, "rel.Finish(30)", fmt.Sprint(Finish(30)))
 	assert.Equal(t, "rel.Finish(30, 31)", fmt.Sprint(Finish(30, 31)))
 	assert.Equal(t, "rel.Finish(\"def\")", fmt.Sprint(Finish("def")))
+	assert.Equal(t, "rel.SortBy(rel.SortDesc(\"created_at\"), rel.SortAsc(\"id\"))", fmt.Sprint(SortBy(SortDesc("created_at"), SortAsc("id"))))
+	assert.Equal(t, "rel.NullsLast(true)", fmt.Sprint(NullsLast(true)))
+	assert.Equal(t, "rel.Resume(\"abc\")", fmt.Sprint(Resume("abc")))
+	assert.Equal(t, "rel.MapPrimary(\"code\", \"id\")", fmt.Sprint(MapPrimary("code", "id")))
+	assert.Equal(t, "rel.IteratePreload(\"items\", \"customer\")", fmt.Sprint(IteratePreload("items", "customer")))
+	assert.Equal(t, "rel.Checkpoints(\"users\")", fmt.Sprint(Checkpoints(NewMemoryCheckpointStore(), "users")))
 }
@@ -0,0 +1,188 @@
+package rel
+
+import (
+	"strings"
+)
+
+// Lock query.
+// This query will be ignored if used outside of transaction.
+type Lock string
+
+// Build query.
+func (l Lock) Build(query *Query) {
+	query.LockQuery = l
+	query.RowLockQuery = parseLock(string(l))
+}
+
+// LockStrength defines the strength of row lock.
+type LockStrength string
+
+const (
+	// LockUpdate locks rows as if they are going to be updated, it blocks all other row locks.
+	LockUpdate LockStrength = "UPDATE"
+	// LockNoKeyUpdate is weaker than LockUpdate, it doesn't block LockKeyShare.
+	LockNoKeyUpdate LockStrength = "NO KEY UPDATE"
+	// LockShare locks rows for reading, it only blocks LockUpdate and LockNoKeyUpdate.
+	LockShare LockStrength = "SHARE"
+	// LockKeyShare is weaker than LockShare, it only blocks LockUpdate.
+	LockKeyShare LockStrength = "KEY SHARE"
+)
+
+// LockWait defines what to do when the row is already locked by other transaction.
+type LockWait string
+
+const (
+	// LockWaitDefault waits until conflicting lock is released.
+	LockWaitDefault LockWait = ""
+	// LockNoWait fails immediately with ErrLockNotAvailable.
+	LockNoWait LockWait = "NOWAIT"
+	// LockSkipLocked skips rows that can't be locked immediately.
+	LockSkipLocked LockWait = "SKIP LOCKED"
+)
+
+// RowLock defines structured row locking clause.
+// Tables limits locking to the given tables or aliases, all tables in the query are locked when it's empty.
+// This query will be ignored if used outside of transaction.
+type RowLock struct {
+	Strength LockStrength
+	Wait     LockWait
+	Tables   []string
+}
+
+// Build query.
+func (rl RowLock) Build(query *Query) {
+	query.LockQuery = rl.Lock()
+	query.RowLockQuery = rl.clone()
+}
+
+// NoWait fails immediately when the row is already locked.
+func (rl RowLock) NoWait() RowLock {
+	rl.Wait = LockNoWait
+	return rl
+}
+
+// SkipLocked skips rows that are already locked.
+func (rl RowLock) SkipLocked() RowLock {
+	rl.Wait = LockSkipLocked
+	return rl
+}
+
+// Of limits locking to the given tables.
+func (rl RowLock) Of(tables ...string) RowLock {
+	rl.Tables = append(append([]string(nil), rl.Tables...), tables...)
+	return rl
+}
+
+// Lock returns the locking clause.
+func (rl RowLock) Lock() Lock {
+	var builder strings.Builder
+	builder.WriteString("FOR ")
+	builder.WriteString(string(rl.Strength))
+
+	if len(rl.Tables) > 0 {
+		builder.WriteString(" OF ")
+		builder.WriteString(strings.Join(rl.Tables, ", "))
+	}
+
+	if rl.Wait != LockWaitDefault {
+		builder.WriteByte(' ')
+		builder.WriteString(string(rl.Wait))
+	}
+
+	return Lock(builder.String())
+}
+
+// clone returns a copy of row lock that doesn't share tables with the original.
+func (rl RowLock) clone() *RowLock {
+	rl.Tables = append([]string(nil), rl.Tables...)
+	return &rl
+}
+
+// ForUpdate lock query.
+func ForUpdate() Lock {
+	return "FOR UPDATE"
+}
+
+// RowLockForUpdate structured lock query.
+func RowLockForUpdate() RowLock {
+	return RowLock{Strength: LockUpdate}
+}
+
+// RowLockForNoKeyUpdate structured lock query.
+func RowLockForNoKeyUpdate() RowLock {
+	return RowLock{Strength: LockNoKeyUpdate}
+}
+
+// RowLockForShare structured lock query.
+func RowLockForShare() RowLock {
+	return RowLock{Strength: LockShare}
+}
+
+// RowLockForKeyShare structured lock query.
+func RowLockForKeyShare() RowLock {
+	return RowLock{Strength: LockKeyShare}
+}
+
+// parseLock parses locking clause in the form of `FOR strength [OF table, ...] [NOWAIT | SKIP LOCKED]`,
+// nil is returned when the clause is not in that form.
+func parseLock(lock string) *RowLock {
+	var (
+		rl     RowLock
+		tokens = strings.Fields(strings.ReplaceAll(lock, ",", " "))
+		next   = func(words ...string) bool {
+			if len(tokens) < len(words) {
+				return false
+			}
+
+			for i := range words {
+				if !strings.EqualFold(tokens[i], words[i]) {
+					return false
+				}
+			}
+
+			tokens = tokens[len(words):]
+			return true
+		}
+	)
+
+	if !next("FOR") {
+		return nil
+	}
+
+	switch {
+	case next("UPDATE"):
+		rl.Strength = LockUpdate
+	case next("NO", "KEY", "UPDATE"):
+		rl.Strength = LockNoKeyUpdate
+	case next("SHARE"):
+		rl.Strength = LockShare
+	case next("KEY", "SHARE"):
+		rl.Strength = LockKeyShare
+	default:
+		return nil
+	}
+
+	if next("OF") {
+		for len(tokens) > 0 && !strings.EqualFold(tokens[0], "NOWAIT") && !strings.EqualFold(tokens[0], "SKIP") {
+			rl.Tables = append(rl.Tables, tokens[0])
+			tokens = tokens[1:]
+		}
+
+		if len(rl.Tables) == 0 {
+			return nil
+		}
+	}
+
+	switch {
+	case next("NOWAIT"):
+		rl.Wait = LockNoWait
+	case next("SKIP", "LOCKED"):
+		rl.Wait = LockSkipLocked
+	}
+
+	if len(tokens) > 0 {
+		return nil
+	}
+
+	return &rl
+}
@@ -0,0 +1,59 @@
+package rel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRowLock_Lock(t *testing.T) {
+	tests := []struct {
+		lock     RowLock
+		expected Lock
+	}{
+		{RowLockForUpdate(), "FOR UPDATE"},
+		{RowLockForNoKeyUpdate().NoWait(), "FOR NO KEY UPDATE NOWAIT"},
+		{RowLockForShare().Of("users", "addresses"), "FOR SHARE OF users, addresses"},
+		{RowLockForKeyShare().Of("users").SkipLocked(), "FOR KEY SHARE OF users SKIP LOCKED"},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.expected), func(t *testing.T) {
+			assert.Equal(t, test.expected, test.lock.Lock())
+			assert.Equal(t, &test.lock, parseLock(string(test.expected)))
+		})
+	}
+}
+
+func TestForUpdate(t *testing.T) {
+	var lock Lock = ForUpdate()
+
+	assert.Equal(t, "FOR UPDATE", string(lock))
+	assert.Equal(t, &RowLock{Strength: LockUpdate}, Build("", lock).RowLockQuery)
+}
+
+func TestRowLock_Of(t *testing.T) {
+	var (
+		base   = RowLockForUpdate().Of("users")
+		first  = base.Of("addresses")
+		second = base.Of("transactions")
+		query  = Build("", first)
+		copied = Build("", query)
+	)
+
+	assert.Equal(t, []string{"users"}, base.Tables)
+	assert.Equal(t, []string{"users", "addresses"}, first.Tables)
+	assert.Equal(t, []string{"users", "transactions"}, second.Tables)
+
+	query.RowLockQuery.Tables[0] = "changed"
+	assert.Equal(t, []string{"users", "addresses"}, first.Tables)
+	assert.Equal(t, []string{"users", "addresses"}, copied.RowLockQuery.Tables)
+}
+
+func TestParseLock(t *testing.T) {
+	assert.Equal(t, &RowLock{Strength: LockShare, Wait: LockNoWait, Tables: []string{"users", "u"}}, parseLock("for share of users,u nowait"))
+	assert.Nil(t, parseLock("LOCK IN SHARE MODE"))
+	assert.Nil(t, parseLock("FOR"))
+	assert.Nil(t, parseLock("FOR UPDATE OF NOWAIT"))
+	assert.Nil(t, parseLock("FOR UPDATE WAIT 5"))
+}
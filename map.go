@@ -10,6 +10,7 @@ import (
 // Insert/Update of has one or belongs to can be done using other Map as a value.
 // Insert/Update of has many can be done using slice of Map as a value.
 // Map is intended to be used internally within application, and not to be exposed directly as an APIs.
+// Map can also be used as a destination of Find, FindAll and Iterate to read records without defining a struct.
 type Map map[string]interface{}
 
 // Apply mutation.
@@ -69,6 +70,34 @@ func (m Map) Apply(doc *Document, mutation *Mutation) {
 	}
 }
 
+// scanners returns slice of sql.Scanner that stores scanned value of given fields into map.
+func (m Map) scanners(fields []string) []interface{} {
+	result := make([]interface{}, len(fields))
+	for i := range fields {
+		result[i] = mapScanner{m: m, field: fields[i]}
+	}
+
+	return result
+}
+
+// mapScanner stores a column value into map, converting driver value into sensible go value.
+// Bytes is stored as string, other values including time are stored as returned by the driver.
+type mapScanner struct {
+	m     Map
+	field string
+}
+
+func (ms mapScanner) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		ms.m[ms.field] = string(v)
+	default:
+		ms.m[ms.field] = v
+	}
+
+	return nil
+}
+
 func (m Map) String() string {
 	var builder strings.Builder
 
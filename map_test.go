@@ -1,7 +1,9 @@
 package rel
 
 import (
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -262,3 +264,22 @@ func TestMap_String(t *testing.T) {
 	assert.Contains(t, data.String(), "\"transactions\": []rel.Map{rel.Map{\"item\": \"Sword\"}, rel.Map{\"item\": \"Shield\"}}")
 	assert.Contains(t, data.String(), "\"address\": rel.Map{\"street\": \"Grove Street\"}")
 }
+
+func TestMap_scanners(t *testing.T) {
+	var (
+		now      = time.Now().Truncate(time.Second).Local()
+		m        = Map{}
+		scanners = m.scanners([]string{"id", "name", "note", "created_at"})
+	)
+
+	assert.Nil(t, scanners[0].(sql.Scanner).Scan(int64(1)))
+	assert.Nil(t, scanners[1].(sql.Scanner).Scan([]byte("Luffy")))
+	assert.Nil(t, scanners[2].(sql.Scanner).Scan(nil))
+	assert.Nil(t, scanners[3].(sql.Scanner).Scan(now))
+	assert.Equal(t, Map{
+		"id":         int64(1),
+		"name":       "Luffy",
+		"note":       nil,
+		"created_at": now,
+	}, m)
+}
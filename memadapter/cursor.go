@@ -0,0 +1,53 @@
+package memadapter
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/go-rel/rel"
+)
+
+type cursor struct {
+	fields []string
+	rows   [][]interface{}
+	index  int
+}
+
+var _ rel.Cursor = (*cursor)(nil)
+
+func (c *cursor) Close() error {
+	c.rows = nil
+	return nil
+}
+
+func (c *cursor) Fields() ([]string, error) {
+	return c.fields, nil
+}
+
+func (c *cursor) Next() bool {
+	c.index++
+	return c.index < len(c.rows)
+}
+
+func (c *cursor) Scan(dest ...interface{}) error {
+	if c.index < 0 || c.index >= len(c.rows) {
+		return errors.New("memadapter: scan called without calling next")
+	}
+
+	row := c.rows[c.index]
+	if len(dest) != len(row) {
+		return errors.New("memadapter: invalid number of scan destination")
+	}
+
+	for i := range dest {
+		if err := scan(dest[i], row[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *cursor) NopScanner() interface{} {
+	return &sql.RawBytes{}
+}
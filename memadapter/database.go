@@ -0,0 +1,496 @@
+package memadapter
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-rel/rel"
+)
+
+var errTransactionDone = errors.New("memadapter: transaction has already been committed or rolled back")
+
+type column struct {
+	name          string
+	primary       bool
+	unique        bool
+	required      bool
+	autoIncrement bool
+	def           interface{}
+}
+
+type uniqueKey struct {
+	name    string
+	typ     rel.ConstraintType
+	columns []string
+	filter  rel.FilterQuery
+}
+
+type foreignKey struct {
+	name      string
+	column    string
+	refTable  string
+	refColumn string
+	onDelete  string
+}
+
+// row stores values of a record, version is changed on every write and used to detect conflicting writes.
+type row struct {
+	id      int64
+	version int64
+	values  map[string]interface{}
+}
+
+func (r row) clone() row {
+	values := make(map[string]interface{}, len(r.values))
+	for k, v := range r.values {
+		values[k] = v
+	}
+
+	return row{id: r.id, version: r.version, values: values}
+}
+
+type table struct {
+	name        string
+	columns     []column
+	uniques     []uniqueKey
+	foreignKeys []foreignKey
+	indexes     map[string]string
+	rows        []row
+}
+
+func newTable(name string) *table {
+	return &table{
+		name:    name,
+		indexes: make(map[string]string),
+	}
+}
+
+func (t *table) clone() *table {
+	clone := &table{
+		name:        t.name,
+		columns:     append([]column(nil), t.columns...),
+		uniques:     append([]uniqueKey(nil), t.uniques...),
+		foreignKeys: append([]foreignKey(nil), t.foreignKeys...),
+		indexes:     make(map[string]string, len(t.indexes)),
+		rows:        make([]row, len(t.rows)),
+	}
+
+	for k, v := range t.indexes {
+		clone.indexes[k] = v
+	}
+
+	for i := range t.rows {
+		clone.rows[i] = t.rows[i].clone()
+	}
+
+	return clone
+}
+
+// append rows of query result, it's used to build temporary table.
+func (t *table) append(fields []string, rows [][]interface{}) {
+	for _, field := range fields {
+		t.addColumn(column{name: field})
+	}
+
+	for _, values := range rows {
+		r := row{id: int64(len(t.rows) + 1), values: make(map[string]interface{}, len(fields))}
+		for i := range fields {
+			r.values[fields[i]] = values[i]
+		}
+
+		t.rows = append(t.rows, r)
+	}
+}
+
+func (t *table) columnNames() []string {
+	names := make([]string, len(t.columns))
+	for i := range t.columns {
+		names[i] = t.columns[i].name
+	}
+
+	return names
+}
+
+func (t *table) column(name string) (*column, bool) {
+	for i := range t.columns {
+		if t.columns[i].name == name {
+			return &t.columns[i], true
+		}
+	}
+
+	return nil, false
+}
+
+func (t *table) addColumn(col column) {
+	if _, exists := t.column(col.name); !exists {
+		t.columns = append(t.columns, col)
+	}
+}
+
+func (t *table) primaryFields() []string {
+	var fields []string
+	for i := range t.columns {
+		if t.columns[i].primary {
+			fields = append(fields, t.columns[i].name)
+		}
+	}
+
+	return fields
+}
+
+func (t *table) search(id int64) (int, bool) {
+	i := sort.Search(len(t.rows), func(i int) bool { return t.rows[i].id >= id })
+	return i, i < len(t.rows) && t.rows[i].id == id
+}
+
+func (t *table) get(id int64) (row, bool) {
+	if i, ok := t.search(id); ok {
+		return t.rows[i], true
+	}
+
+	return row{}, false
+}
+
+func (t *table) put(r row) {
+	i, ok := t.search(r.id)
+	if ok {
+		t.rows[i] = r
+		return
+	}
+
+	t.rows = append(t.rows, row{})
+	copy(t.rows[i+1:], t.rows[i:])
+	t.rows[i] = r
+}
+
+func (t *table) remove(id int64) {
+	if i, ok := t.search(id); ok {
+		t.rows = append(t.rows[:i], t.rows[i+1:]...)
+	}
+}
+
+// database is a single layer of data.
+// The root layer holds committed data, and every transaction works on its own layer
+// cloned from its parent, which is merged back to the parent on commit.
+// dirty stores the version of every written row before it's first written in this layer,
+// commit fails when the row in parent has been changed since then.
+type database struct {
+	mu         sync.RWMutex
+	parent     *database
+	seq        *sequence
+	locks      *lockTable
+	tables     map[string]*table
+	dirty      map[string]map[int64]int64
+	schema     map[string]struct{}
+	savepoints []savepoint
+	done       bool
+}
+
+// savepoint stores snapshot of a transaction layer.
+type savepoint struct {
+	name   string
+	tables map[string]*table
+	dirty  map[string]map[int64]int64
+	schema map[string]struct{}
+}
+
+func newDatabase() *database {
+	return &database{
+		seq:    &sequence{values: make(map[string]int64)},
+		locks:  &lockTable{rows: make(map[string]map[int64][]rowLock)},
+		tables: make(map[string]*table),
+		dirty:  make(map[string]map[int64]int64),
+		schema: make(map[string]struct{}),
+	}
+}
+
+func (db *database) begin() (*database, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.done {
+		return nil, errTransactionDone
+	}
+
+	child := &database{
+		parent: db,
+		seq:    db.seq,
+		locks:  db.locks,
+		tables: make(map[string]*table, len(db.tables)),
+		dirty:  make(map[string]map[int64]int64),
+		schema: make(map[string]struct{}),
+	}
+
+	for name, t := range db.tables {
+		child.tables[name] = t.clone()
+	}
+
+	return child, nil
+}
+
+func (db *database) commit() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.done {
+		return errTransactionDone
+	}
+
+	db.done = true
+	db.releaseLocks()
+
+	parent := db.parent
+	parent.mu.Lock()
+	defer parent.mu.Unlock()
+
+	if err := db.checkCommit(parent); err != nil {
+		return err
+	}
+
+	for name := range db.schema {
+		if t, ok := db.tables[name]; ok {
+			parent.tables[name] = t
+		} else {
+			delete(parent.tables, name)
+		}
+
+		parent.schema[name] = struct{}{}
+	}
+
+	for name, ids := range db.dirty {
+		if _, replaced := db.schema[name]; replaced {
+			continue
+		}
+
+		var (
+			source = db.tables[name]
+			target = parent.tables[name]
+		)
+
+		if source == nil || target == nil {
+			continue
+		}
+
+		for _, col := range source.columns {
+			target.addColumn(col)
+		}
+
+		for id := range ids {
+			var base int64
+			if r, ok := target.get(id); ok {
+				base = r.version
+			}
+
+			if r, ok := source.get(id); ok {
+				target.put(r)
+			} else {
+				target.remove(id)
+			}
+
+			parent.touch(name, id, base)
+		}
+	}
+
+	return nil
+}
+
+// checkCommit returns serialization failure when a row written in this layer has been changed in parent
+// after this layer is created, and constraint error when a written row violates unique key of rows in parent.
+// caller must hold the parent mutex.
+func (db *database) checkCommit(parent *database) error {
+	for name, ids := range db.dirty {
+		if _, replaced := db.schema[name]; replaced {
+			continue
+		}
+
+		var (
+			source = db.tables[name]
+			target = parent.tables[name]
+		)
+
+		if source == nil || target == nil {
+			continue
+		}
+
+		for id, base := range ids {
+			var current int64
+			if r, ok := target.get(id); ok {
+				current = r.version
+			}
+
+			if current != base {
+				return rel.ConcurrencyError{
+					Type: rel.SerializationFailure,
+					Err:  fmt.Errorf("memadapter: could not serialize access due to concurrent update on %s", name),
+				}
+			}
+		}
+
+		for id := range ids {
+			r, ok := source.get(id)
+			if !ok {
+				continue
+			}
+
+			for _, uk := range target.uniques {
+				if _, err := parent.checkUniqueKeyExcept(target, uk, r, ids); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (db *database) rollback() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.done {
+		return errTransactionDone
+	}
+
+	db.done = true
+	db.releaseLocks()
+	return nil
+}
+
+func (db *database) savepoint(name string) error {
+	if db.parent == nil {
+		return errors.New("memadapter: savepoint can only be used inside transaction")
+	}
+
+	sp := savepoint{
+		name:   name,
+		tables: make(map[string]*table, len(db.tables)),
+		dirty:  make(map[string]map[int64]int64, len(db.dirty)),
+		schema: make(map[string]struct{}, len(db.schema)),
+	}
+
+	for name, t := range db.tables {
+		sp.tables[name] = t.clone()
+	}
+
+	for name, ids := range db.dirty {
+		sp.dirty[name] = make(map[int64]int64, len(ids))
+		for id, base := range ids {
+			sp.dirty[name][id] = base
+		}
+	}
+
+	for name := range db.schema {
+		sp.schema[name] = struct{}{}
+	}
+
+	db.savepoints = append(db.savepoints, sp)
+	return nil
+}
+
+// rollbackTo restores data to the state when savepoint was created, the savepoint itself is kept.
+func (db *database) rollbackTo(name string) error {
+	i := db.findSavepoint(name)
+	if i < 0 {
+		return fmt.Errorf("memadapter: savepoint %s does not exist", name)
+	}
+
+	sp := db.savepoints[i]
+	db.savepoints = db.savepoints[:i]
+	db.tables, db.dirty, db.schema = sp.tables, sp.dirty, sp.schema
+
+	// savepoint is recreated using a copy, so it can be rolled back to again.
+	return db.savepoint(name)
+}
+
+// release removes savepoint and all savepoints created after it.
+func (db *database) release(name string) error {
+	i := db.findSavepoint(name)
+	if i < 0 {
+		return fmt.Errorf("memadapter: savepoint %s does not exist", name)
+	}
+
+	db.savepoints = db.savepoints[:i]
+	return nil
+}
+
+func (db *database) findSavepoint(name string) int {
+	for i := len(db.savepoints) - 1; i >= 0; i-- {
+		if db.savepoints[i].name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// touch marks row as written in this layer, base is the version of the row before it's written,
+// or zero when the row didn't exist.
+func (db *database) touch(table string, id int64, base int64) {
+	if db.parent == nil {
+		return
+	}
+
+	ids, ok := db.dirty[table]
+	if !ok {
+		ids = make(map[int64]int64)
+		db.dirty[table] = ids
+	}
+
+	if _, written := ids[id]; !written {
+		ids[id] = base
+	}
+}
+
+func (db *database) changeSchema(table string) {
+	if db.parent != nil {
+		db.schema[table] = struct{}{}
+	}
+}
+
+func (db *database) nextRowID() int64 {
+	return atomic.AddInt64(&db.seq.rowID, 1)
+}
+
+func (db *database) nextVersion() int64 {
+	return atomic.AddInt64(&db.seq.version, 1)
+}
+
+// sequence stores auto increment counters and row version.
+// it's shared across all layers, so concurrent transactions never generate the same value.
+type sequence struct {
+	rowID   int64
+	version int64
+	mu      sync.Mutex
+	values  map[string]int64
+}
+
+// next returns next auto increment value for given table.
+func (s *sequence) next(table string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[table]++
+	return s.values[table]
+}
+
+// observe value that is inserted explicitly, so it won't be generated later.
+func (s *sequence) observe(table string, value int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.values[table] < value {
+		s.values[table] = value
+	}
+}
+
+// rename counter when the table is renamed.
+func (s *sequence) rename(table string, newTable string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[newTable] = s.values[table]
+	delete(s.values, table)
+}
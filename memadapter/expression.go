@@ -0,0 +1,110 @@
+package memadapter
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-rel/rel"
+)
+
+var aggregates = map[string]bool{
+	"count": true,
+	"sum":   true,
+	"avg":   true,
+	"min":   true,
+	"max":   true,
+}
+
+// expression is a parsed select field or filter field.
+// supported forms are: `*`, `table.*`, `field`, `table.field`, `fn(field)`, `fn(*)`, `fn(DISTINCT field)`,
+// all of them can be aliased using `AS alias`, and optionally prefixed by `^`.
+// Raw field prefixed by `^` can also be an integer literal, eg: `^1`.
+type expression struct {
+	name     string
+	star     bool
+	table    string
+	function string
+	distinct bool
+	field    string
+	window   bool        // evaluated after all rows are projected.
+	query    *rel.Query  // scalar sub query.
+	literal  interface{} // integer literal of raw field.
+}
+
+func (e expression) aggregate() bool {
+	return aggregates[e.function]
+}
+
+func parseExpression(str string) expression {
+	var (
+		expr = expression{}
+		raw  = strings.TrimSpace(strings.TrimPrefix(str, "^"))
+	)
+
+	if i := strings.LastIndex(strings.ToLower(raw), " as "); i >= 0 {
+		expr.name = strings.TrimSpace(raw[i+4:])
+		raw = strings.TrimSpace(raw[:i])
+	}
+
+	switch {
+	case raw == "*":
+		expr.star = true
+	case strings.HasSuffix(raw, ".*"):
+		expr.star = true
+		expr.table = strings.TrimSuffix(raw, ".*")
+	case strings.HasSuffix(raw, ")") && strings.IndexByte(raw, '(') > 0:
+		var (
+			open = strings.IndexByte(raw, '(')
+			arg  = strings.TrimSpace(raw[open+1 : len(raw)-1])
+		)
+
+		expr.function = strings.ToLower(strings.TrimSpace(raw[:open]))
+		if len(arg) > 9 && strings.EqualFold(arg[:9], "distinct ") {
+			expr.distinct = true
+			arg = strings.TrimSpace(arg[9:])
+		}
+
+		expr.field = arg
+		if expr.name == "" {
+			expr.name = raw
+		}
+	case strings.HasPrefix(str, "^") && isInteger(raw):
+		expr.literal, _ = strconv.ParseInt(raw, 10, 64)
+		if expr.name == "" {
+			expr.name = raw
+		}
+	default:
+		expr.field = raw
+		if expr.name == "" {
+			if i := strings.LastIndexByte(raw, '.'); i >= 0 {
+				expr.name = raw[i+1:]
+			} else {
+				expr.name = raw
+			}
+		}
+	}
+
+	return expr
+}
+
+func isInteger(str string) bool {
+	_, err := strconv.ParseInt(str, 10, 64)
+	return err == nil
+}
+
+// parseSource parses table name with optional alias, eg: `users`, `users as u` or `users u`.
+func parseSource(str string) (string, string) {
+	var (
+		name  = strings.TrimSpace(str)
+		alias = name
+	)
+
+	if i := strings.LastIndex(strings.ToLower(name), " as "); i >= 0 {
+		alias = strings.TrimSpace(name[i+4:])
+		name = strings.TrimSpace(name[:i])
+	} else if fields := strings.Fields(name); len(fields) == 2 {
+		name, alias = fields[0], fields[1]
+	}
+
+	return name, alias
+}
@@ -0,0 +1,252 @@
+package memadapter
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/go-rel/rel"
+)
+
+// rowLock is a lock held by a transaction on a row.
+type rowLock struct {
+	owner    *database
+	strength rel.LockStrength
+}
+
+// lockTable stores row locks of all transactions, it's shared across all layers.
+// row locks only conflict with other row locks, writes never wait for them,
+// instead conflicting writes of concurrent transactions are detected on commit using row version.
+type lockTable struct {
+	mu   sync.Mutex
+	rows map[string]map[int64][]rowLock
+}
+
+// available returns false when the row is locked by other transaction using conflicting strength.
+// caller must hold the lock table mutex.
+func (lt *lockTable) available(owner *database, table string, id int64, strength rel.LockStrength) bool {
+	for _, l := range lt.rows[table][id] {
+		if l.owner != owner && conflicts(l.strength, strength) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// acquire row lock, caller must hold the lock table mutex.
+func (lt *lockTable) acquire(owner *database, table string, id int64, strength rel.LockStrength) {
+	ids, ok := lt.rows[table]
+	if !ok {
+		ids = make(map[int64][]rowLock)
+		lt.rows[table] = ids
+	}
+
+	ids[id] = append(ids[id], rowLock{owner: owner, strength: strength})
+}
+
+// release all row locks held by owner.
+func (lt *lockTable) release(owner *database) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	for table, ids := range lt.rows {
+		for id, locks := range ids {
+			held := locks[:0]
+			for _, l := range locks {
+				if l.owner != owner {
+					held = append(held, l)
+				}
+			}
+
+			if len(held) == 0 {
+				delete(ids, id)
+			} else {
+				ids[id] = held
+			}
+		}
+
+		if len(ids) == 0 {
+			delete(lt.rows, table)
+		}
+	}
+}
+
+// conflicts follows the conflict table of row-level locks in PostgreSQL.
+func conflicts(held rel.LockStrength, requested rel.LockStrength) bool {
+	switch held {
+	case rel.LockKeyShare:
+		return requested == rel.LockUpdate
+	case rel.LockShare:
+		return requested == rel.LockUpdate || requested == rel.LockNoKeyUpdate
+	case rel.LockNoKeyUpdate:
+		return requested != rel.LockKeyShare
+	default:
+		return true
+	}
+}
+
+// transaction returns the outermost transaction layer, it owns all row locks acquired inside it.
+func (db *database) transaction() *database {
+	for db.parent != nil && db.parent.parent != nil {
+		db = db.parent
+	}
+
+	return db
+}
+
+// releaseLocks held by transaction when the outermost transaction ends.
+func (db *database) releaseLocks() {
+	if db.parent != nil && db.parent.parent == nil {
+		db.locks.release(db)
+	}
+}
+
+// lock rows that will be returned by the query, sort, offset and limit are applied before locking,
+// so rows that are skipped by them are not locked.
+// memadapter never waits for conflicting lock, it fails with lock timeout error instead.
+func (e executor) lock(query rel.Query, sources []*source, envs []env) ([]env, error) {
+	if len(query.GroupQuery.Fields) > 0 {
+		return nil, errors.New("memadapter: lock is not allowed with group")
+	}
+
+	var (
+		rl      = query.RowLockQuery
+		owner   = e.db.transaction()
+		locks   = e.db.locks
+		targets = make(map[*source]bool, len(sources))
+	)
+
+	for _, src := range sources {
+		if e.db.tables[src.table.name] != src.table {
+			continue
+		}
+
+		if len(rl.Tables) == 0 {
+			targets[src] = true
+			continue
+		}
+
+		for _, table := range rl.Tables {
+			if table == src.alias {
+				targets[src] = true
+			}
+		}
+	}
+
+	locks.mu.Lock()
+	defer locks.mu.Unlock()
+
+	if rl.Wait == rel.LockSkipLocked {
+		available := envs[:0:0]
+		for _, en := range envs {
+			if e.available(owner, en, targets, rl.Strength) {
+				available = append(available, en)
+			}
+		}
+
+		envs = available
+	}
+
+	envs, err := e.sortEnvs(envs, query.SortQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset := int(query.OffsetQuery); offset > 0 {
+		if offset > len(envs) {
+			offset = len(envs)
+		}
+
+		envs = envs[offset:]
+	}
+
+	if limit := int(query.LimitQuery); limit > 0 && limit < len(envs) {
+		envs = envs[:limit]
+	}
+
+	for _, en := range envs {
+		if e.available(owner, en, targets, rl.Strength) {
+			continue
+		}
+
+		if rl.Wait == rel.LockNoWait {
+			return nil, rel.ConcurrencyError{
+				Type: rel.LockNotAvailable,
+				Err:  fmt.Errorf("memadapter: could not obtain lock on row in %s", query.Table),
+			}
+		}
+
+		return nil, rel.ConcurrencyError{
+			Type: rel.LockTimeout,
+			Err:  fmt.Errorf("memadapter: row in %s is locked by other transaction", query.Table),
+		}
+	}
+
+	for _, en := range envs {
+		for _, b := range en {
+			if targets[b.source] && b.values != nil {
+				locks.acquire(owner, b.source.table.name, b.id, rl.Strength)
+			}
+		}
+	}
+
+	return envs, nil
+}
+
+func (e executor) available(owner *database, en env, targets map[*source]bool, strength rel.LockStrength) bool {
+	for _, b := range en {
+		if targets[b.source] && b.values != nil && !e.db.locks.available(owner, b.source.table.name, b.id, strength) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e executor) sortEnvs(envs []env, sorts []rel.SortQuery) ([]env, error) {
+	if len(sorts) == 0 {
+		return envs, nil
+	}
+
+	keys := make([][]interface{}, len(envs))
+	for i, en := range envs {
+		keys[i] = make([]interface{}, len(sorts))
+		for j, sq := range sorts {
+			value, err := e.eval(parseExpression(sq.Field), scope{envs: []env{en}})
+			if err != nil {
+				return nil, err
+			}
+
+			keys[i][j] = value
+		}
+	}
+
+	indexes := make([]int, len(envs))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	sort.SliceStable(indexes, func(a, b int) bool {
+		for j, sq := range sorts {
+			c := order(keys[indexes[a]][j], keys[indexes[b]][j])
+			if sq.Desc() {
+				c = -c
+			}
+
+			if c != 0 {
+				return c < 0
+			}
+		}
+
+		return false
+	})
+
+	sorted := make([]env, len(envs))
+	for i, index := range indexes {
+		sorted[i] = envs[index]
+	}
+
+	return sorted, nil
+}
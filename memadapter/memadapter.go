@@ -0,0 +1,358 @@
+// Package memadapter is an in-memory implementation of rel.Adapter.
+//
+// It's intended to be used as a reference adapter and for testing without running any database service.
+// Tables and columns are created automatically on first write when it's not defined using migration,
+// and queries on undefined tables or columns are treated as empty or NULL.
+// Raw sql statements, filter fragments and on conflict fragments are not supported,
+// update fragment is limited to `field = ?` and `field = field op ?` form, where op is one of +, -, * or /.
+package memadapter
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-rel/rel"
+)
+
+// Adapter definition for in-memory database.
+type Adapter struct {
+	db           *database
+	instrumenter rel.Instrumenter
+	readOnly     bool
+}
+
+var (
+	_ rel.Adapter             = (*Adapter)(nil)
+	_ rel.TransactionBeginner = (*Adapter)(nil)
+	_ rel.Savepointer         = (*Adapter)(nil)
+)
+
+var errReadOnly = errors.New("memadapter: cannot execute write in a read-only transaction")
+
+// Close database connection, this is a noop.
+func (a *Adapter) Close() error {
+	return nil
+}
+
+// Instrumentation set instrumenter for this adapter.
+func (a *Adapter) Instrumentation(instrumenter rel.Instrumenter) {
+	a.instrumenter = instrumenter
+}
+
+// Ping database, it's always available.
+func (a *Adapter) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Aggregate record using given query.
+func (a *Adapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string) (int, error) {
+	var (
+		err    error
+		result int
+		finish = a.instrumenter.Observe(ctx, "adapter-aggregate", mode+"("+field+") "+query.String())
+	)
+
+	defer func() { finish(err) }()
+
+	a.db.mu.RLock()
+	defer a.db.mu.RUnlock()
+
+	if a.db.done {
+		err = errTransactionDone
+		return 0, err
+	}
+
+	query.SelectQuery = rel.SelectQuery{Fields: []string{mode + "(" + field + ")"}}
+
+	_, rows, err := executor{db: a.db}.query(query)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(rows) > 0 {
+		if value, ok := toInt64(rows[0][0]); ok {
+			result = int(value)
+		}
+	}
+
+	return result, nil
+}
+
+// Query performs query operation.
+func (a *Adapter) Query(ctx context.Context, query rel.Query) (rel.Cursor, error) {
+	var (
+		err    error
+		finish = a.instrumenter.Observe(ctx, "adapter-query", query.String())
+	)
+
+	defer func() { finish(err) }()
+
+	a.db.mu.RLock()
+	defer a.db.mu.RUnlock()
+
+	if a.db.done {
+		err = errTransactionDone
+		return nil, err
+	}
+
+	fields, rows, err := executor{db: a.db}.query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cursor{fields: fields, rows: rows, index: -1}, nil
+}
+
+// Insert inserts a record to database and returns its primary value.
+func (a *Adapter) Insert(ctx context.Context, query rel.Query, primaryField string, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (interface{}, error) {
+	ids, err := a.InsertAll(ctx, query, primaryField, nil, []map[string]rel.Mutate{mutates}, onConflict)
+	if err != nil {
+		return nil, err
+	}
+
+	return ids[0], nil
+}
+
+// InsertAll inserts multiple records to database and returns their primary values.
+func (a *Adapter) InsertAll(ctx context.Context, query rel.Query, primaryField string, fields []string, bulkMutates []map[string]rel.Mutate, onConflict rel.OnConflict) ([]interface{}, error) {
+	var (
+		err    error
+		ids    []interface{}
+		finish = a.instrumenter.Observe(ctx, "adapter-insert", "insert into "+query.Table)
+	)
+
+	defer func() { finish(err) }()
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	if a.db.done {
+		err = errTransactionDone
+		return nil, err
+	}
+
+	if a.readOnly {
+		err = errReadOnly
+		return nil, err
+	}
+
+	if onConflict.Fragment != "" {
+		err = errors.New("memadapter: on conflict fragment is not supported")
+		return nil, err
+	}
+
+	ids, err = a.db.insert(query.Table, primaryField, bulkMutates, onConflict)
+	return ids, err
+}
+
+// Update updates records that match the query and returns the number of updated records.
+func (a *Adapter) Update(ctx context.Context, query rel.Query, primaryField string, mutates map[string]rel.Mutate) (int, error) {
+	var (
+		err    error
+		count  int
+		finish = a.instrumenter.Observe(ctx, "adapter-update", "update "+query.String())
+	)
+
+	defer func() { finish(err) }()
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	if a.db.done {
+		err = errTransactionDone
+		return 0, err
+	}
+
+	if a.readOnly {
+		err = errReadOnly
+		return 0, err
+	}
+
+	count, err = a.db.update(query, mutates)
+	return count, err
+}
+
+// Delete deletes records that match the query and returns the number of deleted records.
+func (a *Adapter) Delete(ctx context.Context, query rel.Query) (int, error) {
+	var (
+		err    error
+		count  int
+		finish = a.instrumenter.Observe(ctx, "adapter-delete", "delete "+query.String())
+	)
+
+	defer func() { finish(err) }()
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	if a.db.done {
+		err = errTransactionDone
+		return 0, err
+	}
+
+	if a.readOnly {
+		err = errReadOnly
+		return 0, err
+	}
+
+	count, err = a.db.delete(query)
+	return count, err
+}
+
+// Exec raw statement, this is not supported.
+func (a *Adapter) Exec(ctx context.Context, stmt string, args []interface{}) (int64, int64, error) {
+	return 0, 0, errors.New("memadapter: raw statement is not supported")
+}
+
+// Begin a transaction.
+// Writes inside the transaction are not visible to others until it's committed.
+// Calling Begin inside a transaction will start a nested transaction.
+func (a *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	return a.BeginWith(ctx, rel.TransactionOptions{})
+}
+
+// BeginWith begins a transaction using options.
+// Transaction always works on its own snapshot, so isolation level up to repeatable read is supported,
+// serializable and deferrable transaction are not supported.
+// Commit fails with serialization failure when a row written by the transaction has been changed by other transaction,
+// and with constraint error when written row conflicts with unique key of rows committed by other transaction.
+func (a *Adapter) BeginWith(ctx context.Context, options rel.TransactionOptions) (rel.Adapter, error) {
+	var (
+		err    error
+		db     *database
+		finish = a.instrumenter.Observe(ctx, "adapter-begin", "begin")
+	)
+
+	switch {
+	case options.Isolation == rel.Serializable || options.Deferrable:
+		err = errors.New("memadapter: serializable and deferrable transaction are not supported")
+	case options.Isolation != "" && options.Isolation != rel.ReadUncommitted &&
+		options.Isolation != rel.ReadCommitted && options.Isolation != rel.RepeatableRead:
+		err = errors.New("memadapter: unknown isolation level " + string(options.Isolation))
+	default:
+		db, err = a.db.begin()
+	}
+
+	finish(err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Adapter{db: db, instrumenter: a.instrumenter, readOnly: a.readOnly || options.ReadOnly}, nil
+}
+
+// Commit current transaction.
+func (a *Adapter) Commit(ctx context.Context) error {
+	var (
+		err    error
+		finish = a.instrumenter.Observe(ctx, "adapter-commit", "commit")
+	)
+
+	if a.db.parent == nil {
+		err = errors.New("memadapter: unable to commit outside transaction")
+	} else {
+		err = a.db.commit()
+	}
+
+	finish(err)
+	return err
+}
+
+// Rollback current transaction.
+func (a *Adapter) Rollback(ctx context.Context) error {
+	var (
+		err    error
+		finish = a.instrumenter.Observe(ctx, "adapter-rollback", "rollback")
+	)
+
+	if a.db.parent == nil {
+		err = errors.New("memadapter: unable to rollback outside transaction")
+	} else {
+		err = a.db.rollback()
+	}
+
+	finish(err)
+	return err
+}
+
+// Savepoint creates a savepoint inside current transaction.
+func (a *Adapter) Savepoint(ctx context.Context, name string) error {
+	return a.withSavepoint(ctx, "adapter-savepoint", "savepoint "+name, func() error {
+		return a.db.savepoint(name)
+	})
+}
+
+// RollbackTo rolls back current transaction to the savepoint.
+func (a *Adapter) RollbackTo(ctx context.Context, name string) error {
+	return a.withSavepoint(ctx, "adapter-rollback-to", "rollback to savepoint "+name, func() error {
+		return a.db.rollbackTo(name)
+	})
+}
+
+// Release the savepoint.
+func (a *Adapter) Release(ctx context.Context, name string) error {
+	return a.withSavepoint(ctx, "adapter-release", "release savepoint "+name, func() error {
+		return a.db.release(name)
+	})
+}
+
+func (a *Adapter) withSavepoint(ctx context.Context, op string, message string, fn func() error) error {
+	var (
+		err    error
+		finish = a.instrumenter.Observe(ctx, op, message)
+	)
+
+	defer func() { finish(err) }()
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	if a.db.done {
+		err = errTransactionDone
+		return err
+	}
+
+	err = fn()
+	return err
+}
+
+// Apply table or index migration.
+func (a *Adapter) Apply(ctx context.Context, migration rel.Migration) error {
+	var (
+		err    error
+		finish = a.instrumenter.Observe(ctx, "adapter-apply", "apply migration")
+	)
+
+	defer func() { finish(err) }()
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	if a.db.done {
+		err = errTransactionDone
+		return err
+	}
+
+	if a.readOnly {
+		err = errReadOnly
+		return err
+	}
+
+	switch v := migration.(type) {
+	case rel.Table:
+		err = a.db.applyTable(v)
+	case rel.Index:
+		err = a.db.applyIndex(v)
+	default:
+		err = errors.New("memadapter: unsupported migration")
+	}
+
+	return err
+}
+
+// New in-memory adapter with empty database.
+func New() *Adapter {
+	return &Adapter{
+		db: newDatabase(),
+	}
+}
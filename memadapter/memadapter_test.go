@@ -0,0 +1,680 @@
+package memadapter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/join"
+	"github.com/go-rel/rel/sort"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+type User struct {
+	ID        int
+	Name      string
+	Age       int
+	Addresses []Address
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Address struct {
+	ID     int
+	UserID *int
+	Name   string
+}
+
+func TestAdapter(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = New()
+		repo    = rel.New(adapter)
+		users   = []User{
+			{Name: "John", Age: 20, Addresses: []Address{{Name: "home"}, {Name: "office"}}},
+			{Name: "Jane", Age: 30},
+			{Name: "Doe", Age: 40, Addresses: []Address{{Name: "home"}}},
+		}
+	)
+
+	assert.Nil(t, repo.Ping(ctx))
+	assert.Nil(t, repo.InsertAll(ctx, &users))
+	assert.Equal(t, 1, users[0].ID)
+	assert.Equal(t, 3, users[2].ID)
+
+	for i := range users {
+		for _, address := range users[i].Addresses {
+			assert.Nil(t, repo.Insert(ctx, &Address{UserID: &users[i].ID, Name: address.Name}))
+		}
+	}
+
+	t.Run("Find", func(t *testing.T) {
+		var user User
+		assert.Nil(t, repo.Find(ctx, &user, where.Eq("name", "Jane")))
+		assert.Equal(t, 2, user.ID)
+		assert.Equal(t, 30, user.Age)
+	})
+
+	t.Run("FindAll", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result, where.Gte("age", 30).OrEq("name", "John"), sort.Desc("age"), rel.Limit(2), rel.Offset(1)))
+		assert.Len(t, result, 2)
+		assert.Equal(t, "Jane", result[0].Name)
+		assert.Equal(t, "John", result[1].Name)
+	})
+
+	t.Run("FindAll filters", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result, where.In("id", 1, 3).AndLike("name", "%o%"), where.Not(where.Nil("name"))))
+		assert.Len(t, result, 2)
+	})
+
+	t.Run("Join", func(t *testing.T) {
+		var result []User
+		assert.Nil(t, repo.FindAll(ctx, &result,
+			rel.Select("users.*").Distinct(),
+			join.On("addresses", "addresses.user_id", "users.id"),
+			where.Eq("addresses.name", "home"),
+			sort.Asc("users.id"),
+		))
+		assert.Len(t, result, 2)
+		assert.Equal(t, "John", result[0].Name)
+		assert.Equal(t, "Doe", result[1].Name)
+	})
+
+	t.Run("Preload", func(t *testing.T) {
+		var user User
+		assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", 1)))
+		assert.Nil(t, repo.Preload(ctx, &user, "addresses"))
+		assert.Len(t, user.Addresses, 2)
+	})
+
+	t.Run("Aggregate", func(t *testing.T) {
+		count, err := repo.Count(ctx, "users")
+		assert.Nil(t, err)
+		assert.Equal(t, 3, count)
+
+		sum, err := repo.Aggregate(ctx, rel.From("users").Where(where.Gt("age", 20)), "sum", "age")
+		assert.Nil(t, err)
+		assert.Equal(t, 70, sum)
+	})
+
+	t.Run("Group", func(t *testing.T) {
+		cursor, err := adapter.Query(ctx, rel.From("addresses").Select("name", "count(*) AS total").Group("name").Having(where.Gt("count(*)", 1)))
+		assert.Nil(t, err)
+		assert.True(t, cursor.Next())
+
+		var (
+			name  string
+			total int
+		)
+
+		assert.Nil(t, cursor.Scan(&name, &total))
+		assert.Equal(t, "home", name)
+		assert.Equal(t, 2, total)
+		assert.False(t, cursor.Next())
+		assert.Nil(t, cursor.Close())
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		var user User
+		assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", 2)))
+
+		user.Name = "Janet"
+		assert.Nil(t, repo.Update(ctx, &user))
+		assert.Nil(t, repo.Update(ctx, &user, rel.Inc("age")))
+		assert.Equal(t, 31, user.Age)
+
+		updated, err := repo.UpdateAny(ctx, rel.From("users").Where(where.Eq("id", 2)), rel.Dec("age"))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, updated)
+
+		assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", 2)))
+		assert.Equal(t, "Janet", user.Name)
+		assert.Equal(t, 30, user.Age)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		deleted, err := repo.DeleteAny(ctx, rel.From("addresses").Where(where.Eq("name", "office")))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, deleted)
+		assert.Equal(t, 2, repo.MustCount(ctx, "addresses"))
+	})
+}
+
+func TestAdapter_WithRecursive(t *testing.T) {
+	type Category struct {
+		ID       int
+		ParentID *int
+		Name     string
+	}
+
+	var (
+		ctx        = context.TODO()
+		repo       = rel.New(New())
+		categories []Category
+	)
+
+	root := Category{Name: "root"}
+	repo.MustInsert(ctx, &root)
+	child := Category{ParentID: &root.ID, Name: "child"}
+	repo.MustInsert(ctx, &child)
+	repo.MustInsert(ctx, &Category{ParentID: &child.ID, Name: "grandchild"})
+	repo.MustInsert(ctx, &Category{Name: "other"})
+
+	query := rel.WithRecursive("tree",
+		rel.From("categories").Where(where.Eq("name", "root")),
+		rel.Select("categories.*").From("categories").JoinOn("tree", "categories.parent_id", "tree.id"),
+	).From("tree").SortAsc("id")
+
+	assert.Nil(t, repo.FindAll(ctx, &categories, query))
+	assert.Len(t, categories, 3)
+	assert.Equal(t, "grandchild", categories[2].Name)
+
+	assert.Equal(t, 2, repo.MustCount(ctx, "tree", rel.WithRecursive("tree",
+		rel.From("categories").Where(where.Eq("name", "child")),
+		rel.Select("categories.*").From("categories").JoinOn("tree", "categories.parent_id", "tree.id"),
+	)))
+
+	_, err := repo.Aggregate(ctx, rel.WithRecursive("loop",
+		rel.Select("id").From("categories").Where(where.Eq("name", "root")),
+		rel.Select("id").From("loop"),
+	).From("loop"), "count", "id")
+	assert.EqualError(t, err, "memadapter: recursive query loop exceeds maximum iteration")
+}
+
+func TestAdapter_Combine(t *testing.T) {
+	type Tag struct {
+		ID   int
+		Name string
+	}
+
+	var (
+		ctx  = context.TODO()
+		repo = rel.New(New())
+		tags []Tag
+	)
+
+	repo.MustInsertAll(ctx, &[]Tag{{Name: "go"}, {Name: "sql"}, {Name: "rel"}})
+
+	var (
+		all    = rel.Select("name").From("tags")
+		golang = rel.Select("name").From("tags").Where(where.Eq("name", "go"))
+		other  = rel.Select("name").From("tags").Where(where.Ne("name", "go"))
+	)
+
+	assert.Nil(t, repo.FindAll(ctx, &tags, all.Union(golang).SortAsc("name")))
+	assert.Equal(t, []Tag{{Name: "go"}, {Name: "rel"}, {Name: "sql"}}, tags)
+
+	assert.Nil(t, repo.FindAll(ctx, &tags, all.UnionAll(golang).SortDesc("name").Limit(3).Offset(1)))
+	assert.Equal(t, []Tag{{Name: "rel"}, {Name: "go"}, {Name: "go"}}, tags)
+
+	assert.Nil(t, repo.FindAll(ctx, &tags, all.Intersect(other).SortAsc("name")))
+	assert.Equal(t, []Tag{{Name: "rel"}, {Name: "sql"}}, tags)
+
+	assert.Nil(t, repo.FindAll(ctx, &tags, all.Except(other)))
+	assert.Equal(t, []Tag{{Name: "go"}}, tags)
+
+	assert.EqualError(t, repo.FindAll(ctx, &tags, all.Union(rel.Select("id", "name").From("tags"))),
+		"memadapter: combined queries must return the same number of columns")
+}
+
+func TestAdapter_Window(t *testing.T) {
+	type Employee struct {
+		ID         int
+		Department string
+		Name       string
+		Salary     int
+		Rank       int    `db:"rank"`
+		DenseRank  int    `db:"dense_rank"`
+		Number     int    `db:"number"`
+		Previous   *int   `db:"previous"`
+		Next       string `db:"next"`
+		Running    int    `db:"running"`
+		Total      int    `db:"total"`
+	}
+
+	var (
+		ctx       = context.TODO()
+		repo      = rel.New(New())
+		employees []Employee
+	)
+
+	repo.MustInsertAll(ctx, &[]Employee{
+		{Department: "sales", Name: "a", Salary: 300},
+		{Department: "sales", Name: "b", Salary: 200},
+		{Department: "sales", Name: "c", Salary: 200},
+		{Department: "sales", Name: "d", Salary: 100},
+		{Department: "tech", Name: "e", Salary: 500},
+	})
+
+	var (
+		bySalary = rel.PartitionBy("department").SortDesc("salary")
+		query    = rel.From("employees").SelectExpr(
+			rel.Rank().Over(bySalary).As("rank"),
+			rel.DenseRank().Over(bySalary).As("dense_rank"),
+			rel.RowNumber().Over(bySalary.SortAsc("name")).As("number"),
+			rel.Lag("salary", 1).Over(bySalary.SortAsc("name")).As("previous"),
+			rel.Expr("lead", "name", 1, "-").Over(bySalary.SortAsc("name")).As("next"),
+			rel.Expr("sum", "salary").Over(rel.Window{}.SortAsc("salary")).As("running"),
+			rel.Expr("sum", "salary").Over(rel.PartitionBy("department")).As("total"),
+		).SortAsc("number").SortAsc("department")
+	)
+
+	assert.Nil(t, repo.FindAll(ctx, &employees, query))
+	assert.Len(t, employees, 5)
+
+	for _, employee := range employees {
+		var (
+			previous *int
+			salaries = []int{300, 200, 200}
+		)
+
+		switch employee.Name {
+		case "a":
+			assert.Equal(t, []int{1, 1, 1, 800, 800}, []int{employee.Rank, employee.DenseRank, employee.Number, employee.Running, employee.Total})
+			assert.Equal(t, "b", employee.Next)
+		case "b", "c":
+			previous = &salaries[employee.Number-2]
+			assert.Equal(t, []int{2, 2, 500, 800}, []int{employee.Rank, employee.DenseRank, employee.Running, employee.Total})
+		case "d":
+			previous = &salaries[2]
+			assert.Equal(t, []int{4, 3, 4, 100, 800}, []int{employee.Rank, employee.DenseRank, employee.Number, employee.Running, employee.Total})
+			assert.Equal(t, "-", employee.Next)
+		case "e":
+			assert.Equal(t, []int{1, 1, 1, 1300, 500}, []int{employee.Rank, employee.DenseRank, employee.Number, employee.Running, employee.Total})
+		}
+
+		assert.Equal(t, previous, employee.Previous, employee.Name)
+	}
+
+	assert.Equal(t, []string{"a", "e", "b", "c", "d"}, []string{employees[0].Name, employees[1].Name, employees[2].Name, employees[3].Name, employees[4].Name})
+
+	var summary []Employee
+	assert.Nil(t, repo.FindAll(ctx, &summary, rel.Select("department").
+		SelectExpr(rel.Expr("sum", "salary").As("total"), rel.Expr("count").As("number")).
+		From("employees").Group("department").SortAsc("department")))
+	assert.Equal(t, []Employee{{Department: "sales", Total: 800, Number: 4}, {Department: "tech", Total: 500, Number: 1}}, summary)
+
+	assert.EqualError(t, repo.FindAll(ctx, &employees, rel.From("employees").SelectExpr(rel.Expr("ntile", 4).Over(rel.Window{}))),
+		"memadapter: unsupported function ntile")
+	assert.EqualError(t, repo.FindAll(ctx, &employees, rel.From("employees").SelectExpr(rel.Lag("salary", 1).As("previous"))),
+		"memadapter: unsupported function lag")
+}
+
+func TestAdapter_RowLock(t *testing.T) {
+	type Job struct {
+		ID   int
+		Name string
+	}
+
+	var (
+		ctx     = context.TODO()
+		adapter = New()
+		repo    = rel.New(adapter)
+		jobs    = []Job{{Name: "a"}, {Name: "b"}}
+		job     Job
+	)
+
+	repo.MustInsertAll(ctx, &jobs)
+
+	// lock is ignored outside transaction.
+	assert.Nil(t, repo.Find(ctx, &job, rel.ForUpdate()))
+
+	trx1, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+	trx2, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+
+	var (
+		repo1 = rel.New(trx1)
+		repo2 = rel.New(trx2)
+		first = where.Eq("id", jobs[0].ID)
+	)
+
+	assert.Nil(t, repo1.Find(ctx, &job, first, rel.RowLockForShare()))
+	assert.Nil(t, repo2.Find(ctx, &job, first, rel.RowLockForKeyShare().NoWait()))
+	assert.Nil(t, repo2.Find(ctx, &job, first, rel.RowLockForShare().NoWait()))
+	assert.True(t, errors.Is(repo2.Find(ctx, &job, first, rel.RowLockForNoKeyUpdate().NoWait()), rel.ErrLockNotAvailable))
+	assert.True(t, errors.Is(repo2.Find(ctx, &job, first, rel.ForUpdate()), rel.ErrLockTimeout))
+
+	assert.Nil(t, repo2.Find(ctx, &job, rel.RowLockForUpdate().SkipLocked(), rel.Limit(1)))
+	assert.Equal(t, "b", job.Name)
+
+	// lock held by the same transaction never conflicts.
+	assert.Nil(t, repo2.Find(ctx, &job, where.Eq("id", jobs[1].ID), rel.RowLockForUpdate().NoWait()))
+
+	var jobs1 []Job
+	assert.Nil(t, repo1.FindAll(ctx, &jobs1, rel.RowLockForUpdate().SkipLocked()))
+	assert.Len(t, jobs1, 0)
+
+	assert.Nil(t, trx2.Rollback(ctx))
+	assert.Nil(t, repo1.FindAll(ctx, &jobs1, rel.RowLockForUpdate().SkipLocked()))
+	assert.Len(t, jobs1, 2)
+
+	_, err = trx1.Query(ctx, rel.From("jobs").Group("name").RowLock(rel.RowLockForUpdate()))
+	assert.EqualError(t, err, "memadapter: lock is not allowed with group")
+
+	assert.Nil(t, trx1.Commit(ctx))
+	assert.Empty(t, adapter.db.locks.rows)
+}
+
+func TestAdapter_Transaction(t *testing.T) {
+	var (
+		ctx  = context.TODO()
+		repo = rel.New(New())
+	)
+
+	err := repo.Transaction(ctx, func(ctx context.Context) error {
+		repo.MustInsert(ctx, &User{Name: "John"})
+		assert.Equal(t, 1, repo.MustCount(ctx, "users"))
+		return rel.ErrNotFound
+	})
+
+	assert.Equal(t, rel.ErrNotFound, err)
+	assert.Equal(t, 0, repo.MustCount(ctx, "users"))
+
+	assert.Nil(t, repo.Transaction(ctx, func(ctx context.Context) error {
+		repo.MustInsert(ctx, &User{Name: "John"})
+		return nil
+	}))
+
+	assert.Equal(t, 1, repo.MustCount(ctx, "users"))
+}
+
+func TestAdapter_Savepoint(t *testing.T) {
+	var (
+		ctx  = context.TODO()
+		repo = rel.New(New())
+	)
+
+	assert.Nil(t, repo.Transaction(ctx, func(ctx context.Context) error {
+		repo.MustInsert(ctx, &User{Name: "outer"})
+
+		err := repo.Transaction(ctx, func(ctx context.Context) error {
+			repo.MustInsert(ctx, &User{Name: "inner"})
+			repo.MustUpdateAny(ctx, rel.From("users").Where(where.Eq("name", "outer")), rel.Set("age", 10))
+			return rel.ErrNotFound
+		})
+
+		assert.Equal(t, rel.ErrNotFound, err)
+		assert.Equal(t, 0, repo.MustCount(ctx, "users", where.Eq("name", "inner")))
+		assert.Equal(t, 0, repo.MustCount(ctx, "users", where.Eq("age", 10)))
+
+		return repo.Transaction(ctx, func(ctx context.Context) error {
+			repo.MustInsert(ctx, &User{Name: "committed"})
+			return nil
+		})
+	}))
+
+	assert.Equal(t, 2, repo.MustCount(ctx, "users"))
+	assert.Equal(t, 1, repo.MustCount(ctx, "users", where.Eq("name", "committed")))
+}
+
+func TestAdapter_SavepointError(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = New()
+	)
+
+	assert.Error(t, adapter.Savepoint(ctx, "sp"))
+
+	trx, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+
+	sp := trx.(rel.Savepointer)
+	assert.Error(t, sp.RollbackTo(ctx, "sp"))
+	assert.Error(t, sp.Release(ctx, "sp"))
+	assert.Nil(t, sp.Savepoint(ctx, "sp"))
+	assert.Nil(t, sp.RollbackTo(ctx, "sp"))
+	assert.Nil(t, sp.RollbackTo(ctx, "sp"))
+	assert.Nil(t, sp.Release(ctx, "sp"))
+	assert.Error(t, sp.Release(ctx, "sp"))
+}
+
+func TestAdapter_TransactionOptions(t *testing.T) {
+	var (
+		ctx  = context.TODO()
+		repo = rel.New(New())
+	)
+
+	repo.MustInsert(ctx, &User{Name: "user"})
+
+	assert.Nil(t, repo.Transaction(ctx, func(ctx context.Context) error {
+		assert.Equal(t, 1, repo.MustCount(ctx, "users"))
+		assert.Equal(t, errReadOnly, repo.Insert(ctx, &User{Name: "read only"}))
+		_, err := repo.UpdateAny(ctx, rel.From("users"), rel.Set("age", 10))
+		assert.Equal(t, errReadOnly, err)
+		_, err = repo.DeleteAny(ctx, rel.From("users"))
+		assert.Equal(t, errReadOnly, err)
+		return nil
+	}, rel.RepeatableRead, rel.ReadOnly(true)))
+
+	assert.Error(t, repo.Transaction(ctx, func(ctx context.Context) error {
+		return nil
+	}, rel.Serializable))
+
+	assert.Error(t, repo.Transaction(ctx, func(ctx context.Context) error {
+		return nil
+	}, rel.ReadOnly(true), rel.Deferrable(true)))
+
+	assert.Error(t, repo.Transaction(ctx, func(ctx context.Context) error {
+		return nil
+	}, rel.IsolationLevel("SNAPSHOT")))
+}
+
+func TestAdapter_TransactionIsolation(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = New()
+		repo    = rel.New(adapter)
+		user    = User{Name: "John"}
+	)
+
+	repo.MustInsert(ctx, &user)
+
+	trx, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+
+	trxRepo := rel.New(trx)
+	trxRepo.MustInsert(ctx, &User{Name: "Jane"})
+	trxRepo.MustUpdate(ctx, &user, rel.Set("name", "Johnny"))
+
+	assert.Equal(t, 2, trxRepo.MustCount(ctx, "users"))
+	assert.Equal(t, 1, repo.MustCount(ctx, "users"))
+	assert.Equal(t, 1, repo.MustCount(ctx, "users", where.Eq("name", "John")))
+
+	assert.Nil(t, trx.Commit(ctx))
+	assert.Equal(t, errTransactionDone, trx.Commit(ctx))
+
+	assert.Equal(t, 2, repo.MustCount(ctx, "users"))
+	assert.Equal(t, 1, repo.MustCount(ctx, "users", where.Eq("name", "Johnny")))
+}
+
+func TestAdapter_TransactionWriteConflict(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = New()
+		repo    = rel.New(adapter)
+		user    = User{Name: "John"}
+	)
+
+	repo.MustInsert(ctx, &user)
+
+	trx1, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+	trx2, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+
+	rel.New(trx1).MustUpdateAny(ctx, rel.From("users").Where(where.Eq("id", user.ID)), rel.Inc("age"))
+	rel.New(trx2).MustUpdateAny(ctx, rel.From("users").Where(where.Eq("id", user.ID)), rel.Inc("age"))
+
+	assert.Nil(t, trx1.Commit(ctx))
+	err = trx2.Commit(ctx)
+	assert.True(t, errors.Is(err, rel.ErrSerializationFailure))
+
+	repo.MustFind(ctx, &user, where.Eq("id", user.ID))
+	assert.Equal(t, 1, user.Age)
+
+	trx3, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+
+	rel.New(trx3).MustUpdateAny(ctx, rel.From("users").Where(where.Eq("id", user.ID)), rel.Inc("age"))
+	repo.MustDeleteAny(ctx, rel.From("users").Where(where.Eq("id", user.ID)))
+
+	err = trx3.Commit(ctx)
+	assert.True(t, errors.Is(err, rel.ErrSerializationFailure))
+	assert.Equal(t, 0, repo.MustCount(ctx, "users"))
+}
+
+func TestAdapter_TransactionUniqueConflict(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = New()
+		repo    = rel.New(adapter)
+		table   = rel.Table{Op: rel.SchemaCreate, Name: "tags"}
+	)
+
+	table.ID("id")
+	table.String("slug", rel.Unique(true))
+	assert.Nil(t, adapter.Apply(ctx, table))
+
+	trx1, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+	trx2, err := adapter.Begin(ctx)
+	assert.Nil(t, err)
+
+	_, err = trx1.Insert(ctx, rel.From("tags"), "id", map[string]rel.Mutate{"slug": rel.Set("slug", "dup")}, rel.OnConflict{})
+	assert.Nil(t, err)
+	_, err = trx2.Insert(ctx, rel.From("tags"), "id", map[string]rel.Mutate{"slug": rel.Set("slug", "dup")}, rel.OnConflict{})
+	assert.Nil(t, err)
+
+	assert.Nil(t, trx1.Commit(ctx))
+
+	err = trx2.Commit(ctx)
+	assert.True(t, errors.Is(err, rel.ErrUniqueConstraint))
+	assert.Equal(t, 1, repo.MustCount(ctx, "tags"))
+}
+
+func TestAdapter_CommitOutsideTransaction(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = New()
+	)
+
+	assert.Error(t, adapter.Commit(ctx))
+	assert.Error(t, adapter.Rollback(ctx))
+}
+
+func TestAdapter_Apply(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = New()
+		repo    = rel.New(adapter)
+	)
+
+	users := rel.Table{Op: rel.SchemaCreate, Name: "users"}
+	users.ID("id")
+	users.String("name", rel.Unique(true))
+	users.Int("age", rel.Default(18))
+	users.DateTime("created_at")
+	users.DateTime("updated_at")
+	assert.Nil(t, adapter.Apply(ctx, users))
+	assert.Error(t, adapter.Apply(ctx, users))
+
+	addresses := rel.Table{Op: rel.SchemaCreate, Name: "addresses"}
+	addresses.ID("id")
+	addresses.Int("user_id")
+	addresses.String("name", rel.Required(true))
+	addresses.ForeignKey("user_id", "users", "id", rel.OnDelete("CASCADE"))
+	assert.Nil(t, adapter.Apply(ctx, addresses))
+
+	user := User{Name: "John"}
+	repo.MustInsert(ctx, &user)
+
+	_, err := adapter.Insert(ctx, rel.From("users"), "id", map[string]rel.Mutate{"name": rel.Set("name", "Jane")}, rel.OnConflict{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, repo.MustCount(ctx, "users", where.Eq("age", 18)))
+
+	err = repo.Insert(ctx, &User{Name: "John"})
+	assert.ErrorIs(t, err, rel.ErrUniqueConstraint)
+	assert.Equal(t, "users_name_key", err.(rel.ConstraintError).Key)
+
+	invalid := 10
+	assert.ErrorIs(t, repo.Insert(ctx, &Address{UserID: &invalid, Name: "home"}), rel.ErrForeignKeyConstraint)
+	_, err = adapter.Insert(ctx, rel.From("addresses"), "id", map[string]rel.Mutate{"user_id": rel.Set("user_id", user.ID)}, rel.OnConflict{})
+	assert.ErrorIs(t, err, rel.ErrNotNullConstraint)
+
+	repo.MustInsert(ctx, &Address{UserID: &user.ID, Name: "home"})
+	repo.MustDelete(ctx, &user)
+	assert.Equal(t, 0, repo.MustCount(ctx, "addresses"))
+
+	assert.Nil(t, adapter.Apply(ctx, rel.Index{Op: rel.SchemaCreate, Table: "addresses", Name: "addresses_name_idx", Unique: true, Columns: []string{"name"}}))
+	repo.MustInsert(ctx, &Address{Name: "home"})
+	assert.ErrorIs(t, repo.Insert(ctx, &Address{Name: "home"}), rel.ErrUniqueConstraint)
+	assert.Nil(t, adapter.Apply(ctx, rel.Index{Op: rel.SchemaDrop, Table: "addresses", Name: "addresses_name_idx"}))
+	assert.Nil(t, repo.Insert(ctx, &Address{Name: "home"}))
+
+	alter := rel.AlterTable{Table: rel.Table{Op: rel.SchemaAlter, Name: "addresses"}}
+	alter.RenameColumn("name", "label")
+	assert.Nil(t, adapter.Apply(ctx, alter.Table))
+	assert.Equal(t, 2, repo.MustCount(ctx, "addresses", where.Eq("label", "home")))
+
+	assert.Nil(t, adapter.Apply(ctx, rel.Table{Op: rel.SchemaDrop, Name: "addresses"}))
+	assert.Nil(t, adapter.Apply(ctx, rel.Table{Op: rel.SchemaDrop, Name: "addresses", Optional: true}))
+	assert.Error(t, adapter.Apply(ctx, rel.Table{Op: rel.SchemaDrop, Name: "addresses"}))
+}
+
+func TestAdapter_OnConflict(t *testing.T) {
+	var (
+		ctx  = context.TODO()
+		repo = rel.New(New())
+		user = User{ID: 1, Name: "John"}
+	)
+
+	repo.MustInsert(ctx, &user)
+	assert.ErrorIs(t, repo.Insert(ctx, &User{ID: 1, Name: "Jane"}), rel.ErrPrimaryKeyConstraint)
+
+	assert.Nil(t, repo.Insert(ctx, &User{ID: 1, Name: "Jane"}, rel.OnConflictIgnore()))
+	assert.Equal(t, 1, repo.MustCount(ctx, "users", where.Eq("name", "John")))
+
+	assert.Nil(t, repo.Insert(ctx, &User{ID: 1, Name: "Jane"}, rel.OnConflictKeyReplace("id")))
+	assert.Equal(t, 1, repo.MustCount(ctx, "users", where.Eq("name", "Jane")))
+
+	user = User{Name: "Doe"}
+	repo.MustInsert(ctx, &user)
+	assert.Equal(t, 2, user.ID)
+}
+
+func TestAdapter_Unsupported(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = New()
+	)
+
+	_, err := adapter.Insert(ctx, rel.From("users"), "id", map[string]rel.Mutate{"name": rel.Set("name", "John")}, rel.OnConflict{})
+	assert.Nil(t, err)
+
+	_, _, err = adapter.Exec(ctx, "SELECT 1", nil)
+	assert.Error(t, err)
+
+	_, err = adapter.Query(ctx, rel.Build("", rel.SQL("SELECT 1")))
+	assert.Error(t, err)
+
+	_, err = adapter.Query(ctx, rel.From("users").Where(where.Fragment("id = ?", 1)))
+	assert.Error(t, err)
+
+	_, err = adapter.Insert(ctx, rel.From("users"), "id", map[string]rel.Mutate{"name": rel.Set("name", "John")}, rel.OnConflictFragment("ON CONFLICT DO NOTHING"))
+	assert.Error(t, err)
+
+	_, err = adapter.Update(ctx, rel.From("users"), "id", map[string]rel.Mutate{"name = upper(name)": rel.SetFragment("name = upper(name)")})
+	assert.EqualError(t, err, "memadapter: unsupported update fragment name = upper(name)")
+
+	_, err = adapter.Update(ctx, rel.From("users"), "id", map[string]rel.Mutate{"id = id % ?": rel.SetFragment("id = id % ?", 2)})
+	assert.EqualError(t, err, "memadapter: unsupported operator %")
+
+	assert.Error(t, adapter.Apply(ctx, rel.Raw("CREATE TABLE users")))
+}
@@ -0,0 +1,563 @@
+package memadapter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-rel/rel"
+)
+
+func (db *database) table(name string, primaryField string) *table {
+	t, ok := db.tables[name]
+	if !ok {
+		t = newTable(name)
+		if primaryField != "" {
+			t.addColumn(column{name: primaryField, primary: true, autoIncrement: true})
+			t.uniques = append(t.uniques, primaryKey(name, []string{primaryField}))
+		}
+
+		db.tables[name] = t
+		db.changeSchema(name)
+	}
+
+	return t
+}
+
+func (db *database) insert(name string, primaryField string, bulkMutates []map[string]rel.Mutate, onConflict rel.OnConflict) ([]interface{}, error) {
+	var (
+		t   = db.table(name, primaryField)
+		ids = make([]interface{}, len(bulkMutates))
+	)
+
+	for i, mutates := range bulkMutates {
+		values := make(map[string]interface{}, len(mutates))
+		for field, mut := range mutates {
+			if mut.Type != rel.ChangeSetOp {
+				return nil, fmt.Errorf("memadapter: unsupported insert operation on field %s", field)
+			}
+
+			if err := db.assign(t, values, field, mut.Value); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, col := range t.columns {
+			if _, ok := values[col.name]; ok {
+				continue
+			}
+
+			if col.autoIncrement {
+				values[col.name] = db.seq.next(t.name)
+			} else {
+				values[col.name] = col.def
+			}
+		}
+
+		r, err := db.insertRow(t, row{id: db.nextRowID(), values: values}, onConflict)
+		if err != nil {
+			return nil, err
+		}
+
+		if primaryField != "" {
+			ids[i] = r.values[primaryField]
+		}
+	}
+
+	return ids, nil
+}
+
+func (db *database) insertRow(t *table, r row, onConflict rel.OnConflict) (row, error) {
+	if err := db.checkRequired(t, r); err != nil {
+		return r, err
+	}
+
+	if index, err := db.checkUnique(t, r); err != nil {
+		var (
+			existing = t.rows[index]
+			uk       = err.(rel.ConstraintError)
+		)
+
+		if !handleConflict(t, uk.Key, onConflict) {
+			return r, err
+		}
+
+		if onConflict.Ignore {
+			return existing, nil
+		}
+
+		replaced := existing.clone()
+		for field, value := range r.values {
+			if c, ok := t.column(field); ok && (c.primary || c.autoIncrement) {
+				continue
+			}
+
+			replaced.values[field] = value
+		}
+
+		return replaced, db.updateRow(t, index, replaced)
+	}
+
+	if err := db.checkForeignKeys(t, r); err != nil {
+		return r, err
+	}
+
+	r.version = db.nextVersion()
+	t.put(r)
+	db.touch(t.name, r.id, 0)
+
+	return r, nil
+}
+
+func (db *database) updateRow(t *table, index int, r row) error {
+	if err := db.checkRequired(t, r); err != nil {
+		return err
+	}
+
+	if _, err := db.checkUnique(t, r); err != nil {
+		return err
+	}
+
+	if err := db.checkForeignKeys(t, r); err != nil {
+		return err
+	}
+
+	base := t.rows[index].version
+	r.version = db.nextVersion()
+	t.rows[index] = r
+	db.touch(t.name, r.id, base)
+
+	return nil
+}
+
+func (db *database) assign(t *table, values map[string]interface{}, field string, value interface{}) error {
+	v, err := normalize(value)
+	if err != nil {
+		return err
+	}
+
+	if col, ok := t.column(field); !ok {
+		t.addColumn(column{name: field})
+	} else if col.autoIncrement {
+		if id, ok := v.(int64); ok {
+			db.seq.observe(t.name, id)
+		}
+	}
+
+	values[field] = v
+	return nil
+}
+
+func (db *database) update(query rel.Query, mutates map[string]rel.Mutate) (int, error) {
+	var (
+		name, _ = parseSource(query.Table)
+		t, ok   = db.tables[name]
+	)
+
+	if !ok {
+		return 0, nil
+	}
+
+	indexes, err := db.match(t, query)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, index := range indexes {
+		r := t.rows[index].clone()
+
+		for field, mut := range mutates {
+			switch mut.Type {
+			case rel.ChangeSetOp:
+				if err := db.assign(t, r.values, field, mut.Value); err != nil {
+					return 0, err
+				}
+			case rel.ChangeIncOp:
+				delta, _ := normalize(mut.Value)
+				switch v := r.values[field].(type) {
+				case nil:
+				case int64:
+					n, _ := toInt64(delta)
+					r.values[field] = v + n
+				case float64:
+					n, _ := toFloat64(delta)
+					r.values[field] = v + n
+				default:
+					return 0, fmt.Errorf("memadapter: cannot increment non numeric field %s", field)
+				}
+			case rel.ChangeFragmentOp:
+				args, _ := mut.Value.([]interface{})
+				if err := db.fragment(t, r.values, mut.Field, args); err != nil {
+					return 0, err
+				}
+			}
+		}
+
+		if err := db.updateRow(t, index, r); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(indexes), nil
+}
+
+// fragment applies update fragment, only `field = ?` and `field = field op ?` form is supported,
+// where op is one of +, -, * or /.
+func (db *database) fragment(t *table, values map[string]interface{}, fragment string, args []interface{}) error {
+	eq := strings.IndexByte(fragment, '=')
+	if eq < 0 || len(args) != 1 {
+		return fmt.Errorf("memadapter: unsupported update fragment %s", fragment)
+	}
+
+	var (
+		field = strings.TrimSpace(fragment[:eq])
+		rhs   = strings.Fields(fragment[eq+1:])
+	)
+
+	switch {
+	case len(rhs) == 1 && rhs[0] == "?":
+		return db.assign(t, values, field, args[0])
+	case len(rhs) == 3 && rhs[0] == field && rhs[2] == "?":
+		arg, err := normalize(args[0])
+		if err != nil {
+			return err
+		}
+
+		value, err := arithmetic(rhs[1], values[field], arg)
+		if err != nil {
+			return err
+		}
+
+		values[field] = value
+		return nil
+	}
+
+	return fmt.Errorf("memadapter: unsupported update fragment %s", fragment)
+}
+
+// arithmetic evaluates a op b, null is returned when any of the operand is null.
+func arithmetic(op string, a interface{}, b interface{}) (interface{}, error) {
+	if a == nil || b == nil {
+		return nil, nil
+	}
+
+	if x, ok := a.(int64); ok {
+		if y, ok := b.(int64); ok {
+			switch op {
+			case "+":
+				return x + y, nil
+			case "-":
+				return x - y, nil
+			case "*":
+				return x * y, nil
+			case "/":
+				if y == 0 {
+					return nil, errors.New("memadapter: division by zero")
+				}
+
+				return x / y, nil
+			}
+
+			return nil, errors.New("memadapter: unsupported operator " + op)
+		}
+	}
+
+	x, ok1 := toFloat64(a)
+	y, ok2 := toFloat64(b)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("memadapter: cannot evaluate %v %s %v", a, op, b)
+	}
+
+	switch op {
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	case "/":
+		if y == 0 {
+			return nil, errors.New("memadapter: division by zero")
+		}
+
+		return x / y, nil
+	}
+
+	return nil, errors.New("memadapter: unsupported operator " + op)
+}
+
+func (db *database) delete(query rel.Query) (int, error) {
+	var (
+		name, _ = parseSource(query.Table)
+		t, ok   = db.tables[name]
+	)
+
+	if !ok {
+		return 0, nil
+	}
+
+	indexes, err := db.match(t, query)
+	if err != nil {
+		return 0, err
+	}
+
+	ids := make([]int64, len(indexes))
+	for i, index := range indexes {
+		ids[i] = t.rows[index].id
+	}
+
+	return len(ids), db.deleteRows(t, ids)
+}
+
+func (db *database) deleteRows(t *table, ids []int64) error {
+	for _, id := range ids {
+		r, ok := t.get(id)
+		if !ok {
+			continue
+		}
+
+		if err := db.checkReferences(t, r); err != nil {
+			return err
+		}
+
+		t.remove(id)
+		db.touch(t.name, id, r.version)
+	}
+
+	return nil
+}
+
+// match returns the index of rows that match the query filter.
+func (db *database) match(t *table, query rel.Query) ([]int, error) {
+	var (
+		_, alias = parseSource(query.Table)
+		src      = &source{alias: alias, table: t}
+		exec     = executor{db: db}
+		indexes  []int
+	)
+
+	for i := range t.rows {
+		ok, err := exec.match(query.WhereQuery, scope{envs: []env{{{source: src, values: t.rows[i].values}}}})
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes, nil
+}
+
+func (db *database) checkRequired(t *table, r row) error {
+	for _, col := range t.columns {
+		if col.required && r.values[col.name] == nil {
+			return rel.ConstraintError{
+				Key:  col.name,
+				Type: rel.NotNullConstraint,
+				Err:  fmt.Errorf("memadapter: null value in column %s violates not-null constraint", col.name),
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkUnique returns index of conflicting row.
+func (db *database) checkUnique(t *table, r row) (int, error) {
+	for _, uk := range t.uniques {
+		if index, err := db.checkUniqueKey(t, uk, r); err != nil {
+			return index, err
+		}
+	}
+
+	return -1, nil
+}
+
+func (db *database) checkUniqueKey(t *table, uk uniqueKey, r row) (int, error) {
+	return db.checkUniqueKeyExcept(t, uk, r, nil)
+}
+
+// checkUniqueKeyExcept checks unique key against rows that are not in skip,
+// it's used on commit to ignore rows that are overwritten by the transaction.
+func (db *database) checkUniqueKeyExcept(t *table, uk uniqueKey, r row, skip map[int64]int64) (int, error) {
+	exec := executor{db: db}
+	if !db.inUniqueKey(exec, t, uk, r) {
+		return -1, nil
+	}
+
+	for i := range t.rows {
+		if _, skipped := skip[t.rows[i].id]; skipped {
+			continue
+		}
+
+		if t.rows[i].id == r.id || !db.inUniqueKey(exec, t, uk, t.rows[i]) {
+			continue
+		}
+
+		conflict := true
+		for _, col := range uk.columns {
+			if !equal(r.values[col], t.rows[i].values[col]) {
+				conflict = false
+				break
+			}
+		}
+
+		if conflict {
+			return i, rel.ConstraintError{
+				Key:  uk.name,
+				Type: uk.typ,
+				Err:  fmt.Errorf("memadapter: duplicate key value violates unique constraint %s", uk.name),
+			}
+		}
+	}
+
+	return -1, nil
+}
+
+func (db *database) inUniqueKey(exec executor, t *table, uk uniqueKey, r row) bool {
+	for _, col := range uk.columns {
+		if r.values[col] == nil {
+			return false
+		}
+	}
+
+	if uk.filter.None() {
+		return true
+	}
+
+	ok, _ := exec.match(uk.filter, scope{envs: []env{{{source: &source{alias: t.name, table: t}, values: r.values}}}})
+	return ok
+}
+
+func (db *database) checkForeignKeys(t *table, r row) error {
+	for _, fk := range t.foreignKeys {
+		value := r.values[fk.column]
+		if value == nil {
+			continue
+		}
+
+		found := false
+		if ref, ok := db.tables[fk.refTable]; ok {
+			for i := range ref.rows {
+				if equal(ref.rows[i].values[fk.refColumn], value) {
+					found = true
+					break
+				}
+			}
+		}
+
+		if !found {
+			return rel.ConstraintError{
+				Key:  fk.name,
+				Type: rel.ForeignKeyConstraint,
+				Err:  fmt.Errorf("memadapter: insert or update on table %s violates foreign key constraint %s", t.name, fk.name),
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkReferences of deleted row, applies on delete action when specified.
+func (db *database) checkReferences(t *table, r row) error {
+	for _, child := range db.tables {
+		for _, fk := range child.foreignKeys {
+			if fk.refTable != t.name {
+				continue
+			}
+
+			var (
+				value = r.values[fk.refColumn]
+				ids   []int64
+			)
+
+			for i := range child.rows {
+				if child.rows[i].id != r.id && equal(child.rows[i].values[fk.column], value) {
+					ids = append(ids, child.rows[i].id)
+				}
+			}
+
+			if len(ids) == 0 {
+				continue
+			}
+
+			switch strings.ToUpper(fk.onDelete) {
+			case "CASCADE":
+				if err := db.deleteRows(child, ids); err != nil {
+					return err
+				}
+			case "SET NULL":
+				for _, id := range ids {
+					index, _ := child.search(id)
+					var (
+						base    = child.rows[index].version
+						updated = child.rows[index].clone()
+					)
+
+					updated.values[fk.column] = nil
+					updated.version = db.nextVersion()
+					child.rows[index] = updated
+					db.touch(child.name, id, base)
+				}
+			default:
+				return rel.ConstraintError{
+					Key:  fk.name,
+					Type: rel.ForeignKeyConstraint,
+					Err:  fmt.Errorf("memadapter: delete on table %s violates foreign key constraint %s", t.name, fk.name),
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func handleConflict(t *table, key string, onConflict rel.OnConflict) bool {
+	if !onConflict.Ignore && !onConflict.Replace {
+		return false
+	}
+
+	if len(onConflict.Keys) == 0 {
+		return true
+	}
+
+	for _, uk := range t.uniques {
+		if uk.name == key {
+			return sameColumns(uk.columns, onConflict.Keys)
+		}
+	}
+
+	return false
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, col := range a {
+		found := false
+		for i := range b {
+			if b[i] == col {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func primaryKey(table string, columns []string) uniqueKey {
+	return uniqueKey{
+		name:    table + "_pkey",
+		typ:     rel.PrimaryKeyConstraint,
+		columns: columns,
+	}
+}
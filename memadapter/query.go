@@ -0,0 +1,955 @@
+package memadapter
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-rel/rel"
+)
+
+type source struct {
+	alias string
+	table *table
+}
+
+type binding struct {
+	source *source
+	id     int64
+	values map[string]interface{} // nil when row is null-extended by outer join.
+}
+
+type env []binding
+
+func (e env) lookup(field string) (interface{}, bool) {
+	if i := strings.LastIndexByte(field, '.'); i >= 0 {
+		var (
+			alias  = field[:i]
+			column = field[i+1:]
+		)
+
+		for _, b := range e {
+			if b.source.alias == alias {
+				return b.values[column], true
+			}
+		}
+
+		return nil, false
+	}
+
+	for _, b := range e {
+		if v, ok := b.values[field]; ok {
+			return v, true
+		}
+
+		if _, ok := b.source.table.column(field); ok {
+			return nil, true
+		}
+	}
+
+	return nil, false
+}
+
+// scope is a context used to evaluate expression.
+// envs contains the rows of current group, it only contains a single row when query is not grouped.
+type scope struct {
+	envs   []env
+	output map[string]interface{}
+}
+
+func (s scope) lookup(field string) interface{} {
+	if v, ok := s.output[field]; ok {
+		return v
+	}
+
+	if len(s.envs) == 0 {
+		return nil
+	}
+
+	v, _ := s.envs[0].lookup(field)
+	return v
+}
+
+// maxRecursion is the maximum number of iteration of recursive common table expression.
+const maxRecursion = 10000
+
+// executor evaluates query against a database layer.
+// caller must hold the database lock.
+type executor struct {
+	db    *database
+	ctes  map[string]*table
+	outer env // current row of outer query, visible to correlated sub query.
+}
+
+// correlate returns executor for sub query that is evaluated in the scope of current row.
+func (e executor) correlate(s scope) executor {
+	if len(s.envs) > 0 {
+		e.outer = s.envs[0]
+	}
+
+	return e
+}
+
+// from returns the source of query, derived table is evaluated into temporary table.
+func (e executor) from(query rel.Query) (*source, error) {
+	if query.FromQuery == nil {
+		return e.source(query.Table), nil
+	}
+
+	fields, rows, err := e.query(query.FromQuery.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	t := newTable(query.Table)
+	t.append(fields, rows)
+
+	return &source{alias: query.Table, table: t}, nil
+}
+
+func (e executor) source(name string) *source {
+	name, alias := parseSource(name)
+	if t, ok := e.ctes[name]; ok {
+		return &source{alias: alias, table: t}
+	}
+
+	t, ok := e.db.tables[name]
+	if !ok {
+		t = newTable(name)
+	}
+
+	return &source{alias: alias, table: t}
+}
+
+// with evaluates common table expressions into temporary tables that are visible to the query and its sub queries.
+func (e executor) with(withs []rel.WithQuery) (executor, error) {
+	ctes := make(map[string]*table, len(e.ctes)+len(withs))
+	for name, t := range e.ctes {
+		ctes[name] = t
+	}
+
+	e.ctes = ctes
+
+	for _, wq := range withs {
+		fields, rows, err := e.query(wq.Query)
+		if err != nil {
+			return e, err
+		}
+
+		result := newTable(wq.Name)
+		result.append(fields, rows)
+
+		for i, working := 0, rows; wq.Recursive && len(working) > 0; i++ {
+			if i >= maxRecursion {
+				return e, fmt.Errorf("memadapter: recursive query %s exceeds maximum iteration", wq.Name)
+			}
+
+			// recursive query only sees rows produced by the previous iteration.
+			e.ctes[wq.Name] = newTable(wq.Name)
+			e.ctes[wq.Name].append(fields, working)
+
+			if _, working, err = e.query(wq.RecursiveQuery); err != nil {
+				return e, err
+			}
+
+			if len(working) > 0 && len(working[0]) != len(fields) {
+				return e, fmt.Errorf("memadapter: recursive query %s returns different number of columns", wq.Name)
+			}
+
+			result.append(fields, working)
+		}
+
+		e.ctes[wq.Name] = result
+	}
+
+	return e, nil
+}
+
+func (e executor) rows(src *source) []env {
+	envs := make([]env, len(src.table.rows))
+	for i := range src.table.rows {
+		envs[i] = env{{source: src, id: src.table.rows[i].id, values: src.table.rows[i].values}}
+	}
+
+	return envs
+}
+
+func (e executor) query(query rel.Query) ([]string, [][]interface{}, error) {
+	if query.SQLQuery.Statement != "" {
+		return nil, nil, errors.New("memadapter: raw sql query is not supported")
+	}
+
+	if len(query.WithQuery) > 0 {
+		var err error
+		if e, err = e.with(query.WithQuery); err != nil {
+			return nil, nil, err
+		}
+
+		query.WithQuery = nil
+	}
+
+	if len(query.CombineQuery) > 0 {
+		return e.combine(query)
+	}
+
+	from, err := e.from(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		sources = []*source{from}
+		envs    = e.rows(from)
+	)
+
+	for _, jq := range query.JoinQuery {
+		var joined *source
+		if envs, joined, err = e.join(envs, sources, jq); err != nil {
+			return nil, nil, err
+		}
+
+		sources = append(sources, joined)
+	}
+
+	if len(e.outer) > 0 {
+		for i := range envs {
+			envs[i] = append(envs[i][:len(envs[i]):len(envs[i])], e.outer...)
+		}
+	}
+
+	if envs, err = e.filter(envs, query.WhereQuery); err != nil {
+		return nil, nil, err
+	}
+
+	if query.RowLockQuery != nil && e.db.parent != nil {
+		if envs, err = e.lock(query, sources, envs); err != nil {
+			return nil, nil, err
+		}
+
+		// only locked rows are returned, so sort, offset and limit are already applied.
+		query.SortQuery, query.OffsetQuery, query.LimitQuery = nil, 0, 0
+	}
+
+	return e.project(query, sources, envs)
+}
+
+// combinedTable is the name of temporary table that holds combined result.
+const combinedTable = "\x00combined"
+
+// combine result of query with combined queries, then applies sort, offset and limit to the combined result.
+func (e executor) combine(query rel.Query) ([]string, [][]interface{}, error) {
+	base := query
+	base.CombineQuery, base.SortQuery, base.OffsetQuery, base.LimitQuery = nil, nil, 0, 0
+
+	fields, rows, err := e.query(base)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, cq := range query.CombineQuery {
+		_, other, err := e.query(cq.Query)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(other) > 0 && len(other[0]) != len(fields) {
+			return nil, nil, errors.New("memadapter: combined queries must return the same number of columns")
+		}
+
+		rows = combineRows(cq.Op, rows, other)
+	}
+
+	result := newTable(combinedTable)
+	result.append(fields, rows)
+
+	e, _ = e.with(nil)
+	e.ctes[combinedTable] = result
+
+	return e.query(rel.Query{
+		Table:       combinedTable,
+		SortQuery:   query.SortQuery,
+		OffsetQuery: query.OffsetQuery,
+		LimitQuery:  query.LimitQuery,
+	})
+}
+
+func combineRows(op rel.CombineOp, rows [][]interface{}, other [][]interface{}) [][]interface{} {
+	var (
+		result [][]interface{}
+		keys   = make(map[string]struct{}, len(other))
+		seen   = make(map[string]struct{}, len(rows))
+	)
+
+	if op == rel.CombineUnionAllOp {
+		return append(rows, other...)
+	}
+
+	for _, row := range other {
+		keys[fmt.Sprintf("%#v", row)] = struct{}{}
+	}
+
+	for _, row := range rows {
+		key := fmt.Sprintf("%#v", row)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		_, exists := keys[key]
+		if (op == rel.CombineIntersectOp && !exists) || (op == rel.CombineExceptOp && exists) {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		result = append(result, row)
+	}
+
+	if op == rel.CombineUnionOp {
+		for _, row := range other {
+			key := fmt.Sprintf("%#v", row)
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				result = append(result, row)
+			}
+		}
+	}
+
+	return result
+}
+
+func (e executor) join(envs []env, sources []*source, jq rel.JoinQuery) ([]env, *source, error) {
+	if jq.Arguments != nil {
+		return nil, nil, errors.New("memadapter: join fragment is not supported")
+	}
+
+	var (
+		mode   = strings.ToUpper(jq.Mode)
+		src    = e.source(jq.Table)
+		left   = strings.Contains(mode, "LEFT") || strings.Contains(mode, "FULL")
+		right  = strings.Contains(mode, "RIGHT") || strings.Contains(mode, "FULL")
+		filter = jq.Filter
+		result []env
+	)
+
+	if jq.From != "" || jq.To != "" {
+		filter = rel.FilterQuery{Type: rel.FilterEqOp, Field: jq.From, Value: rel.Ref(jq.To)}
+		if !jq.Filter.None() {
+			filter = filter.And(jq.Filter)
+		}
+	}
+
+	var (
+		rows    = src.table.rows
+		matched = make([]bool, len(rows))
+	)
+
+	for _, l := range envs {
+		found := false
+		for i := range rows {
+			combined := append(append(env{}, l...), binding{source: src, id: rows[i].id, values: rows[i].values})
+			ok, err := e.match(filter, scope{envs: []env{combined}})
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if ok {
+				found = true
+				matched[i] = true
+				result = append(result, combined)
+			}
+		}
+
+		if !found && left {
+			result = append(result, append(append(env{}, l...), binding{source: src}))
+		}
+	}
+
+	if right {
+		for i := range rows {
+			if matched[i] {
+				continue
+			}
+
+			combined := make(env, 0, len(sources)+1)
+			for _, s := range sources {
+				combined = append(combined, binding{source: s})
+			}
+
+			result = append(result, append(combined, binding{source: src, id: rows[i].id, values: rows[i].values}))
+		}
+	}
+
+	return result, src, nil
+}
+
+func (e executor) filter(envs []env, filter rel.FilterQuery) ([]env, error) {
+	if filter.None() {
+		return envs, nil
+	}
+
+	result := envs[:0:0]
+	for _, en := range envs {
+		ok, err := e.match(filter, scope{envs: []env{en}})
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			result = append(result, en)
+		}
+	}
+
+	return result, nil
+}
+
+func (e executor) project(query rel.Query, sources []*source, envs []env) ([]string, [][]interface{}, error) {
+	var (
+		fields      = query.SelectQuery.Fields
+		exprs       []expression
+		columns     []string
+		grouped     = len(query.GroupQuery.Fields) > 0
+		groups      [][]env
+		rows        [][]interface{}
+		scopes      []scope
+		starSources [][]*source
+		windows     []windowColumn
+	)
+
+	if fields == nil {
+		fields = []string{"*"}
+	}
+
+	for _, field := range fields {
+		expr := parseExpression(field)
+		if expr.function != "" && !expr.aggregate() {
+			return nil, nil, fmt.Errorf("memadapter: unsupported function %s", expr.function)
+		}
+
+		grouped = grouped || expr.aggregate()
+		exprs = append(exprs, expr)
+
+		var matched []*source
+		if expr.star {
+			for _, src := range sources {
+				if expr.table == "" || expr.table == src.alias {
+					matched = append(matched, src)
+					columns = append(columns, src.table.columnNames()...)
+				}
+			}
+		} else {
+			columns = append(columns, expr.name)
+		}
+
+		starSources = append(starSources, matched)
+	}
+
+	for _, se := range query.SelectQuery.Expressions {
+		expr, err := selectExpression(se)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if expr.window {
+			windows = append(windows, windowColumn{expr: se, index: len(columns)})
+		} else {
+			grouped = grouped || expr.aggregate()
+		}
+
+		exprs = append(exprs, expr)
+		columns = append(columns, expr.name)
+		starSources = append(starSources, nil)
+	}
+
+	for i := range query.SelectQuery.SubQueries {
+		sub := query.SelectQuery.SubQueries[i]
+		if sub.Alias == "" {
+			return nil, nil, errors.New("memadapter: selected sub query requires alias")
+		}
+
+		exprs = append(exprs, expression{name: sub.Alias, query: &sub.Query})
+		columns = append(columns, sub.Alias)
+		starSources = append(starSources, nil)
+	}
+
+	switch {
+	case len(query.GroupQuery.Fields) > 0:
+		var index = make(map[string]int)
+		for _, en := range envs {
+			key := make([]interface{}, len(query.GroupQuery.Fields))
+			for i, field := range query.GroupQuery.Fields {
+				key[i], _ = en.lookup(field)
+			}
+
+			k := fmt.Sprintf("%#v", key)
+			if i, ok := index[k]; ok {
+				groups[i] = append(groups[i], en)
+			} else {
+				index[k] = len(groups)
+				groups = append(groups, []env{en})
+			}
+		}
+	case grouped:
+		groups = [][]env{envs}
+	default:
+		groups = make([][]env, len(envs))
+		for i := range envs {
+			groups[i] = envs[i : i+1]
+		}
+	}
+
+	for _, group := range groups {
+		var (
+			s   = scope{envs: group, output: make(map[string]interface{}, len(columns))}
+			row = make([]interface{}, 0, len(columns))
+		)
+
+		for i, expr := range exprs {
+			if expr.star {
+				for _, src := range starSources[i] {
+					var values map[string]interface{}
+					if len(group) > 0 {
+						for _, b := range group[0] {
+							if b.source == src {
+								values = b.values
+							}
+						}
+					}
+
+					for _, col := range src.table.columns {
+						row = append(row, values[col.name])
+					}
+				}
+
+				continue
+			}
+
+			if expr.window {
+				row = append(row, nil)
+				continue
+			}
+
+			value, err := e.eval(expr, s)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			s.output[expr.name] = value
+			row = append(row, value)
+		}
+
+		if !query.GroupQuery.Filter.None() {
+			ok, err := e.match(query.GroupQuery.Filter, s)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if !ok {
+				continue
+			}
+		}
+
+		rows = append(rows, row)
+		scopes = append(scopes, s)
+	}
+
+	for _, w := range windows {
+		if err := e.window(w, rows, scopes); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if query.SelectQuery.OnlyDistinct {
+		rows, scopes = distinct(rows, scopes)
+	}
+
+	if len(query.SortQuery) > 0 {
+		var (
+			keys    = make([][]interface{}, len(rows))
+			indexes = make([]int, len(rows))
+		)
+
+		for i := range rows {
+			indexes[i] = i
+			keys[i] = make([]interface{}, len(query.SortQuery))
+			for j, sq := range query.SortQuery {
+				value, err := e.eval(parseExpression(sq.Field), scopes[i])
+				if err != nil {
+					return nil, nil, err
+				}
+
+				keys[i][j] = value
+			}
+		}
+
+		sort.SliceStable(indexes, func(a, b int) bool {
+			for j, sq := range query.SortQuery {
+				c := order(keys[indexes[a]][j], keys[indexes[b]][j])
+				if sq.Desc() {
+					c = -c
+				}
+
+				if c != 0 {
+					return c < 0
+				}
+			}
+
+			return false
+		})
+
+		sorted := make([][]interface{}, len(rows))
+		for i, index := range indexes {
+			sorted[i] = rows[index]
+		}
+
+		rows = sorted
+	}
+
+	if offset := int(query.OffsetQuery); offset > 0 {
+		if offset > len(rows) {
+			offset = len(rows)
+		}
+
+		rows = rows[offset:]
+	}
+
+	if limit := int(query.LimitQuery); limit > 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+
+	return columns, rows, nil
+}
+
+// distinct removes duplicate rows along with their scopes.
+func distinct(rows [][]interface{}, scopes []scope) ([][]interface{}, []scope) {
+	var (
+		seen         = make(map[string]struct{}, len(rows))
+		resultRows   = rows[:0:0]
+		resultScopes = scopes[:0:0]
+	)
+
+	for i, row := range rows {
+		key := fmt.Sprintf("%#v", row)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		resultRows = append(resultRows, row)
+		resultScopes = append(resultScopes, scopes[i])
+	}
+
+	return resultRows, resultScopes
+}
+
+func (e executor) eval(expr expression, s scope) (interface{}, error) {
+	if expr.query != nil {
+		return e.value(*expr.query, s)
+	}
+
+	if expr.function != "" && !expr.aggregate() {
+		return nil, fmt.Errorf("memadapter: unsupported function %s", expr.function)
+	}
+
+	if expr.literal != nil {
+		return expr.literal, nil
+	}
+
+	if !expr.aggregate() {
+		return s.lookup(expr.field), nil
+	}
+
+	var (
+		values []interface{}
+		seen   map[string]struct{}
+	)
+
+	for _, en := range s.envs {
+		if expr.field == "*" {
+			values = append(values, true)
+			continue
+		}
+
+		value, _ := en.lookup(expr.field)
+		if value == nil {
+			continue
+		}
+
+		if expr.distinct {
+			if seen == nil {
+				seen = make(map[string]struct{})
+			}
+
+			key := fmt.Sprintf("%#v", value)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+
+			seen[key] = struct{}{}
+		}
+
+		values = append(values, value)
+	}
+
+	return aggregate(expr.function, values)
+}
+
+func aggregate(function string, values []interface{}) (interface{}, error) {
+	switch function {
+	case "count":
+		return int64(len(values)), nil
+	case "sum", "avg":
+		if len(values) == 0 {
+			return nil, nil
+		}
+
+		var (
+			isum    int64
+			fsum    float64
+			isFloat bool
+		)
+
+		for _, value := range values {
+			switch v := value.(type) {
+			case int64:
+				isum += v
+				fsum += float64(v)
+			case float64:
+				fsum += v
+				isFloat = true
+			default:
+				return nil, fmt.Errorf("memadapter: cannot %s non numeric value %v", function, value)
+			}
+		}
+
+		if function == "avg" {
+			return fsum / float64(len(values)), nil
+		}
+
+		if isFloat {
+			return fsum, nil
+		}
+
+		return isum, nil
+	default:
+		var result interface{}
+		for _, value := range values {
+			c := order(value, result)
+			if result == nil || (function == "min" && c < 0) || (function == "max" && c > 0) {
+				result = value
+			}
+		}
+
+		return result, nil
+	}
+}
+
+func (e executor) value(value interface{}, s scope) (interface{}, error) {
+	switch v := value.(type) {
+	case rel.Ref:
+		return s.lookup(string(v)), nil
+	case rel.Query:
+		_, rows, err := e.correlate(s).query(v)
+		if err != nil || len(rows) == 0 || len(rows[0]) == 0 {
+			return nil, err
+		}
+
+		return rows[0][0], nil
+	default:
+		return normalize(v)
+	}
+}
+
+func (e executor) values(query rel.Query) ([]interface{}, error) {
+	_, rows, err := e.query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, 0, len(rows))
+	for _, row := range rows {
+		if len(row) > 0 {
+			values = append(values, row[0])
+		}
+	}
+
+	return values, nil
+}
+
+// match evaluates filter using three-valued logic, unknown result is treated as false.
+func (e executor) match(filter rel.FilterQuery, s scope) (bool, error) {
+	result, known, err := e.test(filter, s)
+	return result && known, err
+}
+
+func (e executor) test(filter rel.FilterQuery, s scope) (bool, bool, error) {
+	switch filter.Type {
+	case rel.FilterAndOp:
+		var unknown bool
+		for _, inner := range filter.Inner {
+			result, known, err := e.test(inner, s)
+			if err != nil {
+				return false, false, err
+			}
+
+			if known && !result {
+				return false, true, nil
+			}
+
+			unknown = unknown || !known
+		}
+
+		return !unknown, !unknown, nil
+	case rel.FilterOrOp:
+		var unknown bool
+		for _, inner := range filter.Inner {
+			result, known, err := e.test(inner, s)
+			if err != nil {
+				return false, false, err
+			}
+
+			if known && result {
+				return true, true, nil
+			}
+
+			unknown = unknown || !known
+		}
+
+		return false, !unknown, nil
+	case rel.FilterNotOp:
+		result, known, err := e.test(rel.And(filter.Inner...), s)
+		return !result, known, err
+	case rel.FilterFragmentOp:
+		return false, false, errors.New("memadapter: filter fragment is not supported")
+	case rel.FilterExistsOp, rel.FilterNotExistsOp:
+		sub, _ := filter.Value.(rel.Query)
+		_, rows, err := e.correlate(s).query(sub.Limit(1))
+		if err != nil {
+			return false, false, err
+		}
+
+		return (len(rows) > 0) == (filter.Type == rel.FilterExistsOp), true, nil
+	}
+
+	field, err := e.eval(parseExpression(filter.Field), s)
+	if err != nil {
+		return false, false, err
+	}
+
+	switch filter.Type {
+	case rel.FilterNilOp:
+		return field == nil, true, nil
+	case rel.FilterNotNilOp:
+		return field != nil, true, nil
+	case rel.FilterLikeOp, rel.FilterNotLikeOp:
+		if field == nil {
+			return false, false, nil
+		}
+
+		pattern, _ := filter.Value.(string)
+		return like(field, pattern) == (filter.Type == rel.FilterLikeOp), true, nil
+	case rel.FilterInOp, rel.FilterNinOp:
+		return e.testIn(field, filter, s)
+	}
+
+	if sub, ok := filter.Value.(rel.SubQuery); ok {
+		return e.correlate(s).testSubQuery(field, filter.Type, sub)
+	}
+
+	value, err := e.value(filter.Value, s)
+	if err != nil {
+		return false, false, err
+	}
+
+	result, known := compareOp(field, value, filter.Type)
+	return result, known, nil
+}
+
+func compareOp(field interface{}, value interface{}, op rel.FilterOp) (bool, bool) {
+	c, ok := compare(field, value)
+	if !ok {
+		return false, false
+	}
+
+	switch op {
+	case rel.FilterEqOp:
+		return c == 0, true
+	case rel.FilterNeOp:
+		return c != 0, true
+	case rel.FilterLtOp:
+		return c < 0, true
+	case rel.FilterLteOp:
+		return c <= 0, true
+	case rel.FilterGtOp:
+		return c > 0, true
+	case rel.FilterGteOp:
+		return c >= 0, true
+	}
+
+	return false, false
+}
+
+func (e executor) testIn(field interface{}, filter rel.FilterQuery, s scope) (bool, bool, error) {
+	var (
+		values, _ = filter.Value.([]interface{})
+		in        = filter.Type == rel.FilterInOp
+		unknown   = field == nil
+	)
+
+	if len(values) == 1 {
+		if sub, ok := values[0].(rel.Query); ok {
+			var err error
+			if values, err = e.correlate(s).values(sub); err != nil {
+				return false, false, err
+			}
+		}
+	}
+
+	for _, v := range values {
+		value, err := e.value(v, s)
+		if err != nil {
+			return false, false, err
+		}
+
+		if value == nil {
+			unknown = true
+		} else if equal(field, value) {
+			return in, true, nil
+		}
+	}
+
+	if unknown {
+		return false, false, nil
+	}
+
+	return !in, true, nil
+}
+
+func (e executor) testSubQuery(field interface{}, op rel.FilterOp, sub rel.SubQuery) (bool, bool, error) {
+	values, err := e.values(sub.Query)
+	if err != nil {
+		return false, false, err
+	}
+
+	var (
+		any     = strings.EqualFold(sub.Prefix, "ANY")
+		unknown bool
+	)
+
+	for _, value := range values {
+		result, known := compareOp(field, value, op)
+		switch {
+		case !known:
+			unknown = true
+		case any && result:
+			return true, true, nil
+		case !any && !result:
+			return false, true, nil
+		}
+	}
+
+	if unknown {
+		return false, false, nil
+	}
+
+	return !any, true, nil
+}
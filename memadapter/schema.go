@@ -0,0 +1,310 @@
+package memadapter
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-rel/rel"
+)
+
+func (db *database) applyTable(table rel.Table) error {
+	switch table.Op {
+	case rel.SchemaCreate:
+		if _, exists := db.tables[table.Name]; exists {
+			if table.Optional {
+				return nil
+			}
+
+			return fmt.Errorf("memadapter: table %s already exists", table.Name)
+		}
+
+		t := newTable(table.Name)
+		if err := db.applyDefinitions(t, table.Definitions); err != nil {
+			return err
+		}
+
+		db.tables[table.Name] = t
+		db.changeSchema(table.Name)
+	case rel.SchemaAlter:
+		t, ok := db.tables[table.Name]
+		if !ok {
+			return fmt.Errorf("memadapter: table %s does not exist", table.Name)
+		}
+
+		t = t.clone()
+		if err := db.applyDefinitions(t, table.Definitions); err != nil {
+			return err
+		}
+
+		db.tables[table.Name] = t
+		db.changeSchema(table.Name)
+	case rel.SchemaRename:
+		t, ok := db.tables[table.Name]
+		if !ok {
+			return fmt.Errorf("memadapter: table %s does not exist", table.Name)
+		}
+
+		if _, exists := db.tables[table.Rename]; exists {
+			return fmt.Errorf("memadapter: table %s already exists", table.Rename)
+		}
+
+		t = t.clone()
+		t.name = table.Rename
+		delete(db.tables, table.Name)
+		db.tables[table.Rename] = t
+		db.changeSchema(table.Name)
+		db.changeSchema(table.Rename)
+		db.seq.rename(table.Name, table.Rename)
+	case rel.SchemaDrop:
+		if _, ok := db.tables[table.Name]; !ok {
+			if table.Optional {
+				return nil
+			}
+
+			return fmt.Errorf("memadapter: table %s does not exist", table.Name)
+		}
+
+		delete(db.tables, table.Name)
+		db.changeSchema(table.Name)
+	}
+
+	return nil
+}
+
+func (db *database) applyDefinitions(t *table, definitions []rel.TableDefinition) error {
+	for _, definition := range definitions {
+		switch v := definition.(type) {
+		case rel.Column:
+			if err := db.applyColumn(t, v); err != nil {
+				return err
+			}
+		case rel.Key:
+			if err := db.applyKey(t, v); err != nil {
+				return err
+			}
+		default:
+			return errors.New("memadapter: unsupported table definition")
+		}
+	}
+
+	return nil
+}
+
+func (db *database) applyColumn(t *table, col rel.Column) error {
+	switch col.Op {
+	case rel.SchemaCreate:
+		if _, exists := t.column(col.Name); exists {
+			return fmt.Errorf("memadapter: column %s already exists", col.Name)
+		}
+
+		def, err := normalize(col.Default)
+		if err != nil {
+			return err
+		}
+
+		t.addColumn(column{
+			name:          col.Name,
+			primary:       col.Primary,
+			unique:        col.Unique,
+			required:      col.Required || col.Primary,
+			autoIncrement: col.Type == rel.ID || col.Type == rel.BigID,
+			def:           def,
+		})
+
+		if col.Primary {
+			t.uniques = append(t.uniques, primaryKey(t.name, []string{col.Name}))
+		} else if col.Unique {
+			t.uniques = append(t.uniques, uniqueKey{
+				name:    t.name + "_" + col.Name + "_key",
+				typ:     rel.UniqueConstraint,
+				columns: []string{col.Name},
+			})
+		}
+
+		for i := range t.rows {
+			r := t.rows[i].clone()
+			r.values[col.Name] = def
+			t.rows[i] = r
+		}
+	case rel.SchemaRename:
+		c, ok := t.column(col.Name)
+		if !ok {
+			return fmt.Errorf("memadapter: column %s does not exist", col.Name)
+		}
+
+		c.name = col.Rename
+		for i := range t.uniques {
+			t.uniques[i].columns = renameColumn(t.uniques[i].columns, col.Name, col.Rename)
+		}
+
+		for i := range t.foreignKeys {
+			if t.foreignKeys[i].column == col.Name {
+				t.foreignKeys[i].column = col.Rename
+			}
+		}
+
+		for i := range t.rows {
+			r := t.rows[i].clone()
+			r.values[col.Rename] = r.values[col.Name]
+			delete(r.values, col.Name)
+			t.rows[i] = r
+		}
+	case rel.SchemaDrop:
+		if _, ok := t.column(col.Name); !ok {
+			return fmt.Errorf("memadapter: column %s does not exist", col.Name)
+		}
+
+		columns := t.columns[:0:0]
+		for _, c := range t.columns {
+			if c.name != col.Name {
+				columns = append(columns, c)
+			}
+		}
+
+		t.columns = columns
+
+		for i := range t.rows {
+			r := t.rows[i].clone()
+			delete(r.values, col.Name)
+			t.rows[i] = r
+		}
+	default:
+		return errors.New("memadapter: unsupported column operation")
+	}
+
+	return nil
+}
+
+func (db *database) applyKey(t *table, key rel.Key) error {
+	if key.Op != rel.SchemaCreate {
+		return errors.New("memadapter: unsupported key operation")
+	}
+
+	switch key.Type {
+	case rel.PrimaryKey:
+		for _, name := range key.Columns {
+			if c, ok := t.column(name); ok {
+				c.primary = true
+				c.required = true
+			}
+		}
+
+		t.uniques = append(t.uniques, primaryKey(t.name, key.Columns))
+	case rel.UniqueKey:
+		name := key.Name
+		if name == "" {
+			name = t.name + "_" + joinColumns(key.Columns) + "_key"
+		}
+
+		t.uniques = append(t.uniques, uniqueKey{
+			name:    name,
+			typ:     rel.UniqueConstraint,
+			columns: key.Columns,
+		})
+	case rel.ForeignKey:
+		name := key.Name
+		if name == "" {
+			name = t.name + "_" + joinColumns(key.Columns) + "_fkey"
+		}
+
+		t.foreignKeys = append(t.foreignKeys, foreignKey{
+			name:      name,
+			column:    key.Columns[0],
+			refTable:  key.Reference.Table,
+			refColumn: key.Reference.Columns[0],
+			onDelete:  key.Reference.OnDelete,
+		})
+	}
+
+	return nil
+}
+
+func (db *database) applyIndex(index rel.Index) error {
+	t, ok := db.tables[index.Table]
+	if !ok {
+		return fmt.Errorf("memadapter: table %s does not exist", index.Table)
+	}
+
+	t = t.clone()
+
+	switch index.Op {
+	case rel.SchemaCreate:
+		if _, exists := t.indexes[index.Name]; exists {
+			if index.Optional {
+				return nil
+			}
+
+			return fmt.Errorf("memadapter: index %s already exists", index.Name)
+		}
+
+		t.indexes[index.Name] = index.Name
+		if index.Unique {
+			uk := uniqueKey{
+				name:    index.Name,
+				typ:     rel.UniqueConstraint,
+				columns: index.Columns,
+				filter:  index.Filter,
+			}
+
+			for i := range t.rows {
+				if _, err := db.checkUniqueKey(t, uk, t.rows[i]); err != nil {
+					return err
+				}
+			}
+
+			t.uniques = append(t.uniques, uk)
+		}
+	case rel.SchemaDrop:
+		if _, exists := t.indexes[index.Name]; !exists {
+			if index.Optional {
+				return nil
+			}
+
+			return fmt.Errorf("memadapter: index %s does not exist", index.Name)
+		}
+
+		delete(t.indexes, index.Name)
+
+		uniques := t.uniques[:0:0]
+		for _, uk := range t.uniques {
+			if uk.name != index.Name {
+				uniques = append(uniques, uk)
+			}
+		}
+
+		t.uniques = uniques
+	default:
+		return errors.New("memadapter: unsupported index operation")
+	}
+
+	db.tables[index.Table] = t
+	db.changeSchema(index.Table)
+
+	return nil
+}
+
+func renameColumn(columns []string, name string, newName string) []string {
+	result := make([]string, len(columns))
+	for i := range columns {
+		if columns[i] == name {
+			result[i] = newName
+		} else {
+			result[i] = columns[i]
+		}
+	}
+
+	return result
+}
+
+func joinColumns(columns []string) string {
+	var result string
+	for i := range columns {
+		if i > 0 {
+			result += "_"
+		}
+
+		result += columns[i]
+	}
+
+	return result
+}
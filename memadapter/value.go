@@ -0,0 +1,204 @@
+package memadapter
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-rel/rel"
+)
+
+// normalize value to one of the type supported by database/sql driver.
+// this allows custom types (eg: type Status string) to be compared with its underlying type.
+func normalize(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	return driver.DefaultParameterConverter.ConvertValue(value)
+}
+
+// compare two normalized values.
+// second return value will be false if values is not comparable, this includes comparison with nil.
+func compare(a, b interface{}) (int, bool) {
+	if a == nil || b == nil {
+		return 0, false
+	}
+
+	switch av := a.(type) {
+	case int64:
+		switch bv := b.(type) {
+		case int64:
+			return compareInt64(av, bv), true
+		case float64:
+			return compareFloat64(float64(av), bv), true
+		}
+	case float64:
+		switch bv := b.(type) {
+		case int64:
+			return compareFloat64(av, float64(bv)), true
+		case float64:
+			return compareFloat64(av, bv), true
+		}
+	case string:
+		switch bv := b.(type) {
+		case string:
+			return strings.Compare(av, bv), true
+		case []byte:
+			return strings.Compare(av, string(bv)), true
+		}
+	case []byte:
+		switch bv := b.(type) {
+		case string:
+			return bytes.Compare(av, []byte(bv)), true
+		case []byte:
+			return bytes.Compare(av, bv), true
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			switch {
+			case av == bv:
+				return 0, true
+			case bv:
+				return -1, true
+			default:
+				return 1, true
+			}
+		}
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			switch {
+			case av.Equal(bv):
+				return 0, true
+			case av.Before(bv):
+				return -1, true
+			default:
+				return 1, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// order compares two values for sorting, nil is always sorted first.
+func order(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	c, _ := compare(a, b)
+	return c
+}
+
+func equal(a, b interface{}) bool {
+	c, ok := compare(a, b)
+	return ok && c == 0
+}
+
+func like(value interface{}, pattern string) bool {
+	var str string
+	switch v := value.(type) {
+	case string:
+		str = v
+	case []byte:
+		str = string(v)
+	default:
+		return false
+	}
+
+	var builder strings.Builder
+	builder.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			builder.WriteString(".*")
+		case '_':
+			builder.WriteString(".")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	builder.WriteString("$")
+
+	matched, _ := regexp.MatchString(builder.String(), str)
+	return matched
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+
+	return 0, false
+}
+
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), true
+	}
+
+	return 0, false
+}
+
+// scan value into dest, dest is expected to be created by rel.Document.Scanners.
+func scan(dest interface{}, value interface{}) error {
+	if s, ok := dest.(sql.Scanner); ok {
+		return s.Scan(value)
+	}
+
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Ptr {
+		if value == nil {
+			rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+			return nil
+		}
+
+		ptr := reflect.New(rv.Elem().Type().Elem())
+		if err := scan(ptr.Interface(), value); err != nil {
+			return err
+		}
+
+		rv.Elem().Set(ptr)
+		return nil
+	}
+
+	return rel.Nullable(dest).(sql.Scanner).Scan(value)
+}
@@ -0,0 +1,204 @@
+package memadapter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-rel/rel"
+)
+
+// windowColumn is a window function and the index of its result column.
+type windowColumn struct {
+	expr  rel.SelectExpr
+	index int
+}
+
+// selectExpression converts structured select expression, only aggregate and window function are supported.
+func selectExpression(se rel.SelectExpr) (expression, error) {
+	expr := expression{
+		name:     se.Alias,
+		function: strings.ToLower(se.Func),
+		distinct: se.Distinct,
+		window:   se.Window != nil,
+	}
+
+	if expr.name == "" {
+		expr.name = expr.function
+	}
+
+	if expr.window {
+		switch expr.function {
+		case "row_number", "rank", "dense_rank", "lag", "lead":
+			return expr, nil
+		}
+	}
+
+	if !expr.aggregate() {
+		return expr, fmt.Errorf("memadapter: unsupported function %s", se.Func)
+	}
+
+	switch len(se.Arguments) {
+	case 0:
+		expr.field = "*"
+	case 1:
+		field, ok := se.Arguments[0].(string)
+		if !ok {
+			return expr, fmt.Errorf("memadapter: unsupported argument of function %s", se.Func)
+		}
+
+		expr.field = field
+	default:
+		return expr, fmt.Errorf("memadapter: function %s expects a single argument", se.Func)
+	}
+
+	return expr, nil
+}
+
+// window evaluates window function for every row, the result is written to the row and its scope.
+func (e executor) window(w windowColumn, rows [][]interface{}, scopes []scope) error {
+	var (
+		window     = w.expr.Window
+		expr, _    = selectExpression(w.expr)
+		partitions [][]int
+		index      = make(map[string]int)
+		keys       = make([][]interface{}, len(rows))
+	)
+
+	for i := range rows {
+		key := make([]interface{}, len(window.Partition))
+		for j, field := range window.Partition {
+			key[j] = scopes[i].lookup(field)
+		}
+
+		k := fmt.Sprintf("%#v", key)
+		if p, ok := index[k]; ok {
+			partitions[p] = append(partitions[p], i)
+		} else {
+			index[k] = len(partitions)
+			partitions = append(partitions, []int{i})
+		}
+
+		keys[i] = make([]interface{}, len(window.Sort))
+		for j, sq := range window.Sort {
+			keys[i][j] = scopes[i].lookup(sq.Field)
+		}
+	}
+
+	compareKeys := func(a, b int) int {
+		for j, sq := range window.Sort {
+			c := order(keys[a][j], keys[b][j])
+			if sq.Desc() {
+				c = -c
+			}
+
+			if c != 0 {
+				return c
+			}
+		}
+
+		return 0
+	}
+
+	for _, partition := range partitions {
+		sort.SliceStable(partition, func(a, b int) bool {
+			return compareKeys(partition[a], partition[b]) < 0
+		})
+
+		var rank, denseRank int64
+		for pos, i := range partition {
+			var (
+				value interface{}
+				err   error
+			)
+
+			if pos == 0 || compareKeys(partition[pos-1], i) != 0 {
+				rank = int64(pos + 1)
+				denseRank++
+			}
+
+			switch expr.function {
+			case "row_number":
+				value = int64(pos + 1)
+			case "rank":
+				value = rank
+			case "dense_rank":
+				value = denseRank
+			case "lag", "lead":
+				value, err = e.offset(w.expr, partition, pos, scopes)
+			default:
+				// frame ends at the last peer of current row when window is sorted, otherwise it's the whole partition.
+				end := len(partition)
+				if len(window.Sort) > 0 {
+					end = pos + 1
+					for end < len(partition) && compareKeys(partition[end], i) == 0 {
+						end++
+					}
+				}
+
+				frame := make([]env, 0, end)
+				for _, j := range partition[:end] {
+					frame = append(frame, scopes[j].envs...)
+				}
+
+				value, err = e.eval(expr, scope{envs: frame})
+			}
+
+			if err != nil {
+				return err
+			}
+
+			rows[i][w.index] = value
+			scopes[i].output[expr.name] = value
+		}
+	}
+
+	return nil
+}
+
+// offset evaluates lag and lead function, arguments are field, offset (defaults to 1) and default value.
+func (e executor) offset(se rel.SelectExpr, partition []int, pos int, scopes []scope) (interface{}, error) {
+	var (
+		field  string
+		offset = int64(1)
+		def    interface{}
+		ok     bool
+	)
+
+	if len(se.Arguments) == 0 || len(se.Arguments) > 3 {
+		return nil, fmt.Errorf("memadapter: function %s expects field, offset and default value as arguments", se.Func)
+	}
+
+	if field, ok = se.Arguments[0].(string); !ok {
+		return nil, fmt.Errorf("memadapter: unsupported argument of function %s", se.Func)
+	}
+
+	if len(se.Arguments) > 1 {
+		value, err := normalize(se.Arguments[1])
+		if err != nil {
+			return nil, err
+		}
+
+		if offset, ok = value.(int64); !ok {
+			return nil, fmt.Errorf("memadapter: offset of function %s must be an integer", se.Func)
+		}
+	}
+
+	if len(se.Arguments) > 2 {
+		var err error
+		if def, err = normalize(se.Arguments[2]); err != nil {
+			return nil, err
+		}
+	}
+
+	if strings.EqualFold(se.Func, "lag") {
+		offset = -offset
+	}
+
+	target := pos + int(offset)
+	if target < 0 || target >= len(partition) {
+		return def, nil
+	}
+
+	return scopes[partition[target]].lookup(field), nil
+}
@@ -0,0 +1,490 @@
+package rel
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidPageToken returned when page token is malformed, tampered, or it's created for different sort.
+	ErrInvalidPageToken = errors.New("rel: invalid page token")
+
+	// ErrPageSecretRequired returned when paginating without PageSecret option.
+	ErrPageSecretRequired = errors.New("rel: page secret is required to sign page token, use rel.PageSecret option")
+)
+
+// Page contains opaque tokens to fetch the adjacent pages of paginated records.
+// Token is empty when there's no adjacent page in that direction.
+type Page struct {
+	Next     string
+	Previous string
+}
+
+// HasNext returns true when there's a next page.
+func (p Page) HasNext() bool {
+	return p.Next != ""
+}
+
+// HasPrevious returns true when there's a previous page.
+func (p Page) HasPrevious() bool {
+	return p.Previous != ""
+}
+
+// PaginateOption is used to configure pagination, such as page size and page token.
+type PaginateOption interface {
+	applyPaginate(*paginator)
+}
+
+type after string
+
+func (a after) applyPaginate(p *paginator) {
+	p.token = string(a)
+	p.before = false
+}
+
+// String representation.
+func (a after) String() string {
+	return fmt.Sprintf("rel.After(%q)", string(a))
+}
+
+// After fetches the page after the page token, use Page.Next as the token.
+// Empty token fetches the first page.
+func After(token string) PaginateOption {
+	return after(token)
+}
+
+type before string
+
+func (b before) applyPaginate(p *paginator) {
+	p.token = string(b)
+	p.before = true
+}
+
+// String representation.
+func (b before) String() string {
+	return fmt.Sprintf("rel.Before(%q)", string(b))
+}
+
+// Before fetches the page before the page token, use Page.Previous as the token.
+func Before(token string) PaginateOption {
+	return before(token)
+}
+
+type pageSize int
+
+func (ps pageSize) applyPaginate(p *paginator) {
+	p.size = int(ps)
+}
+
+// String representation.
+func (ps pageSize) String() string {
+	return fmt.Sprintf("rel.PageSize(%d)", ps)
+}
+
+// PageSize specifies the number of records in a page.
+// Defaults to the limit of the query, or 20 if the query has no limit.
+func PageSize(size int) PaginateOption {
+	return pageSize(size)
+}
+
+type pageSecret []byte
+
+func (ps pageSecret) applyPaginate(p *paginator) {
+	p.secret = ps
+}
+
+// String representation.
+func (ps pageSecret) String() string {
+	return "rel.PageSecret(***)"
+}
+
+// PageSecret specifies the key used to sign page tokens, it's required to paginate.
+// The same secret must be used to create and to read the tokens.
+func PageSecret(secret []byte) PaginateOption {
+	return pageSecret(secret)
+}
+
+// NullsLast specifies that the database sorts null as a value larger than any non null value,
+// which is the default behaviour of PostgreSQL and Oracle.
+// Defaults to false, which means null is sorted as the smallest value like in MySQL and SQLite.
+// It can be used as both PaginateOption and IteratorOption.
+type NullsLast bool
+
+func (nl NullsLast) applyPaginate(p *paginator) {
+	p.nullsLast = bool(nl)
+}
+
+func (nl NullsLast) apply(i *iterator) {
+	i.keyset.nullsLast = bool(nl)
+}
+
+// String representation.
+func (nl NullsLast) String() string {
+	return fmt.Sprintf("rel.NullsLast(%t)", bool(nl))
+}
+
+type paginator struct {
+	token     string
+	before    bool
+	size      int
+	secret    []byte
+	nullsLast bool
+	notNull   map[string]bool
+}
+
+func newPaginator(query Query, options []PaginateOption) paginator {
+	p := paginator{size: int(query.LimitQuery)}
+	for i := range options {
+		options[i].applyPaginate(&p)
+	}
+
+	if p.size <= 0 {
+		p.size = 20
+	}
+
+	return p
+}
+
+// keys returns sort of the query with primary fields appended as the tie breaker.
+// Primary fields are recorded as not null, so filter doesn't need to check null for those keys.
+// Other fields are always treated as nullable, because null can be scanned into non pointer field as zero value.
+func (p *paginator) keys(query Query, primaryFields []string) []SortQuery {
+	var (
+		keys = append([]SortQuery(nil), query.SortQuery...)
+	)
+
+	for _, field := range primaryFields {
+		found := false
+		for _, key := range keys {
+			if key.Field == field || key.Field == query.Table+"."+field {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			keys = append(keys, SortAsc(field))
+		}
+	}
+
+	p.notNull = make(map[string]bool, len(primaryFields))
+	for _, field := range primaryFields {
+		p.notNull[field] = true
+		p.notNull[query.Table+"."+field] = true
+	}
+
+	return keys
+}
+
+// build query of the requested page, query with before token is sorted in reverse order.
+func (p paginator) build(query Query, keys []SortQuery) (Query, error) {
+	var (
+		values []interface{}
+		err    error
+	)
+
+	if p.token != "" {
+		if values, err = p.decode(keys); err != nil {
+			return query, err
+		}
+	}
+
+	if p.before {
+		keys = reverseSorts(keys)
+	}
+
+	query.SortQuery = keys
+	query.OffsetQuery = 0
+	query.LimitQuery = Limit(p.size + 1)
+
+	if p.token == "" {
+		return query, nil
+	}
+
+	filter, ok := p.filter(keys, values)
+	if !ok {
+		return query, ErrInvalidPageToken
+	}
+
+	return query.Where(filter), nil
+}
+
+// filter returns records that come after the values in the order of keys.
+// It returns false when nothing can come after the values, which only happens when primary value is null.
+func (p paginator) filter(keys []SortQuery, values []interface{}) (FilterQuery, bool) {
+	var (
+		equals []FilterQuery
+		inner  []FilterQuery
+	)
+
+	for i, key := range keys {
+		var (
+			value     = values[i]
+			nullFirst = key.Asc() != p.nullsLast
+			next      FilterQuery
+		)
+
+		switch {
+		case value == nil && nullFirst:
+			next = NotNil(key.Field)
+		case value == nil:
+			// nothing comes after null.
+		case key.Asc() && (nullFirst || p.notNull[key.Field]):
+			next = Gt(key.Field, value)
+		case key.Asc():
+			next = Or(Gt(key.Field, value), Nil(key.Field))
+		case nullFirst || p.notNull[key.Field]:
+			next = Lt(key.Field, value)
+		default:
+			next = Or(Lt(key.Field, value), Nil(key.Field))
+		}
+
+		if !next.None() {
+			inner = append(inner, And(append(append([]FilterQuery(nil), equals...), next)...))
+		}
+
+		if value == nil {
+			equals = append(equals, Nil(key.Field))
+		} else {
+			equals = append(equals, Eq(key.Field, value))
+		}
+	}
+
+	return Or(inner...), len(inner) > 0
+}
+
+// page trims the extra record used to detect adjacent page, and creates tokens of the adjacent pages.
+func (p paginator) page(col *Collection, keys []SortQuery) (Page, error) {
+	var (
+		page = Page{}
+		more = col.Len() > p.size
+	)
+
+	if more {
+		col.Truncate(0, p.size)
+	}
+
+	if p.before {
+		for i, j := 0, col.Len()-1; i < j; i, j = i+1, j-1 {
+			col.Swap(i, j)
+		}
+	}
+
+	if col.Len() == 0 {
+		return page, nil
+	}
+
+	var (
+		hasNext     = (!p.before && more) || (p.before && p.token != "")
+		hasPrevious = (p.before && more) || (!p.before && p.token != "")
+		err         error
+	)
+
+	if hasNext {
+		if page.Next, err = p.encode(col.Get(col.Len()-1), keys, false); err != nil {
+			return Page{}, err
+		}
+	}
+
+	if hasPrevious {
+		if page.Previous, err = p.encode(col.Get(0), keys, true); err != nil {
+			return Page{}, err
+		}
+	}
+
+	return page, nil
+}
+
+type pageToken struct {
+	Before bool         `json:"b,omitempty"`
+	Keys   string       `json:"k"`
+	Values []tokenValue `json:"v"`
+}
+
+type tokenValue struct {
+	Type  string `json:"t"`
+	Value string `json:"v,omitempty"`
+}
+
+func (p paginator) encode(doc *Document, keys []SortQuery, before bool) (string, error) {
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		value, ok := doc.Value(key.Field)
+		if !ok {
+			value, ok = doc.Value(key.Field[strings.LastIndexByte(key.Field, '.')+1:])
+		}
+
+		if !ok {
+			panic("rel: cannot paginate using field (" + key.Field + ") that doesn't exist in " + doc.Table())
+		}
+
+		values[i] = value
+	}
+
+	return p.encodeValues(keys, values, before)
+}
+
+// encodeValues creates signed token of values of sort keys, it's also used as iterator checkpoint.
+func (p paginator) encodeValues(keys []SortQuery, values []interface{}, before bool) (string, error) {
+	token := pageToken{
+		Before: before,
+		Keys:   fingerprint(keys),
+		Values: make([]tokenValue, len(keys)),
+	}
+
+	for i := range values {
+		tv, err := encodeTokenValue(values[i])
+		if err != nil {
+			return "", err
+		}
+
+		token.Values[i] = tv
+	}
+
+	payload, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(p.sign(payload)), nil
+}
+
+func (p paginator) decode(keys []SortQuery) ([]interface{}, error) {
+	var (
+		token pageToken
+		parts = strings.Split(p.token, ".")
+	)
+
+	if len(parts) != 2 {
+		return nil, ErrInvalidPageToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, p.sign(payload)) {
+		return nil, ErrInvalidPageToken
+	}
+
+	if err := json.Unmarshal(payload, &token); err != nil {
+		return nil, ErrInvalidPageToken
+	}
+
+	if token.Before != p.before || token.Keys != fingerprint(keys) || len(token.Values) != len(keys) {
+		return nil, ErrInvalidPageToken
+	}
+
+	values := make([]interface{}, len(token.Values))
+	for i := range token.Values {
+		if values[i], err = decodeTokenValue(token.Values[i]); err != nil {
+			return nil, ErrInvalidPageToken
+		}
+	}
+
+	return values, nil
+}
+
+// sign payload of token using the secret, payload is left unsigned when there's no secret,
+// which is only the case for iterator checkpoint.
+func (p paginator) sign(payload []byte) []byte {
+	if len(p.secret) == 0 {
+		return nil
+	}
+
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// fingerprint of sort keys, so token can't be used with different sort.
+func fingerprint(keys []SortQuery) string {
+	var buffer bytes.Buffer
+	for _, key := range keys {
+		buffer.WriteString(key.Field)
+		buffer.WriteString(":")
+		buffer.WriteString(strconv.Itoa(key.Sort))
+		buffer.WriteString(",")
+	}
+
+	sum := sha256.Sum256(buffer.Bytes())
+	return hex.EncodeToString(sum[:8])
+}
+
+func reverseSorts(sorts []SortQuery) []SortQuery {
+	result := make([]SortQuery, len(sorts))
+	for i := range sorts {
+		result[i] = SortQuery{Field: sorts[i].Field, Sort: -sorts[i].Sort}
+	}
+
+	return result
+}
+
+func encodeTokenValue(value interface{}) (tokenValue, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return tokenValue{}, err
+		}
+
+		value = v
+	}
+
+	if value == nil {
+		return tokenValue{Type: "n"}, nil
+	}
+
+	if t, ok := value.(time.Time); ok {
+		return tokenValue{Type: "t", Value: t.Format(time.RFC3339Nano)}, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return tokenValue{Type: "i", Value: strconv.FormatInt(rv.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return tokenValue{Type: "u", Value: strconv.FormatUint(rv.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		return tokenValue{Type: "f", Value: strconv.FormatFloat(rv.Float(), 'g', -1, 64)}, nil
+	case reflect.Bool:
+		return tokenValue{Type: "b", Value: strconv.FormatBool(rv.Bool())}, nil
+	case reflect.String:
+		return tokenValue{Type: "s", Value: rv.String()}, nil
+	}
+
+	return tokenValue{}, fmt.Errorf("rel: unsupported page token value type %T", value)
+}
+
+func decodeTokenValue(tv tokenValue) (interface{}, error) {
+	switch tv.Type {
+	case "n":
+		return nil, nil
+	case "t":
+		return time.Parse(time.RFC3339Nano, tv.Value)
+	case "i":
+		return strconv.ParseInt(tv.Value, 10, 64)
+	case "u":
+		return strconv.ParseUint(tv.Value, 10, 64)
+	case "f":
+		return strconv.ParseFloat(tv.Value, 64)
+	case "b":
+		return strconv.ParseBool(tv.Value)
+	case "s":
+		return tv.Value, nil
+	}
+
+	return nil, ErrInvalidPageToken
+}
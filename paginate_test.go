@@ -0,0 +1,331 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginateOption_String(t *testing.T) {
+	assert.Equal(t, "rel.After(\"token\")", After("token").(after).String())
+	assert.Equal(t, "rel.Before(\"token\")", Before("token").(before).String())
+	assert.Equal(t, "rel.PageSize(10)", PageSize(10).(pageSize).String())
+	assert.Equal(t, "rel.PageSecret(***)", PageSecret([]byte("secret")).(pageSecret).String())
+	assert.Equal(t, "rel.NullsLast(true)", NullsLast(true).String())
+}
+
+func TestPage(t *testing.T) {
+	assert.False(t, Page{}.HasNext())
+	assert.False(t, Page{}.HasPrevious())
+	assert.True(t, Page{Next: "next"}.HasNext())
+	assert.True(t, Page{Previous: "previous"}.HasPrevious())
+}
+
+func TestPaginator_size(t *testing.T) {
+	assert.Equal(t, 20, newPaginator(From("users"), nil).size)
+	assert.Equal(t, 5, newPaginator(From("users").Limit(5), nil).size)
+	assert.Equal(t, 10, newPaginator(From("users").Limit(5), []PaginateOption{PageSize(10)}).size)
+}
+
+func TestPaginator_keys(t *testing.T) {
+	var (
+		p             = paginator{}
+		primaryFields = []string{"id"}
+	)
+
+	assert.Equal(t, []SortQuery{SortAsc("id")}, p.keys(From("users"), primaryFields))
+	assert.Equal(t, []SortQuery{SortDesc("age"), SortAsc("id")}, p.keys(From("users").SortDesc("age"), primaryFields))
+	assert.Equal(t, []SortQuery{SortDesc("users.id"), SortAsc("name")}, p.keys(From("users").SortDesc("users.id").SortAsc("name"), primaryFields))
+	assert.Equal(t, map[string]bool{"id": true, "users.id": true}, p.notNull)
+}
+
+func TestPaginator_filter(t *testing.T) {
+	tests := []struct {
+		name      string
+		nullsLast bool
+		keys      []SortQuery
+		values    []interface{}
+		filter    FilterQuery
+	}{
+		{
+			name:   "asc",
+			keys:   []SortQuery{SortAsc("id")},
+			values: []interface{}{1},
+			filter: Gt("id", 1),
+		},
+		{
+			name:   "mixed",
+			keys:   []SortQuery{SortDesc("age"), SortAsc("id")},
+			values: []interface{}{20, 1},
+			filter: Or(Or(Lt("age", 20), Nil("age")), And(Eq("age", 20), Gt("id", 1))),
+		},
+		{
+			name:   "nulls first",
+			keys:   []SortQuery{SortAsc("note"), SortAsc("id")},
+			values: []interface{}{"a", 1},
+			filter: Or(Gt("note", "a"), And(Eq("note", "a"), Gt("id", 1))),
+		},
+		{
+			name:   "nulls first at null",
+			keys:   []SortQuery{SortAsc("note"), SortAsc("id")},
+			values: []interface{}{nil, 1},
+			filter: Or(NotNil("note"), And(Nil("note"), Gt("id", 1))),
+		},
+		{
+			name:   "nulls first desc",
+			keys:   []SortQuery{SortDesc("note"), SortAsc("id")},
+			values: []interface{}{"a", 1},
+			filter: Or(Or(Lt("note", "a"), Nil("note")), And(Eq("note", "a"), Gt("id", 1))),
+		},
+		{
+			name:   "nulls first desc at null",
+			keys:   []SortQuery{SortDesc("note"), SortAsc("id")},
+			values: []interface{}{nil, 1},
+			filter: And(Nil("note"), Gt("id", 1)),
+		},
+		{
+			name:      "nulls last",
+			nullsLast: true,
+			keys:      []SortQuery{SortAsc("note"), SortAsc("id")},
+			values:    []interface{}{"a", 1},
+			filter:    Or(Or(Gt("note", "a"), Nil("note")), And(Eq("note", "a"), Gt("id", 1))),
+		},
+		{
+			name:      "nulls last at null",
+			nullsLast: true,
+			keys:      []SortQuery{SortAsc("note"), SortAsc("id")},
+			values:    []interface{}{nil, 1},
+			filter:    And(Nil("note"), Gt("id", 1)),
+		},
+		{
+			name:      "nulls last desc",
+			nullsLast: true,
+			keys:      []SortQuery{SortDesc("note"), SortAsc("id")},
+			values:    []interface{}{"a", 1},
+			filter:    Or(Lt("note", "a"), And(Eq("note", "a"), Gt("id", 1))),
+		},
+		{
+			name:      "nulls last desc at null",
+			nullsLast: true,
+			keys:      []SortQuery{SortDesc("note"), SortAsc("id")},
+			values:    []interface{}{nil, 1},
+			filter:    Or(NotNil("note"), And(Nil("note"), Gt("id", 1))),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, ok := paginator{nullsLast: test.nullsLast, notNull: map[string]bool{"id": true}}.filter(test.keys, test.values)
+			assert.True(t, ok)
+			assert.Equal(t, test.filter, filter)
+		})
+	}
+
+	_, ok := paginator{}.filter([]SortQuery{SortDesc("id")}, []interface{}{nil})
+	assert.False(t, ok)
+}
+
+func TestPaginator_token(t *testing.T) {
+	var (
+		now  = time.Now().Truncate(time.Second).UTC()
+		note = "note"
+		p    = paginator{secret: []byte("secret")}
+		keys = []SortQuery{SortDesc("created_at"), SortAsc("name"), SortAsc("note"), SortAsc("age"), SortAsc("id")}
+		doc  = NewDocument(&struct {
+			ID        uint
+			Name      string
+			Note      *string
+			Age       float64
+			CreatedAt time.Time
+		}{ID: 1, Name: "name", Note: &note, Age: 2.5, CreatedAt: now})
+	)
+
+	token, err := p.encode(doc, keys, false)
+	assert.Nil(t, err)
+
+	p.token = token
+	values, err := p.decode(keys)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{now, "name", "note", 2.5, uint64(1)}, values)
+
+	t.Run("different direction", func(t *testing.T) {
+		p := p
+		p.before = true
+		_, err := p.decode(keys)
+		assert.Equal(t, ErrInvalidPageToken, err)
+	})
+
+	t.Run("different sort", func(t *testing.T) {
+		_, err := p.decode(reverseSorts(keys))
+		assert.Equal(t, ErrInvalidPageToken, err)
+	})
+
+	t.Run("different secret", func(t *testing.T) {
+		p := p
+		p.secret = nil
+		_, err := p.decode(keys)
+		assert.Equal(t, ErrInvalidPageToken, err)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		parts := strings.Split(token, ".")
+		for _, tampered := range []string{
+			"token",
+			"!." + parts[1],
+			parts[0] + ".!",
+			parts[0][1:] + "." + parts[1],
+		} {
+			p := p
+			p.token = tampered
+			_, err := p.decode(keys)
+			assert.Equal(t, ErrInvalidPageToken, err)
+		}
+	})
+}
+
+func TestTokenValue(t *testing.T) {
+	tests := []interface{}{
+		nil,
+		int64(-1),
+		uint64(1),
+		1.5,
+		true,
+		"string",
+		time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC),
+	}
+
+	for _, value := range tests {
+		tv, err := encodeTokenValue(value)
+		assert.Nil(t, err)
+
+		decoded, err := decodeTokenValue(tv)
+		assert.Nil(t, err)
+		assert.Equal(t, value, decoded)
+	}
+
+	_, err := encodeTokenValue([]int{1})
+	assert.Equal(t, errors.New("rel: unsupported page token value type []int"), err)
+
+	_, err = decodeTokenValue(tokenValue{Type: "x"})
+	assert.Equal(t, ErrInvalidPageToken, err)
+}
+
+func TestRepository_Paginate(t *testing.T) {
+	var (
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").SortDesc("age")
+		secret  = PageSecret([]byte("secret"))
+		cur     = createCursor(3)
+	)
+
+	adapter.On("Query", query.SortAsc("id").Limit(3)).Return(cur, nil).Once()
+
+	page, err := repo.Paginate(context.TODO(), &users, query, PageSize(2), secret)
+	assert.Nil(t, err)
+	assert.Len(t, users, 2)
+	assert.True(t, page.HasNext())
+	assert.False(t, page.HasPrevious())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+
+	t.Run("next", func(t *testing.T) {
+		var (
+			users   []User
+			adapter = &testAdapter{}
+			repo    = New(adapter)
+			cur     = createCursor(1)
+		)
+
+		adapter.On("Query", query.Where(Or(Or(Lt("age", int64(0)), Nil("age")), And(Eq("age", int64(0)), Gt("id", int64(10))))).SortAsc("id").Limit(3)).Return(cur, nil).Once()
+
+		next, err := repo.Paginate(context.TODO(), &users, query, PageSize(2), After(page.Next), secret)
+		assert.Nil(t, err)
+		assert.Len(t, users, 1)
+		assert.False(t, next.HasNext())
+		assert.True(t, next.HasPrevious())
+
+		adapter.AssertExpectations(t)
+		cur.AssertExpectations(t)
+	})
+
+	t.Run("previous", func(t *testing.T) {
+		var (
+			users   []User
+			adapter = &testAdapter{}
+			repo    = New(adapter)
+			cur     = createCursor(0)
+			token   = (paginator{secret: []byte("secret")}).encodeOrPanic(NewDocument(&User{ID: 10, Age: 20}), []SortQuery{SortDesc("age"), SortAsc("id")})
+		)
+
+		adapter.On("Query", From("users").Where(Or(Gt("age", int64(20)), And(Eq("age", int64(20)), Lt("id", int64(10))))).SortAsc("age").SortDesc("id").Limit(3)).Return(cur, nil).Once()
+
+		previous, err := repo.Paginate(context.TODO(), &users, query, PageSize(2), Before(token), secret)
+		assert.Nil(t, err)
+		assert.Len(t, users, 0)
+		assert.Equal(t, Page{}, previous)
+
+		adapter.AssertExpectations(t)
+		cur.AssertExpectations(t)
+	})
+}
+
+func (p paginator) encodeOrPanic(doc *Document, keys []SortQuery) string {
+	token, err := p.encode(doc, keys, true)
+	must(err)
+	return token
+}
+
+func TestRepository_Paginate_invalidToken(t *testing.T) {
+	var (
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	page, err := repo.Paginate(context.TODO(), &users, From("users"), After("invalid"), PageSecret([]byte("secret")))
+	assert.Equal(t, ErrInvalidPageToken, err)
+	assert.Equal(t, Page{}, page)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Paginate_secretRequired(t *testing.T) {
+	var (
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	page, err := repo.Paginate(context.TODO(), &users, From("users"))
+	assert.Equal(t, ErrPageSecretRequired, err)
+	assert.Equal(t, Page{}, page)
+
+	_, err = repo.Paginate(context.TODO(), &users, From("users"), PageSecret(nil))
+	assert.Equal(t, ErrPageSecretRequired, err)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_MustPaginate(t *testing.T) {
+	var (
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", From("users").SortAsc("id").Limit(21)).Return(&testCursor{}, err).Once()
+
+	assert.PanicsWithValue(t, err, func() {
+		repo.MustPaginate(context.TODO(), &users, From("users"), PageSecret([]byte("secret")))
+	})
+
+	adapter.AssertExpectations(t)
+}
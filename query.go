@@ -43,6 +43,8 @@ func Build(table string, queriers ...Querier) Query {
 			q.Build(&query)
 		case Lock:
 			q.Build(&query)
+		case RowLock:
+			q.Build(&query)
 		case Unscoped:
 			q.Build(&query)
 		case Reload:
@@ -53,6 +55,14 @@ func Build(table string, queriers ...Querier) Query {
 			q.Build(&query)
 		case Cascade:
 			q.Build(&query)
+		case NoCache:
+			q.Build(&query)
+		case WithQuery:
+			q.Build(&query)
+		case CombineQuery:
+			q.Build(&query)
+		case SelectExpr:
+			q.Build(&query)
 		}
 	}
 
@@ -66,21 +76,26 @@ func Build(table string, queriers ...Querier) Query {
 // Query defines information about query generated by query builder.
 type Query struct {
 	empty           bool // TODO: use bitmask to mark what is updated and use it when merging two queries
+	WithQuery       []WithQuery
 	Table           string
+	FromQuery       *SubQuery
 	SelectQuery     SelectQuery
 	JoinQuery       []JoinQuery
 	WhereQuery      FilterQuery
 	GroupQuery      GroupQuery
+	CombineQuery    []CombineQuery
 	SortQuery       []SortQuery
 	OffsetQuery     Offset
 	LimitQuery      Limit
 	LockQuery       Lock
+	RowLockQuery    *RowLock
 	SQLQuery        SQLQuery
 	UnscopedQuery   Unscoped
 	ReloadQuery     Reload
 	CascadeQuery    Cascade
 	PreloadQuery    []string
 	UsePrimaryDb    bool
+	NoCacheQuery    NoCache
 	queryPopulators []QueryPopulator
 }
 
@@ -88,13 +103,19 @@ type Query struct {
 func (q Query) Build(query *Query) {
 	if query.empty {
 		*query = q
+		if q.RowLockQuery != nil {
+			query.RowLockQuery = q.RowLockQuery.clone()
+		}
 	} else {
 		// manual merge
+		query.WithQuery = append(query.WithQuery, q.WithQuery...)
+
 		if q.Table != "" {
 			query.Table = q.Table
+			query.FromQuery = q.FromQuery
 		}
 
-		if q.SelectQuery.Fields != nil {
+		if q.SelectQuery.Fields != nil || q.SelectQuery.Expressions != nil || q.SelectQuery.SubQueries != nil {
 			query.SelectQuery = q.SelectQuery
 		}
 
@@ -108,6 +129,8 @@ func (q Query) Build(query *Query) {
 			query.GroupQuery = q.GroupQuery
 		}
 
+		query.CombineQuery = append(query.CombineQuery, q.CombineQuery...)
+
 		query.SortQuery = append(query.SortQuery, q.SortQuery...)
 
 		if q.OffsetQuery != 0 {
@@ -120,11 +143,16 @@ func (q Query) Build(query *Query) {
 
 		if q.LockQuery != "" {
 			query.LockQuery = q.LockQuery
+			query.RowLockQuery = nil
+			if q.RowLockQuery != nil {
+				query.RowLockQuery = q.RowLockQuery.clone()
+			}
 		}
 
 		query.ReloadQuery = query.ReloadQuery || q.ReloadQuery
 		query.CascadeQuery = query.CascadeQuery || q.CascadeQuery
 		query.UsePrimaryDb = query.UsePrimaryDb || q.UsePrimaryDb
+		query.NoCacheQuery = query.NoCacheQuery || q.NoCacheQuery
 	}
 }
 
@@ -140,18 +168,67 @@ func (q *Query) AddPopulator(populator QueryPopulator) {
 	q.queryPopulators = append(q.queryPopulators, populator)
 }
 
+// With defines common table expression that can be used as table in from or join.
+func (q Query) With(name string, query Query) Query {
+	NewWith(name, query).Build(&q)
+	return q
+}
+
+// WithRecursive defines recursive common table expression that can be used as table in from or join.
+// anchor and recursive query are combined using UNION ALL, recursive query may refer to name as its table.
+func (q Query) WithRecursive(name string, anchor Query, recursive Query) Query {
+	NewWithRecursive(name, anchor, recursive).Build(&q)
+	return q
+}
+
 // Select filter fields to be selected from database.
 func (q Query) Select(fields ...string) Query {
+	var (
+		expressions = q.SelectQuery.Expressions
+		subQueries  = q.SelectQuery.SubQueries
+	)
+
 	q.SelectQuery = NewSelect(fields...)
+	q.SelectQuery.Expressions = expressions
+	q.SelectQuery.SubQueries = subQueries
+	return q
+}
+
+// SelectExpr adds structured expressions to be selected, such as window function.
+func (q Query) SelectExpr(expressions ...SelectExpr) Query {
+	q.SelectQuery.Expressions = append(q.SelectQuery.Expressions, expressions...)
+	return q
+}
+
+// SelectSub adds scalar sub queries to be selected, each sub query is selected using its alias.
+// Sub query may refer to fields of this query using Ref.
+func (q Query) SelectSub(subQueries ...SubQuery) Query {
+	q.SelectQuery.SubQueries = append(q.SelectQuery.SubQueries, subQueries...)
 	return q
 }
 
 // From set the table to be used for query.
 func (q Query) From(table string) Query {
 	q.Table = table
+	q.FromQuery = nil
+	return q
+}
+
+// FromSub set sub query to be used as derived table for query, alias of sub query is used as the table name.
+func (q Query) FromSub(sub SubQuery) Query {
+	q.Table = sub.Alias
+	q.FromQuery = &sub
 	return q
 }
 
+// As wraps the query as a sub query with alias, so it can be used as derived table or scalar sub query.
+func (q Query) As(alias string) SubQuery {
+	return SubQuery{
+		Query: q,
+		Alias: alias,
+	}
+}
+
 // Distinct sets select query to be distinct.
 func (q Query) Distinct() Query {
 	q.SelectQuery.OnlyDistinct = true
@@ -248,6 +325,30 @@ func (q Query) OrHavingf(expr string, args ...interface{}) Query {
 	return q
 }
 
+// Union combines distinct result of this query and the other query.
+func (q Query) Union(query Query) Query {
+	NewCombine(CombineUnionOp, query).Build(&q)
+	return q
+}
+
+// UnionAll combines result of this query and the other query including duplicates.
+func (q Query) UnionAll(query Query) Query {
+	NewCombine(CombineUnionAllOp, query).Build(&q)
+	return q
+}
+
+// Intersect returns distinct result of this query that also returned by the other query.
+func (q Query) Intersect(query Query) Query {
+	NewCombine(CombineIntersectOp, query).Build(&q)
+	return q
+}
+
+// Except returns distinct result of this query that is not returned by the other query.
+func (q Query) Except(query Query) Query {
+	NewCombine(CombineExceptOp, query).Build(&q)
+	return q
+}
+
 // Sort query.
 func (q Query) Sort(fields ...string) Query {
 	return q.SortAsc(fields...)
@@ -294,8 +395,15 @@ func (q Query) Limit(limit int) Query {
 }
 
 // Lock query expression.
+// Structured form of the lock is available in RowLockQuery when the expression is a standard locking clause.
 func (q Query) Lock(lock string) Query {
-	q.LockQuery = Lock(lock)
+	Lock(lock).Build(&q)
+	return q
+}
+
+// RowLock locks the selected rows using structured locking clause.
+func (q Query) RowLock(lock RowLock) Query {
+	lock.Build(&q)
 	return q
 }
 
@@ -329,6 +437,12 @@ func (q Query) UsePrimary() Query {
 	return q
 }
 
+// NoCache skips query cache.
+func (q Query) NoCache() Query {
+	q.NoCacheQuery = true
+	return q
+}
+
 // String describe query as string.
 func (q Query) String() string {
 	if q.SQLQuery.Statement != "" {
@@ -342,7 +456,15 @@ func (q Query) String() string {
 		builder.WriteString(".UsePrimary()")
 	}
 
-	if q.Table != "" {
+	for i := range q.WithQuery {
+		builder.WriteString(q.WithQuery[i].String())
+	}
+
+	if q.FromQuery != nil {
+		builder.WriteString(".FromSub(")
+		builder.WriteString(q.FromQuery.String())
+		builder.WriteByte(')')
+	} else if q.Table != "" {
 		builder.WriteString(".From(\"")
 		builder.WriteString(q.Table)
 		builder.WriteString("\")")
@@ -354,6 +476,28 @@ func (q Query) String() string {
 		builder.WriteString("\")")
 	}
 
+	if len(q.SelectQuery.Expressions) != 0 {
+		builder.WriteString(".SelectExpr(")
+		for i := range q.SelectQuery.Expressions {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			builder.WriteString(q.SelectQuery.Expressions[i].String())
+		}
+		builder.WriteByte(')')
+	}
+
+	if len(q.SelectQuery.SubQueries) != 0 {
+		builder.WriteString(".SelectSub(")
+		for i := range q.SelectQuery.SubQueries {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			builder.WriteString(q.SelectQuery.SubQueries[i].String())
+		}
+		builder.WriteByte(')')
+	}
+
 	if q.SelectQuery.OnlyDistinct {
 		builder.WriteString(".Distinct()")
 	}
@@ -388,6 +532,10 @@ func (q Query) String() string {
 		}
 	}
 
+	for i := range q.CombineQuery {
+		builder.WriteString(q.CombineQuery[i].String())
+	}
+
 	for _, sq := range q.SortQuery {
 		if sq.Asc() {
 			builder.WriteString(".SortAsc(\"")
@@ -434,6 +582,10 @@ func (q Query) String() string {
 		builder.WriteString("\")")
 	}
 
+	if q.NoCacheQuery {
+		builder.WriteString(".NoCache()")
+	}
+
 	if str := builder.String(); str != "rel" {
 		return str
 	}
@@ -460,6 +612,11 @@ func From(table string) Query {
 	return query
 }
 
+// FromSub create a query with chainable syntax, using derived table as the starting point.
+func FromSub(sub SubQuery) Query {
+	return newQuery().FromSub(sub)
+}
+
 // Join create a query with chainable syntax, using join as the starting point.
 func Join(table string, filter ...FilterQuery) Query {
 	return JoinOn(table, "", "", filter...)
@@ -510,6 +667,16 @@ func Where(filters ...FilterQuery) Query {
 	return query
 }
 
+// With create a query with chainable syntax, using common table expression as the starting point.
+func With(name string, query Query) Query {
+	return newQuery().With(name, query)
+}
+
+// WithRecursive create a query with chainable syntax, using recursive common table expression as the starting point.
+func WithRecursive(name string, anchor Query, recursive Query) Query {
+	return newQuery().WithRecursive(name, anchor, recursive)
+}
+
 func UsePrimary() Query {
 	query := newQuery()
 	query.UsePrimaryDb = true
@@ -538,20 +705,6 @@ func (l Limit) applyColumn(column *Column) {
 	column.Limit = int(l)
 }
 
-// Lock query.
-// This query will be ignored if used outside of transaction.
-type Lock string
-
-// Build query.
-func (l Lock) Build(query *Query) {
-	query.LockQuery = l
-}
-
-// ForUpdate lock query.
-func ForUpdate() Lock {
-	return "FOR UPDATE"
-}
-
 // Unscoped query.
 type Unscoped bool
 
@@ -565,6 +718,15 @@ func (u Unscoped) Apply(doc *Document, mutation *Mutation) {
 	mutation.Unscoped = u
 }
 
+// NoCache query.
+// When true, query cache is skipped and the query is always sent to the database.
+type NoCache bool
+
+// Build query.
+func (nc NoCache) Build(query *Query) {
+	query.NoCacheQuery = nc
+}
+
 // Preload query.
 type Preload string
 
@@ -12,21 +12,26 @@ import (
 )
 
 func shallowAssertQuery(t *testing.T, a rel.Query, b rel.Query) {
+	assert.Equal(t, a.WithQuery, b.WithQuery)
 	assert.Equal(t, a.Table, b.Table)
+	assert.Equal(t, a.FromQuery, b.FromQuery)
 	assert.Equal(t, a.SelectQuery, b.SelectQuery)
 	assert.Equal(t, a.JoinQuery, b.JoinQuery)
 	assert.Equal(t, a.WhereQuery, b.WhereQuery)
 	assert.Equal(t, a.GroupQuery, b.GroupQuery)
+	assert.Equal(t, a.CombineQuery, b.CombineQuery)
 	assert.Equal(t, a.SortQuery, b.SortQuery)
 	assert.Equal(t, a.OffsetQuery, b.OffsetQuery)
 	assert.Equal(t, a.LimitQuery, b.LimitQuery)
 	assert.Equal(t, a.LockQuery, b.LockQuery)
+	assert.Equal(t, a.RowLockQuery, b.RowLockQuery)
 	assert.Equal(t, a.SQLQuery, b.SQLQuery)
 	assert.Equal(t, a.UnscopedQuery, b.UnscopedQuery)
 	assert.Equal(t, a.ReloadQuery, b.ReloadQuery)
 	assert.Equal(t, a.CascadeQuery, b.CascadeQuery)
 	assert.Equal(t, a.PreloadQuery, b.PreloadQuery)
 	assert.Equal(t, a.UsePrimaryDb, b.UsePrimaryDb)
+	assert.Equal(t, a.NoCacheQuery, b.NoCacheQuery)
 }
 
 func TestQuerier(t *testing.T) {
@@ -182,6 +187,7 @@ func TestQuerier(t *testing.T) {
 			query: rel.Query{
 				WhereQuery:   where.Eq("id", 1),
 				LockQuery:    "FOR UPDATE",
+				RowLockQuery: &rel.RowLock{Strength: rel.LockUpdate},
 				CascadeQuery: true,
 			},
 		},
@@ -201,6 +207,7 @@ func TestQuerier(t *testing.T) {
 				GroupQuery:   rel.GroupQuery{Fields: []string{"status"}},
 				WhereQuery:   where.Nil("deleted_at").AndNe("status", "paid"),
 				LockQuery:    "FOR UPDATE",
+				RowLockQuery: &rel.RowLock{Strength: rel.LockUpdate},
 				CascadeQuery: true,
 			},
 		},
@@ -270,6 +277,95 @@ func TestQuerier(t *testing.T) {
 				UsePrimaryDb: true,
 			},
 		},
+		{
+			name: "rel.From(\"users\").NoCache()",
+			queriers: [][]rel.Querier{
+				{
+					rel.From("users").NoCache(),
+				},
+				{
+					rel.From("users"), rel.NoCache(true),
+				},
+				{
+					rel.NoCache(true), rel.From("users"),
+				},
+			},
+			query: rel.Query{
+				Table:        "users",
+				CascadeQuery: true,
+				NoCacheQuery: true,
+			},
+		},
+		{
+			name: "rel.With(\"active_users\", rel.From(\"users\").Where(where.Eq(\"active\", true))).From(\"active_users\")",
+			queriers: [][]rel.Querier{
+				{
+					rel.With("active_users", rel.From("users").Where(where.Eq("active", true))).From("active_users"),
+				},
+				{
+					rel.From("active_users"), rel.NewWith("active_users", rel.From("users").Where(where.Eq("active", true))),
+				},
+			},
+			query: rel.Query{
+				WithQuery:    []rel.WithQuery{rel.NewWith("active_users", rel.From("users").Where(where.Eq("active", true)))},
+				Table:        "active_users",
+				CascadeQuery: true,
+			},
+		},
+		{
+			name: "rel.WithRecursive(\"tree\", rel.From(\"categories\").Where(where.Nil(\"parent_id\")), rel.From(\"categories\").JoinWith(\"JOIN\", \"tree\", \"categories.parent_id\", \"tree.id\")).From(\"tree\")",
+			queriers: [][]rel.Querier{
+				{
+					rel.WithRecursive("tree",
+						rel.From("categories").Where(where.Nil("parent_id")),
+						rel.From("categories").JoinOn("tree", "categories.parent_id", "tree.id"),
+					).From("tree"),
+				},
+			},
+			query: rel.Query{
+				WithQuery: []rel.WithQuery{rel.NewWithRecursive("tree",
+					rel.From("categories").Where(where.Nil("parent_id")),
+					rel.From("categories").JoinOn("tree", "categories.parent_id", "tree.id"),
+				)},
+				Table:        "tree",
+				CascadeQuery: true,
+			},
+		},
+		{
+			name: "rel.From(\"users\").Select(\"id\", \"name\").Union(rel.From(\"admins\").Select(\"id\", \"name\")).UnionAll(rel.From(\"guests\")).Intersect(rel.From(\"members\")).Except(rel.From(\"banned\")).SortAsc(\"name\").Limit(10)",
+			queriers: [][]rel.Querier{
+				{
+					rel.From("users").Select("id", "name").
+						Union(rel.From("admins").Select("id", "name")).
+						UnionAll(rel.From("guests")).
+						Intersect(rel.From("members")).
+						Except(rel.From("banned")).
+						SortAsc("name").Limit(10),
+				},
+				{
+					rel.From("users").Select("id", "name"),
+					rel.NewCombine(rel.CombineUnionOp, rel.From("admins").Select("id", "name")),
+					rel.NewCombine(rel.CombineUnionAllOp, rel.From("guests")),
+					rel.NewCombine(rel.CombineIntersectOp, rel.From("members")),
+					rel.NewCombine(rel.CombineExceptOp, rel.From("banned")),
+					sort.Asc("name"),
+					rel.Limit(10),
+				},
+			},
+			query: rel.Query{
+				Table:       "users",
+				SelectQuery: rel.NewSelect("id", "name"),
+				CombineQuery: []rel.CombineQuery{
+					{Op: rel.CombineUnionOp, Query: rel.From("admins").Select("id", "name")},
+					{Op: rel.CombineUnionAllOp, Query: rel.From("guests")},
+					{Op: rel.CombineIntersectOp, Query: rel.From("members")},
+					{Op: rel.CombineExceptOp, Query: rel.From("banned")},
+				},
+				SortQuery:    []rel.SortQuery{sort.Asc("name")},
+				LimitQuery:   10,
+				CascadeQuery: true,
+			},
+		},
 		{
 			name: "",
 			queriers: [][]rel.Querier{
@@ -315,6 +411,66 @@ func TestQuery_Select(t *testing.T) {
 	}, rel.From("users").Select("id", "name", "email"))
 }
 
+func TestQuery_SelectExpr(t *testing.T) {
+	var (
+		rank  = rel.Rank().Over(rel.PartitionBy("department").SortDesc("salary")).As("rank")
+		total = rel.Expr("sum", "salary").Over(rel.PartitionBy("department")).As("total")
+	)
+
+	result := rel.Query{
+		Table: "employees",
+		SelectQuery: rel.SelectQuery{
+			Fields:      []string{"id", "name"},
+			Expressions: []rel.SelectExpr{rank, total},
+		},
+		CascadeQuery: true,
+	}
+
+	assert.Equal(t, result, rel.From("employees").Select("id", "name").SelectExpr(rank, total))
+	assert.Equal(t, result, rel.From("employees").SelectExpr(rank, total).Select("id", "name"))
+	assert.Equal(t, result, rel.Build("employees", rel.Select("id", "name"), rank, total))
+	assert.Equal(t, "rel.From(\"employees\").Select(\"id\", \"name\").SelectExpr("+
+		"rel.Expr(\"rank\").Over(rel.PartitionBy(\"department\").SortDesc(\"salary\")).As(\"rank\"), "+
+		"rel.Expr(\"sum\", \"salary\").Over(rel.PartitionBy(\"department\")).As(\"total\"))", result.String())
+}
+
+func TestQuery_FromSub(t *testing.T) {
+	var (
+		sub    = rel.Select("user_id", "sum(amount) AS total").From("orders").Group("user_id").As("totals")
+		result = rel.Query{
+			Table:        "totals",
+			FromQuery:    &sub,
+			WhereQuery:   where.Gt("total", 100),
+			CascadeQuery: true,
+		}
+	)
+
+	assert.Equal(t, result, rel.FromSub(sub).Where(where.Gt("total", 100)))
+	assert.Equal(t, result, rel.Build("users", rel.FromSub(sub), where.Gt("total", 100)))
+	assert.Equal(t, "rel.FromSub(rel.From(\"orders\").Select(\"user_id\", \"sum(amount) AS total\").Group(\"user_id\").As(\"totals\")).Where(where.Gt(\"total\", 100))", result.String())
+
+	assert.Equal(t, rel.From("orders"), rel.FromSub(sub).From("orders"))
+	assert.Equal(t, rel.From("orders"), rel.Build("", rel.FromSub(sub), rel.From("orders")))
+}
+
+func TestQuery_SelectSub(t *testing.T) {
+	var (
+		count  = rel.Select("count(id)").From("orders").Where(where.Eq("orders.user_id", rel.Ref("users.id"))).As("order_count")
+		result = rel.Query{
+			Table: "users",
+			SelectQuery: rel.SelectQuery{
+				Fields:     []string{"id", "name"},
+				SubQueries: []rel.SubQuery{count},
+			},
+			CascadeQuery: true,
+		}
+	)
+
+	assert.Equal(t, result, rel.From("users").Select("id", "name").SelectSub(count))
+	assert.Equal(t, result, rel.From("users").SelectSub(count).Select("id", "name"))
+	assert.Equal(t, "rel.From(\"users\").Select(\"id\", \"name\").SelectSub(rel.From(\"orders\").Select(\"count(id)\").Where(where.Eq(\"orders.user_id\", rel.Ref(\"users.id\"))).As(\"order_count\"))", result.String())
+}
+
 func TestQuery_Distinct(t *testing.T) {
 	assert.Equal(t, rel.Query{
 		Table: "users",
@@ -809,6 +965,27 @@ func TestQuery_Lock_outsideTransaction(t *testing.T) {
 	assert.Equal(t, rel.Query{
 		Table:        "users",
 		LockQuery:    "FOR UPDATE",
+		RowLockQuery: &rel.RowLock{Strength: rel.LockUpdate},
 		CascadeQuery: true,
 	}, rel.From("users").Lock("FOR UPDATE"))
 }
+
+func TestQuery_RowLock(t *testing.T) {
+	result := rel.Query{
+		Table:        "jobs",
+		LockQuery:    "FOR UPDATE OF jobs SKIP LOCKED",
+		RowLockQuery: &rel.RowLock{Strength: rel.LockUpdate, Wait: rel.LockSkipLocked, Tables: []string{"jobs"}},
+		CascadeQuery: true,
+	}
+
+	assert.Equal(t, result, rel.From("jobs").RowLock(rel.RowLockForUpdate().Of("jobs").SkipLocked()))
+	assert.Equal(t, result, rel.From("jobs").Lock("FOR UPDATE OF jobs SKIP LOCKED"))
+	assert.Equal(t, result, rel.Build("jobs", rel.RowLockForUpdate().Of("jobs").SkipLocked()))
+	assert.Equal(t, "rel.From(\"jobs\").Lock(\"FOR UPDATE OF jobs SKIP LOCKED\")", result.String())
+
+	assert.Equal(t, rel.Query{
+		Table:        "users",
+		LockQuery:    "LOCK IN SHARE MODE",
+		CascadeQuery: true,
+	}, rel.From("users").Lock("LOCK IN SHARE MODE"))
+}
@@ -0,0 +1,260 @@
+// Package replica provides rel.Adapter that routes queries to a primary and read replica databases.
+//
+// Query and aggregate are sent to replicas using weighted round-robin,
+// while writes, transactions, locking queries and queries that use rel.UsePrimary are sent to primary.
+// Transaction is always started on primary, so every operation inside it is performed on primary.
+package replica
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-rel/rel"
+)
+
+type contextKey int8
+
+var (
+	sessionKey contextKey
+	now        = time.Now
+)
+
+// Adapter that routes operations to primary and replicas.
+type Adapter struct {
+	primary  rel.Adapter
+	replicas []rel.Adapter
+	weights  []int
+	current  []int
+	sticky   time.Duration
+	mu       sync.Mutex
+}
+
+var (
+	_ rel.Adapter             = (*Adapter)(nil)
+	_ rel.TransactionBeginner = (*Adapter)(nil)
+)
+
+// Option to configure adapter.
+type Option func(a *Adapter)
+
+// Weights of each replicas, replica with higher weight receives more queries.
+// Replicas without weight or with non positive weight will use weight 1.
+func Weights(weights ...int) Option {
+	return func(a *Adapter) {
+		for i := range a.weights {
+			if i < len(weights) && weights[i] > 0 {
+				a.weights[i] = weights[i]
+			}
+		}
+	}
+}
+
+// StickyWindow routes reads to primary for the given duration after a write,
+// this only applies to context created using Session.
+func StickyWindow(d time.Duration) Option {
+	return func(a *Adapter) {
+		a.sticky = d
+	}
+}
+
+type session struct {
+	mu        sync.Mutex
+	lastWrite time.Time
+}
+
+// Session returns context that tracks writes performed using it,
+// so reads using the same context can stick to primary within the sticky window.
+func Session(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey, &session{})
+}
+
+// Close primary and replicas connection.
+func (a *Adapter) Close() error {
+	err := a.primary.Close()
+	for i := range a.replicas {
+		if e := a.replicas[i].Close(); err == nil {
+			err = e
+		}
+	}
+
+	return err
+}
+
+// Instrumentation set instrumenter for primary and replicas.
+func (a *Adapter) Instrumentation(instrumenter rel.Instrumenter) {
+	a.primary.Instrumentation(instrumenter)
+	for i := range a.replicas {
+		a.replicas[i].Instrumentation(instrumenter)
+	}
+}
+
+// Ping primary and replicas.
+func (a *Adapter) Ping(ctx context.Context) error {
+	if err := a.primary.Ping(ctx); err != nil {
+		return err
+	}
+
+	for i := range a.replicas {
+		if err := a.replicas[i].Ping(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Aggregate using replica unless primary is required.
+func (a *Adapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string) (int, error) {
+	return a.reader(ctx, query).Aggregate(ctx, query, mode, field)
+}
+
+// Query using replica unless primary is required.
+func (a *Adapter) Query(ctx context.Context, query rel.Query) (rel.Cursor, error) {
+	return a.reader(ctx, query).Query(ctx, query)
+}
+
+// Insert using primary.
+func (a *Adapter) Insert(ctx context.Context, query rel.Query, primaryField string, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (interface{}, error) {
+	return a.writer(ctx).Insert(ctx, query, primaryField, mutates, onConflict)
+}
+
+// InsertAll using primary.
+func (a *Adapter) InsertAll(ctx context.Context, query rel.Query, primaryField string, fields []string, bulkMutates []map[string]rel.Mutate, onConflict rel.OnConflict) ([]interface{}, error) {
+	return a.writer(ctx).InsertAll(ctx, query, primaryField, fields, bulkMutates, onConflict)
+}
+
+// Update using primary.
+func (a *Adapter) Update(ctx context.Context, query rel.Query, primaryField string, mutates map[string]rel.Mutate) (int, error) {
+	return a.writer(ctx).Update(ctx, query, primaryField, mutates)
+}
+
+// Delete using primary.
+func (a *Adapter) Delete(ctx context.Context, query rel.Query) (int, error) {
+	return a.writer(ctx).Delete(ctx, query)
+}
+
+// Exec raw statement using primary.
+func (a *Adapter) Exec(ctx context.Context, stmt string, args []interface{}) (int64, int64, error) {
+	return a.writer(ctx).Exec(ctx, stmt, args)
+}
+
+// Begin a transaction on primary.
+func (a *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	return a.writer(ctx).Begin(ctx)
+}
+
+// BeginWith begins a transaction with options on primary.
+func (a *Adapter) BeginWith(ctx context.Context, options rel.TransactionOptions) (rel.Adapter, error) {
+	if tb, ok := a.writer(ctx).(rel.TransactionBeginner); ok {
+		return tb.BeginWith(ctx, options)
+	}
+
+	return nil, rel.ErrTransactionOptionNotSupported
+}
+
+// Commit is forwarded to primary.
+func (a *Adapter) Commit(ctx context.Context) error {
+	return a.primary.Commit(ctx)
+}
+
+// Rollback is forwarded to primary.
+func (a *Adapter) Rollback(ctx context.Context) error {
+	return a.primary.Rollback(ctx)
+}
+
+// Apply migration using primary.
+func (a *Adapter) Apply(ctx context.Context, migration rel.Migration) error {
+	return a.writer(ctx).Apply(ctx, migration)
+}
+
+// Primary adapter.
+func (a *Adapter) Primary() rel.Adapter {
+	return a.primary
+}
+
+// Replicas adapter.
+func (a *Adapter) Replicas() []rel.Adapter {
+	return a.replicas
+}
+
+// writer returns primary and marks the session as written.
+func (a *Adapter) writer(ctx context.Context) rel.Adapter {
+	if s, ok := ctx.Value(sessionKey).(*session); ok {
+		s.mu.Lock()
+		s.lastWrite = now()
+		s.mu.Unlock()
+	}
+
+	return a.primary
+}
+
+// reader returns adapter to be used for reading.
+func (a *Adapter) reader(ctx context.Context, query rel.Query) rel.Adapter {
+	if len(a.replicas) == 0 || query.UsePrimaryDb || query.LockQuery != "" || a.stick(ctx) {
+		return a.primary
+	}
+
+	return a.replicas[a.next()]
+}
+
+// stick returns true when session has written within the sticky window.
+func (a *Adapter) stick(ctx context.Context) bool {
+	if a.sticky <= 0 {
+		return false
+	}
+
+	s, ok := ctx.Value(sessionKey).(*session)
+	if !ok {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return !s.lastWrite.IsZero() && now().Sub(s.lastWrite) < a.sticky
+}
+
+// next returns index of replica using smooth weighted round-robin.
+func (a *Adapter) next() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var (
+		total    int
+		selected int
+	)
+
+	for i := range a.weights {
+		a.current[i] += a.weights[i]
+		total += a.weights[i]
+
+		if a.current[i] > a.current[selected] {
+			selected = i
+		}
+	}
+
+	a.current[selected] -= total
+	return selected
+}
+
+// New adapter that uses primary for writes and replicas for reads.
+// When no replica is given, every operation is sent to primary.
+func New(primary rel.Adapter, replicas []rel.Adapter, options ...Option) *Adapter {
+	a := &Adapter{
+		primary:  primary,
+		replicas: replicas,
+		weights:  make([]int, len(replicas)),
+		current:  make([]int, len(replicas)),
+	}
+
+	for i := range a.weights {
+		a.weights[i] = 1
+	}
+
+	for i := range options {
+		options[i](a)
+	}
+
+	return a
+}
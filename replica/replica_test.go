@@ -0,0 +1,120 @@
+package replica
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/adaptertest"
+	"github.com/go-rel/rel/memadapter"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+type User struct {
+	ID   int
+	Name string
+}
+
+func setup(t *testing.T, options ...Option) (*Adapter, rel.Repository) {
+	var (
+		ctx      = context.TODO()
+		adapters = []rel.Adapter{memadapter.New(), memadapter.New(), memadapter.New()}
+		names    = []string{"primary", "replica 1", "replica 2"}
+	)
+
+	for i := range adapters {
+		rel.New(adapters[i]).MustInsert(ctx, &User{Name: names[i]})
+	}
+
+	adapter := New(adapters[0], adapters[1:], options...)
+	return adapter, rel.New(adapter)
+}
+
+func findName(t *testing.T, ctx context.Context, repo rel.Repository, queriers ...rel.Querier) string {
+	var user User
+	assert.Nil(t, repo.Find(ctx, &user, queriers...))
+	return user.Name
+}
+
+func TestAdapter(t *testing.T) {
+	adaptertest.Run(t, New(memadapter.New(), nil))
+}
+
+func TestAdapter_sameReplica(t *testing.T) {
+	primary := memadapter.New()
+	adaptertest.Run(t, New(primary, []rel.Adapter{primary}))
+}
+
+func TestAdapter_roundRobin(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		_, repo = setup(t)
+	)
+
+	assert.Equal(t, "replica 1", findName(t, ctx, repo))
+	assert.Equal(t, "replica 2", findName(t, ctx, repo))
+	assert.Equal(t, "replica 1", findName(t, ctx, repo))
+	assert.Equal(t, 1, repo.MustCount(ctx, "users"))
+}
+
+func TestAdapter_weights(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		_, repo = setup(t, Weights(2, 1))
+		names   []string
+	)
+
+	for i := 0; i < 6; i++ {
+		names = append(names, findName(t, ctx, repo))
+	}
+
+	assert.Equal(t, []string{"replica 1", "replica 2", "replica 1", "replica 1", "replica 2", "replica 1"}, names)
+}
+
+func TestAdapter_primary(t *testing.T) {
+	var (
+		ctx        = context.TODO()
+		adapter, _ = setup(t)
+		repo       = rel.New(adapter)
+	)
+
+	assert.Equal(t, "primary", findName(t, ctx, repo, rel.UsePrimary()))
+	assert.Equal(t, "primary", findName(t, ctx, repo, rel.ForUpdate()))
+
+	user := User{Name: "written"}
+	repo.MustInsert(ctx, &user)
+	assert.Equal(t, 1, rel.New(adapter.Primary()).MustCount(ctx, "users", where.Eq("name", "written")))
+	assert.Equal(t, 0, repo.MustCount(ctx, "users", where.Eq("name", "written")))
+
+	assert.Nil(t, repo.Transaction(ctx, func(ctx context.Context) error {
+		assert.Equal(t, 1, repo.MustCount(ctx, "users", where.Eq("name", "written")))
+		repo.MustUpdate(ctx, &user, rel.Set("name", "updated"))
+		return nil
+	}, rel.ReadOnly(false)))
+
+	assert.Equal(t, "updated", findName(t, ctx, repo, where.Eq("id", user.ID), rel.UsePrimary()))
+	assert.Nil(t, repo.Ping(ctx))
+	assert.Len(t, adapter.Replicas(), 2)
+}
+
+func TestAdapter_sticky(t *testing.T) {
+	var (
+		ctx     = Session(context.TODO())
+		_, repo = setup(t, StickyWindow(time.Second))
+		current = time.Now()
+	)
+
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	assert.Equal(t, "replica 1", findName(t, ctx, repo))
+
+	repo.MustInsert(ctx, &User{Name: "written"})
+	assert.Equal(t, "primary", findName(t, ctx, repo))
+	assert.Equal(t, "replica 2", findName(t, context.TODO(), repo))
+
+	current = current.Add(time.Second)
+	assert.Equal(t, "replica 1", findName(t, ctx, repo))
+}
@@ -3,9 +3,12 @@ package rel
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 )
 
 // Repository for interacting with database.
@@ -21,22 +24,52 @@ type Repository interface {
 
 	// Iterate through a collection of records from database in batches.
 	// This function returns iterator that can be used to loop all records.
-	// Limit, Offset and Sort query is automatically ignored.
+	// Limit, Offset and Sort query is automatically ignored, use SortBy option to iterate in other order.
+	// Batches are fetched using primary values of the last record, offset is only used when primary fields are not selected.
+	// Records can be iterated into Map, in that case table must be specified by the query and id is used as primary field
+	// unless MapPrimary option is given.
 	Iterate(ctx context.Context, query Query, option ...IteratorOption) Iterator
 
+	// IterateParallel splits the range of primary value into partitions, and iterates each partition concurrently.
+	// fn must be a func(context.Context, *T) error, and it's called with every record from multiple goroutines.
+	// Iteration stops when fn returns error or the context is canceled, and the first error is returned.
+	// Options are applied to every worker, Start, Finish, SortBy, Resume and Checkpoints options are ignored.
+	// Record must have a single integer primary field, and it should not be used inside transaction.
+	IterateParallel(ctx context.Context, query Query, workers int, fn interface{}, options ...IteratorOption) error
+
 	// Aggregate over the given field.
 	// Supported aggregate: count, sum, avg, max, min.
 	// Any select, group, offset, limit and sort query will be ignored automatically.
-	// If complex aggregation is needed, consider using All instead.
+	// If multiple or grouped aggregation is needed, consider using Aggregates or AggregateGroups instead.
 	Aggregate(ctx context.Context, query Query, aggregate string, field string) (int, error)
 
 	// MustAggregate over the given field.
 	// Supported aggregate: count, sum, avg, max, min.
 	// Any select, group, offset, limit and sort query will be ignored automatically.
-	// If complex aggregation is needed, consider using All instead.
+	// If multiple or grouped aggregation is needed, consider using Aggregates or AggregateGroups instead.
 	// It'll panic if any error eccured.
 	MustAggregate(ctx context.Context, query Query, aggregate string, field string) int
 
+	// Aggregates performs multiple aggregations in a single query.
+	// Any select, group, offset, limit and sort query will be ignored automatically.
+	Aggregates(ctx context.Context, query Query, aggregations ...Aggregation) (AggregateResult, error)
+
+	// MustAggregates performs multiple aggregations in a single query.
+	// Any select, group, offset, limit and sort query will be ignored automatically.
+	// It'll panic if any error eccured.
+	MustAggregates(ctx context.Context, query Query, aggregations ...Aggregation) AggregateResult
+
+	// AggregateGroups performs multiple aggregations for every group of the query.
+	// Each result contains value of the group fields along with the aggregations.
+	// Any select query will be ignored automatically, sort can use the name of aggregation.
+	AggregateGroups(ctx context.Context, query Query, aggregations ...Aggregation) ([]AggregateResult, error)
+
+	// MustAggregateGroups performs multiple aggregations for every group of the query.
+	// Each result contains value of the group fields along with the aggregations.
+	// Any select query will be ignored automatically, sort can use the name of aggregation.
+	// It'll panic if any error eccured.
+	MustAggregateGroups(ctx context.Context, query Query, aggregations ...Aggregation) []AggregateResult
+
 	// Count records that match the query.
 	Count(ctx context.Context, collection string, queriers ...Querier) (int, error)
 
@@ -44,7 +77,44 @@ type Repository interface {
 	// It'll panic if any error eccured.
 	MustCount(ctx context.Context, collection string, queriers ...Querier) int
 
+	// Pluck a field of records that match the query into a slice.
+	// Destination must be a pointer to slice of the field type, and table must be specified by the query.
+	Pluck(ctx context.Context, field string, dest interface{}, queriers ...Querier) error
+
+	// MustPluck a field of records that match the query into a slice.
+	// It'll panic if any error eccured.
+	MustPluck(ctx context.Context, field string, dest interface{}, queriers ...Querier)
+
+	// Exists returns true if any record matches the query.
+	// Unlike Count, it stops at the first matching record.
+	Exists(ctx context.Context, collection string, queriers ...Querier) (bool, error)
+
+	// MustExists returns true if any record matches the query.
+	// It'll panic if any error eccured.
+	MustExists(ctx context.Context, collection string, queriers ...Querier) bool
+
+	// First record that match the query ordered by primary fields.
+	// Sort query is automatically ignored.
+	// If no result found, it'll return not found error.
+	First(ctx context.Context, record interface{}, queriers ...Querier) error
+
+	// MustFirst record that match the query ordered by primary fields.
+	// Sort query is automatically ignored.
+	// If no result found, it'll panic.
+	MustFirst(ctx context.Context, record interface{}, queriers ...Querier)
+
+	// Last record that match the query ordered by primary fields.
+	// Sort query is automatically ignored.
+	// If no result found, it'll return not found error.
+	Last(ctx context.Context, record interface{}, queriers ...Querier) error
+
+	// MustLast record that match the query ordered by primary fields.
+	// Sort query is automatically ignored.
+	// If no result found, it'll panic.
+	MustLast(ctx context.Context, record interface{}, queriers ...Querier)
+
 	// Find a record that match the query.
+	// Record can be a pointer to Map to read a record without defining a struct, table must be specified by the query.
 	// If no result found, it'll return not found error.
 	Find(ctx context.Context, record interface{}, queriers ...Querier) error
 
@@ -53,6 +123,7 @@ type Repository interface {
 	MustFind(ctx context.Context, record interface{}, queriers ...Querier)
 
 	// FindAll records that match the query.
+	// Records can be a pointer to slice of Map to read records without defining a struct, table must be specified by the query.
 	FindAll(ctx context.Context, records interface{}, queriers ...Querier) error
 
 	// MustFindAll records that match the query.
@@ -70,6 +141,37 @@ type Repository interface {
 	// It'll panic if any error eccured.
 	MustFindAndCountAll(ctx context.Context, records interface{}, queriers ...Querier) int
 
+	// Scan the first result of the query into any struct by matching db tag with the result columns.
+	// Unlike Find, the struct doesn't need to own a table, and no default scope, preload and hooks are applied.
+	// Unknown columns are ignored unless Strict option is used.
+	// If no result found, it'll return not found error.
+	Scan(ctx context.Context, dest interface{}, query Query, options ...ScanOption) error
+
+	// MustScan the first result of the query into any struct.
+	// It'll panic if any error eccured.
+	MustScan(ctx context.Context, dest interface{}, query Query, options ...ScanOption)
+
+	// ScanAll results of the query into slice of any struct by matching db tag with the result columns.
+	// Unlike FindAll, the struct doesn't need to own a table, and no default scope, preload and hooks are applied.
+	// Unknown columns are ignored unless Strict option is used.
+	ScanAll(ctx context.Context, dest interface{}, query Query, options ...ScanOption) error
+
+	// MustScanAll results of the query into slice of any struct.
+	// It'll panic if any error eccured.
+	MustScanAll(ctx context.Context, dest interface{}, query Query, options ...ScanOption)
+
+	// Paginate records that match the query using keyset pagination.
+	// Records are ordered by the sort of the query with primary fields as the tie breaker,
+	// and the adjacent pages can be fetched using After or Before option with the token of returned Page.
+	// Nullable sort field must be defined as pointer, use NullsLast option when the database sorts null as the largest value.
+	// Tokens are signed using PageSecret option, which is required.
+	// Offset query is automatically ignored.
+	Paginate(ctx context.Context, records interface{}, query Query, options ...PaginateOption) (Page, error)
+
+	// MustPaginate records that match the query using keyset pagination.
+	// It'll panic if any error eccured.
+	MustPaginate(ctx context.Context, records interface{}, query Query, options ...PaginateOption) Page
+
 	// Insert a record to database.
 	Insert(ctx context.Context, record interface{}, mutators ...Mutator) error
 
@@ -149,7 +251,9 @@ type Repository interface {
 
 	// Transaction performs transaction with given function argument.
 	// Transaction scope/connection is automatically passed using context.
-	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+	// Options such as isolation level and read only mode requires adapter that implements TransactionBeginner,
+	// and cannot be used in nested transaction. Use Retry option to retry transaction on concurrency error.
+	Transaction(ctx context.Context, fn func(ctx context.Context) error, options ...TransactionOption) error
 }
 
 type repository struct {
@@ -175,7 +279,121 @@ func (r repository) Iterate(ctx context.Context, query Query, options ...Iterato
 		cw = fetchContext(ctx, r.rootAdapter)
 	)
 
-	return newIterator(cw.ctx, cw.adapter, query, options)
+	it := newIterator(cw.ctx, cw.adapter, query, options)
+	it.preload = r.preload
+
+	return it
+}
+
+func (r repository) IterateParallel(ctx context.Context, query Query, workers int, fn interface{}, options ...IteratorOption) error {
+	finish := r.instrumenter.Observe(ctx, "rel-iterate-parallel", "iterating records in parallel")
+	defer finish(nil)
+
+	if workers <= 0 {
+		panic("rel: workers must be greater than zero")
+	}
+
+	var (
+		call         = newIterateFunc(fn)
+		record       = call.record()
+		primaryField = "id"
+	)
+
+	if _, ok := record.(*Map); !ok {
+		doc := NewDocument(record)
+		if query.Table == "" {
+			query.Table = doc.Table()
+		}
+
+		if fields := doc.PrimaryFields(); len(fields) == 1 {
+			primaryField = fields[0]
+		} else {
+			panic("rel: parallel iteration requires a single integer primary field")
+		}
+
+		if typ, _ := doc.Type(primaryField); typ == nil || !isInteger(typ.Kind()) {
+			panic("rel: parallel iteration requires a single integer primary field")
+		}
+	} else if query.Table == "" {
+		panic("rel: table is required to iterate into map, use rel.From to specify it")
+	}
+
+	var (
+		min = Min(primaryField)
+		max = Max(primaryField)
+	)
+
+	bounds, err := r.Aggregates(ctx, query, min, max)
+	if err != nil || bounds[min.Name()] == nil {
+		return err
+	}
+
+	var (
+		wg         sync.WaitGroup
+		once       sync.Once
+		firstErr   error
+		cctx, stop = context.WithCancel(ctx)
+		cw         = fetchContext(cctx, r.rootAdapter)
+		parts      = partitions(bounds.Int(min.Name()), bounds.Int(max.Name()), workers)
+	)
+
+	defer stop()
+
+	for j := range parts {
+		wg.Add(1)
+		go func(j int) {
+			defer wg.Done()
+
+			if err := r.iteratePartition(cw, query, j, parts, call, options); err != nil {
+				once.Do(func() {
+					firstErr = err
+					stop()
+				})
+			}
+		}(j)
+	}
+
+	wg.Wait()
+	return firstErr
+}
+
+func (r repository) iteratePartition(cw contextWrapper, query Query, j int, parts []partition, call iterateFunc, options []IteratorOption) error {
+	var (
+		err    error
+		count  int
+		part   = parts[j]
+		finish = r.instrumenter.Observe(cw.ctx, "rel-iterate-partition",
+			fmt.Sprintf("iterating partition %d of %d [%d, %d]", j+1, len(parts), part.start, part.finish))
+		it = newIterator(cw.ctx, cw.adapter, query, append(options[:len(options):len(options)], part))
+	)
+
+	it.preload = r.preload
+
+	defer func() { finish(err) }()
+	defer it.Close()
+
+	for {
+		record := call.record()
+		if err = it.Next(record); err == io.EOF {
+			err = nil
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err = cw.ctx.Err(); err != nil {
+			return err
+		}
+
+		if err = call.call(cw.ctx, record); err != nil {
+			return err
+		}
+
+		if count++; count%it.batchSize == 0 {
+			r.instrumenter.Observe(cw.ctx, "rel-iterate-progress",
+				fmt.Sprintf("partition %d of %d processed %d records", j+1, len(parts), count))(nil)
+		}
+	}
 }
 
 func (r repository) Aggregate(ctx context.Context, query Query, aggregate string, field string) (int, error) {
@@ -204,6 +422,79 @@ func (r repository) MustAggregate(ctx context.Context, query Query, aggregate st
 	return result
 }
 
+func (r repository) Aggregates(ctx context.Context, query Query, aggregations ...Aggregation) (AggregateResult, error) {
+	finish := r.instrumenter.Observe(ctx, "rel-aggregates", "aggregating records")
+	defer finish(nil)
+
+	var (
+		cw = fetchContext(ctx, r.rootAdapter)
+	)
+
+	query.GroupQuery = GroupQuery{}
+	query.LimitQuery = 0
+	query.OffsetQuery = 0
+	query.SortQuery = nil
+	query.SelectQuery = SelectQuery{Fields: []string{}, Expressions: aggregationExprs(aggregations)}
+
+	results, err := r.aggregateAll(cw, query)
+	if err != nil || len(results) == 0 {
+		return AggregateResult{}, err
+	}
+
+	return results[0], nil
+}
+
+func (r repository) MustAggregates(ctx context.Context, query Query, aggregations ...Aggregation) AggregateResult {
+	result, err := r.Aggregates(ctx, query, aggregations...)
+	must(err)
+	return result
+}
+
+func (r repository) AggregateGroups(ctx context.Context, query Query, aggregations ...Aggregation) ([]AggregateResult, error) {
+	finish := r.instrumenter.Observe(ctx, "rel-aggregate-groups", "aggregating groups")
+	defer finish(nil)
+
+	var (
+		cw    = fetchContext(ctx, r.rootAdapter)
+		exprs = aggregationExprs(aggregations)
+	)
+
+	query.SelectQuery = SelectQuery{
+		Fields:      append([]string{}, query.GroupQuery.Fields...),
+		Expressions: exprs,
+	}
+
+	return r.aggregateAll(cw, query)
+}
+
+func (r repository) MustAggregateGroups(ctx context.Context, query Query, aggregations ...Aggregation) []AggregateResult {
+	results, err := r.AggregateGroups(ctx, query, aggregations...)
+	must(err)
+	return results
+}
+
+func (r repository) aggregateAll(cw contextWrapper, query Query) ([]AggregateResult, error) {
+	var (
+		maps []Map
+	)
+
+	cur, err := cw.adapter.Query(cw.ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := scanMaps(cur, &maps); err != nil {
+		return nil, err
+	}
+
+	results := make([]AggregateResult, len(maps))
+	for i := range maps {
+		results[i] = AggregateResult(maps[i])
+	}
+
+	return results, nil
+}
+
 func (r repository) Count(ctx context.Context, collection string, queriers ...Querier) (int, error) {
 	finish := r.instrumenter.Observe(ctx, "rel-count", "aggregating records")
 	defer finish(nil)
@@ -221,10 +512,115 @@ func (r repository) MustCount(ctx context.Context, collection string, queriers .
 	return count
 }
 
+func (r repository) Pluck(ctx context.Context, field string, dest interface{}, queriers ...Querier) error {
+	finish := r.instrumenter.Observe(ctx, "rel-pluck", "plucking a field")
+	defer finish(nil)
+
+	var (
+		cw    = fetchContext(ctx, r.rootAdapter)
+		rv    = reflect.ValueOf(dest)
+		query = Build("", queriers...)
+	)
+
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		panic("rel: pluck destination must be a pointer to slice")
+	}
+
+	if query.Table == "" {
+		panic("rel: table is required to pluck, use rel.From to specify it")
+	}
+
+	// distinct is kept, so unique values can be plucked using Distinct query.
+	query.SelectQuery = SelectQuery{OnlyDistinct: query.SelectQuery.OnlyDistinct, Fields: []string{field}}
+
+	cur, err := cw.adapter.Query(cw.ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return scanPluck(cur, rv.Elem())
+}
+
+func (r repository) MustPluck(ctx context.Context, field string, dest interface{}, queriers ...Querier) {
+	must(r.Pluck(ctx, field, dest, queriers...))
+}
+
+func (r repository) Exists(ctx context.Context, collection string, queriers ...Querier) (bool, error) {
+	finish := r.instrumenter.Observe(ctx, "rel-exists", "checking record existence")
+	defer finish(nil)
+
+	var (
+		cw    = fetchContext(ctx, r.rootAdapter)
+		query = Build(collection, queriers...)
+	)
+
+	query.SelectQuery = SelectQuery{Fields: []string{"^1"}}
+	query.SortQuery = nil
+
+	cur, err := cw.adapter.Query(cw.ctx, query.Limit(1))
+	if err != nil {
+		return false, err
+	}
+
+	defer cur.Close()
+
+	if _, err := cur.Fields(); err != nil {
+		return false, err
+	}
+
+	return cur.Next(), nil
+}
+
+func (r repository) MustExists(ctx context.Context, collection string, queriers ...Querier) bool {
+	exists, err := r.Exists(ctx, collection, queriers...)
+	must(err)
+	return exists
+}
+
+func (r repository) First(ctx context.Context, record interface{}, queriers ...Querier) error {
+	finish := r.instrumenter.Observe(ctx, "rel-first", "finding first record")
+	defer finish(nil)
+
+	var (
+		cw    = fetchContext(ctx, r.rootAdapter)
+		doc   = NewDocument(record)
+		query = Build(doc.Table(), queriers...).Populate(doc.Meta())
+	)
+
+	query.SortQuery = nil
+	return r.find(cw, doc, query.SortAsc(doc.PrimaryFields()...))
+}
+
+func (r repository) MustFirst(ctx context.Context, record interface{}, queriers ...Querier) {
+	must(r.First(ctx, record, queriers...))
+}
+
+func (r repository) Last(ctx context.Context, record interface{}, queriers ...Querier) error {
+	finish := r.instrumenter.Observe(ctx, "rel-last", "finding last record")
+	defer finish(nil)
+
+	var (
+		cw    = fetchContext(ctx, r.rootAdapter)
+		doc   = NewDocument(record)
+		query = Build(doc.Table(), queriers...).Populate(doc.Meta())
+	)
+
+	query.SortQuery = nil
+	return r.find(cw, doc, query.SortDesc(doc.PrimaryFields()...))
+}
+
+func (r repository) MustLast(ctx context.Context, record interface{}, queriers ...Querier) {
+	must(r.Last(ctx, record, queriers...))
+}
+
 func (r repository) Find(ctx context.Context, record interface{}, queriers ...Querier) error {
 	finish := r.instrumenter.Observe(ctx, "rel-find", "finding a record")
 	defer finish(nil)
 
+	if m, ok := record.(*Map); ok {
+		return r.findMap(fetchContext(ctx, r.rootAdapter), m, buildMapQuery(queriers))
+	}
+
 	var (
 		cw    = fetchContext(ctx, r.rootAdapter)
 		doc   = NewDocument(record)
@@ -258,13 +654,17 @@ func (r repository) find(cw contextWrapper, doc *Document, query Query) error {
 		}
 	}
 
-	return nil
+	return afterFind(cw.ctx, doc)
 }
 
 func (r repository) FindAll(ctx context.Context, records interface{}, queriers ...Querier) error {
 	finish := r.instrumenter.Observe(ctx, "rel-find-all", "finding all records")
 	defer finish(nil)
 
+	if maps, ok := records.(*[]Map); ok {
+		return r.findMaps(fetchContext(ctx, r.rootAdapter), maps, buildMapQuery(queriers))
+	}
+
 	var (
 		cw    = fetchContext(ctx, r.rootAdapter)
 		col   = NewCollection(records)
@@ -300,7 +700,43 @@ func (r repository) findAll(cw contextWrapper, col *Collection, query Query) err
 		}
 	}
 
-	return nil
+	return afterFind(cw.ctx, col)
+}
+
+func (r repository) findMap(cw contextWrapper, m *Map, query Query) error {
+	cur, err := cw.adapter.Query(cw.ctx, query.Limit(1))
+	if err != nil {
+		return err
+	}
+
+	finish := r.instrumenter.Observe(cw.ctx, "rel-scan-one", "scanning a record")
+	err = scanMap(cur, m)
+	finish(err)
+
+	return err
+}
+
+func (r repository) findMaps(cw contextWrapper, maps *[]Map, query Query) error {
+	cur, err := cw.adapter.Query(cw.ctx, query)
+	if err != nil {
+		return err
+	}
+
+	finish := r.instrumenter.Observe(cw.ctx, "rel-scan-all", "scanning all records")
+	err = scanMaps(cur, maps)
+	finish(err)
+
+	return err
+}
+
+// buildMapQuery builds query for map destination, which requires table to be specified in the query.
+func buildMapQuery(queriers []Querier) Query {
+	query := Build("", queriers...)
+	if query.Table == "" {
+		panic("rel: table is required to find into map, use rel.From to specify it")
+	}
+
+	return query
 }
 
 func (r repository) FindAndCountAll(ctx context.Context, records interface{}, queriers ...Querier) (int, error) {
@@ -329,6 +765,86 @@ func (r repository) MustFindAndCountAll(ctx context.Context, records interface{}
 	return count
 }
 
+func (r repository) Scan(ctx context.Context, dest interface{}, query Query, options ...ScanOption) error {
+	finish := r.instrumenter.Observe(ctx, "rel-scan", "scanning a result")
+	defer finish(nil)
+
+	var (
+		cw  = fetchContext(ctx, r.rootAdapter)
+		doc = NewDocument(dest)
+	)
+
+	cur, err := cw.adapter.Query(cw.ctx, query.Limit(1))
+	if err != nil {
+		return err
+	}
+
+	return scanOne(cur, doc, options...)
+}
+
+func (r repository) MustScan(ctx context.Context, dest interface{}, query Query, options ...ScanOption) {
+	must(r.Scan(ctx, dest, query, options...))
+}
+
+func (r repository) ScanAll(ctx context.Context, dest interface{}, query Query, options ...ScanOption) error {
+	finish := r.instrumenter.Observe(ctx, "rel-scan-all", "scanning all results")
+	defer finish(nil)
+
+	var (
+		cw  = fetchContext(ctx, r.rootAdapter)
+		col = NewCollection(dest)
+	)
+
+	col.Reset()
+
+	cur, err := cw.adapter.Query(cw.ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return scanAll(cur, col, options...)
+}
+
+func (r repository) MustScanAll(ctx context.Context, dest interface{}, query Query, options ...ScanOption) {
+	must(r.ScanAll(ctx, dest, query, options...))
+}
+
+func (r repository) Paginate(ctx context.Context, records interface{}, query Query, options ...PaginateOption) (Page, error) {
+	finish := r.instrumenter.Observe(ctx, "rel-paginate", "paginating records")
+	defer finish(nil)
+
+	var (
+		cw  = fetchContext(ctx, r.rootAdapter)
+		col = NewCollection(records)
+		p   = newPaginator(query, options)
+	)
+
+	if len(p.secret) == 0 {
+		return Page{}, ErrPageSecretRequired
+	}
+
+	query = Build(col.Table(), query).Populate(col.Meta())
+	col.Reset()
+
+	keys := p.keys(query, col.PrimaryFields())
+	query, err := p.build(query, keys)
+	if err != nil {
+		return Page{}, err
+	}
+
+	if err := r.findAll(cw, col, query); err != nil {
+		return Page{}, err
+	}
+
+	return p.page(col, keys)
+}
+
+func (r repository) MustPaginate(ctx context.Context, records interface{}, query Query, options ...PaginateOption) Page {
+	page, err := r.Paginate(ctx, records, query, options...)
+	must(err)
+	return page
+}
+
 func (r repository) Insert(ctx context.Context, record interface{}, mutators ...Mutator) error {
 	finish := r.instrumenter.Observe(ctx, "rel-insert", "inserting a record")
 	defer finish(nil)
@@ -343,7 +859,7 @@ func (r repository) Insert(ctx context.Context, record interface{}, mutators ...
 		mutation = Apply(doc, mutators...)
 	)
 
-	if !mutation.IsAssocEmpty() && mutation.Cascade == true {
+	if (!mutation.IsAssocEmpty() && mutation.Cascade == true) || hasInsertHook(doc.meta) {
 		return r.transaction(cw, func(cw contextWrapper) error {
 			return r.insert(cw, doc, mutation)
 		})
@@ -359,6 +875,10 @@ func (r repository) insert(cw contextWrapper, doc *Document, mutation Mutation)
 		queriers = Build(doc.Table())
 	)
 
+	if err := beforeInsert(cw.ctx, doc, &mutation); err != nil {
+		return err
+	}
+
 	if mutation.Cascade {
 		if err := r.saveBelongsTo(cw, doc, &mutation); err != nil {
 			return err
@@ -389,7 +909,7 @@ func (r repository) insert(cw contextWrapper, doc *Document, mutation Mutation)
 		}
 	}
 
-	return nil
+	return afterInsert(cw.ctx, doc)
 }
 
 func (r repository) MustInsert(ctx context.Context, record interface{}, mutators ...Mutator) {
@@ -420,6 +940,12 @@ func (r repository) InsertAll(ctx context.Context, records interface{}, mutators
 		}
 	}
 
+	if hasInsertHook(col.meta) {
+		return r.transaction(cw, func(cw contextWrapper) error {
+			return r.insertAll(cw, col, muts)
+		})
+	}
+
 	return r.insertAll(cw, col, muts)
 }
 
@@ -443,6 +969,14 @@ func (r repository) insertAll(cw contextWrapper, col *Collection, mutation []Mut
 		bulkMutates = make([]map[string]Mutate, len(mutation))
 	)
 
+	if hasInsertHook(col.meta) {
+		for i := range mutation {
+			if err := beforeInsert(cw.ctx, col.Get(i), &mutation[i]); err != nil {
+				return err
+			}
+		}
+	}
+
 	// TODO: baypassable if it's predictable.
 	for i := range mutation {
 		for field := range mutation[i].Mutates {
@@ -470,6 +1004,14 @@ func (r repository) insertAll(cw contextWrapper, col *Collection, mutation []Mut
 		}
 	}
 
+	if hasInsertHook(col.meta) {
+		for i := range mutation {
+			if err := afterInsert(cw.ctx, col.Get(i)); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -488,7 +1030,7 @@ func (r repository) Update(ctx context.Context, record interface{}, mutators ...
 		mutation = Apply(doc, mutators...)
 	)
 
-	if !mutation.IsAssocEmpty() && mutation.Cascade == true {
+	if (!mutation.IsAssocEmpty() && mutation.Cascade == true) || hasUpdateHook(doc.meta) {
 		return r.transaction(cw, func(cw contextWrapper) error {
 			return r.update(cw, doc, mutation, filter)
 		})
@@ -510,6 +1052,10 @@ func (r repository) lockVersion(doc Document, unscoped Unscoped) (int, bool) {
 }
 
 func (r repository) update(cw contextWrapper, doc *Document, mutation Mutation, filter FilterQuery) error {
+	if err := beforeUpdate(cw.ctx, doc, &mutation); err != nil {
+		return err
+	}
+
 	if mutation.Cascade {
 		if err := r.saveBelongsTo(cw, doc, &mutation); err != nil {
 			return err
@@ -532,7 +1078,7 @@ func (r repository) update(cw contextWrapper, doc *Document, mutation Mutation,
 		}
 	}
 
-	return nil
+	return afterUpdate(cw.ctx, doc)
 }
 
 func (r repository) applyMutates(cw contextWrapper, doc *Document, mutation Mutation, filter FilterQuery) (dbErr error) {
@@ -810,7 +1356,7 @@ func (r repository) Delete(ctx context.Context, record interface{}, mutators ...
 		mutation = applyMutators(nil, false, false, mutators...)
 	)
 
-	if mutation.Cascade {
+	if bool(mutation.Cascade) || hasDeleteHook(doc.meta) {
 		return r.transaction(cw, func(cw contextWrapper) error {
 			return r.delete(cw, doc, filterDocument(doc), mutation)
 		})
@@ -831,6 +1377,10 @@ func (r repository) delete(cw contextWrapper, doc *Document, filter FilterQuery,
 		query = Build(table, filters...).Populate(doc.Meta())
 	)
 
+	if err := beforeDelete(cw.ctx, doc); err != nil {
+		return err
+	}
+
 	if mutation.Cascade {
 		if err := r.deleteHasOne(cw, doc, true); err != nil {
 			return err
@@ -852,6 +1402,10 @@ func (r repository) delete(cw contextWrapper, doc *Document, filter FilterQuery,
 		}
 	}
 
+	if err == nil {
+		err = afterDelete(cw.ctx, doc)
+	}
+
 	return err
 }
 
@@ -923,7 +1477,7 @@ func (r repository) deleteHasMany(cw contextWrapper, doc *Document) error {
 				filter = Eq(fField, rValue).And(filterCollection(col))
 			)
 
-			if _, err := r.deleteAny(cw, col.meta.flag, Build(table, filter).Populate(doc.Meta())); err != nil {
+			if err := r.deleteAll(cw, col, Build(table, filter).Populate(doc.Meta())); err != nil {
 				return err
 			}
 		}
@@ -949,12 +1503,34 @@ func (r repository) DeleteAll(ctx context.Context, records interface{}) error {
 		return nil
 	}
 
-	var (
-		query  = Build(col.Table(), filterCollection(col)).Populate(col.Meta())
-		_, err = r.deleteAny(cw, col.meta.flag, query)
-	)
+	query := Build(col.Table(), filterCollection(col)).Populate(col.Meta())
 
-	return err
+	if hasDeleteHook(col.meta) {
+		return r.transaction(cw, func(cw contextWrapper) error {
+			return r.deleteAll(cw, col, query)
+		})
+	}
+
+	return r.deleteAll(cw, col, query)
+}
+
+func (r repository) deleteAll(cw contextWrapper, col *Collection, query Query) error {
+	hooked := hasDeleteHook(col.meta)
+	if hooked {
+		if err := eachDocument(col, func(doc *Document) error { return beforeDelete(cw.ctx, doc) }); err != nil {
+			return err
+		}
+	}
+
+	if _, err := r.deleteAny(cw, col.meta.flag, query); err != nil {
+		return err
+	}
+
+	if hooked {
+		return eachDocument(col, func(doc *Document) error { return afterDelete(cw.ctx, doc) })
+	}
+
+	return nil
 }
 
 func (r repository) MustDeleteAll(ctx context.Context, records interface{}) {
@@ -1072,6 +1648,14 @@ func (r repository) preload(cw contextWrapper, records slice, field string, quer
 		}
 	}
 
+	for _, target := range targets {
+		for i := range target {
+			if err := afterFind(cw.ctx, target[i]); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -1194,10 +1778,20 @@ func (r repository) withDefaultScope(meta DocumentMeta, query Query, preload boo
 		return query
 	}
 
-	if meta.flag.Is(HasDeleted) {
-		query = query.Where(Eq("deleted", false))
-	} else if meta.flag.Is(HasDeletedAt) {
-		query = query.Where(Nil("deleted_at"))
+	query = softDeleteScope(meta, query)
+
+	// every combined query returns the same record, so it's scoped as well.
+	if len(query.CombineQuery) > 0 {
+		combines := make([]CombineQuery, len(query.CombineQuery))
+		for i, cq := range query.CombineQuery {
+			if !cq.Query.UnscopedQuery {
+				cq.Query = softDeleteScope(meta, cq.Query)
+			}
+
+			combines[i] = cq
+		}
+
+		query.CombineQuery = combines
 	}
 
 	if preload && bool(query.CascadeQuery) {
@@ -1207,6 +1801,16 @@ func (r repository) withDefaultScope(meta DocumentMeta, query Query, preload boo
 	return query
 }
 
+func softDeleteScope(meta DocumentMeta, query Query) Query {
+	if meta.flag.Is(HasDeleted) {
+		return query.Where(Eq("deleted", false))
+	} else if meta.flag.Is(HasDeletedAt) {
+		return query.Where(Nil("deleted_at"))
+	}
+
+	return query
+}
+
 // Exec raw statement.
 // Returns last inserted id, rows affected and error.
 func (r repository) Exec(ctx context.Context, stmt string, args ...interface{}) (int, int, error) {
@@ -1222,7 +1826,7 @@ func (r repository) MustExec(ctx context.Context, stmt string, args ...interface
 	return lastInsertedId, rowsAffected
 }
 
-func (r repository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+func (r repository) Transaction(ctx context.Context, fn func(ctx context.Context) error, options ...TransactionOption) error {
 	finish := r.instrumenter.Observe(ctx, "rel-transaction", "transaction")
 	defer finish(nil)
 
@@ -1232,42 +1836,113 @@ func (r repository) Transaction(ctx context.Context, fn func(ctx context.Context
 
 	return r.transaction(cw, func(cw contextWrapper) error {
 		return fn(cw.ctx)
-	})
+	}, options...)
 }
 
-func (r repository) transaction(cw contextWrapper, fn func(cw contextWrapper) error) error {
-	adp, err := cw.adapter.Begin(cw.ctx)
+func (r repository) transaction(cw contextWrapper, fn func(cw contextWrapper) error, options ...TransactionOption) error {
+	config := applyTransactionOptions(options)
+	if !config.none() && cw.inTransaction() {
+		return ErrNestedTransactionOption
+	}
+
+	if sp, ok := cw.adapter.(Savepointer); ok && cw.inTransaction() {
+		return r.savepoint(cw, sp, fn)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := r.begin(cw, config.options, fn)
+		if !config.shouldRetry(err, attempt) {
+			return err
+		}
+
+		if err := config.wait(cw.ctx, attempt); err != nil {
+			return err
+		}
+	}
+}
+
+// begin transaction using options when specified, and run fn inside it.
+func (r repository) begin(cw contextWrapper, opts TransactionOptions, fn func(cw contextWrapper) error) error {
+	var (
+		adp Adapter
+		err error
+	)
+
+	if opts.None() {
+		adp, err = cw.adapter.Begin(cw.ctx)
+	} else if tb, ok := cw.adapter.(TransactionBeginner); ok {
+		adp, err = tb.BeginWith(cw.ctx, opts)
+	} else {
+		err = ErrTransactionOptionNotSupported
+	}
+
 	if err != nil {
 		return err
 	}
 
+	var (
+		ctx = cw.ctx
+	)
+
 	// wrap trx adapter to new context.
 	cw = wrapContext(cw.ctx, adp)
 
-	func() {
-		defer func() {
-			if p := recover(); p != nil {
-				_ = cw.adapter.Rollback(cw.ctx)
-
-				switch e := p.(type) {
-				case runtime.Error:
-					panic(e)
-				case error:
-					err = e
-				default:
-					panic(e)
-				}
-			} else if err != nil {
-				_ = cw.adapter.Rollback(cw.ctx)
-			} else {
-				err = cw.adapter.Commit(cw.ctx)
-			}
-		}()
+	return runTransaction(ctx, cw, fn, func() error {
+		return cw.adapter.Commit(cw.ctx)
+	}, func() {
+		_ = cw.adapter.Rollback(cw.ctx)
+	})
+}
+
+// savepoint performs nested transaction using savepoint.
+func (r repository) savepoint(cw contextWrapper, sp Savepointer, fn func(cw contextWrapper) error) error {
+	ctx := cw.ctx
+	cw, name := cw.savepoint()
+
+	if err := sp.Savepoint(cw.ctx, name); err != nil {
+		return err
+	}
 
-		err = fn(cw)
+	return runTransaction(ctx, cw, fn, func() error {
+		return sp.Release(cw.ctx, name)
+	}, func() {
+		_ = sp.RollbackTo(cw.ctx, name)
+	})
+}
+
+// runTransaction calls fn, then commits when it succeed or rollback when it returns error or panic.
+// ctx is the context before the transaction started, it's used to call the queued callbacks.
+func runTransaction(ctx context.Context, cw contextWrapper, fn func(cw contextWrapper) error, commit func() error, rollback func()) (err error) {
+	var (
+		parent, _ = ctx.Value(callbackKey).(*transactionCallbacks)
+		callbacks = &transactionCallbacks{}
+	)
+
+	cw.ctx = context.WithValue(cw.ctx, callbackKey, callbacks)
+
+	defer func() {
+		if p := recover(); p != nil {
+			rollback()
+			callbacks.finish(ctx, parent, false)
+
+			switch e := p.(type) {
+			case runtime.Error:
+				panic(e)
+			case error:
+				err = e
+			default:
+				panic(e)
+			}
+		} else if err != nil {
+			rollback()
+			callbacks.finish(ctx, parent, false)
+		} else {
+			err = commit()
+			callbacks.finish(ctx, parent, err == nil)
+		}
 	}()
 
-	return err
+	return fn(cw)
 }
 
 // New create new repo using adapter.
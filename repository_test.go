@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sync"
 	"testing"
 	"time"
 
@@ -35,6 +36,22 @@ func createCursor(row int) *testCursor {
 	return cur
 }
 
+func createIDCursor(ids ...int) *testCursor {
+	cur := &testCursor{}
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id"}, nil).Once()
+
+	for _, id := range ids {
+		cur.On("Next").Return(true).Once()
+		cur.MockScan(id).Once()
+	}
+
+	cur.On("Next").Return(false).Once()
+
+	return cur
+}
+
 func TestNew(t *testing.T) {
 	var (
 		ctx     = context.TODO()
@@ -101,6 +118,237 @@ func TestRepository_Iterate(t *testing.T) {
 	adapter.AssertExpectations(t)
 }
 
+func TestRepository_Iterate_preload(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		cur1    = createCursor(2)
+		cur2    = createCursor(0)
+		userID  = 10
+		address = Address{ID: 100, UserID: &userID}
+		acur    = &testCursor{}
+		result  []User
+	)
+
+	adapter.On("Query", query.SortAsc("id").Limit(2)).Return(cur1, nil).Once()
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(acur, nil).Once()
+	adapter.On("Query", query.Where(Gt("id", 10)).SortAsc("id").Limit(2)).Return(cur2, nil).Once()
+
+	acur.On("Close").Return(nil).Once()
+	acur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	acur.On("Next").Return(true).Once()
+	acur.MockScan(address.ID, *address.UserID).Times(3)
+	acur.On("Next").Return(false).Once()
+
+	it := repo.Iterate(context.TODO(), query, BatchSize(2), IteratePreload("address"))
+	for {
+		var user User
+		if err := it.Next(&user); err == io.EOF {
+			break
+		} else {
+			assert.Nil(t, err)
+		}
+
+		result = append(result, user)
+	}
+	it.Close()
+
+	assert.Len(t, result, 2)
+	for _, user := range result {
+		assert.Equal(t, 10, user.ID)
+		assert.Equal(t, address, user.Address)
+	}
+
+	adapter.AssertExpectations(t)
+	cur1.AssertExpectations(t)
+	cur2.AssertExpectations(t)
+	acur.AssertExpectations(t)
+}
+
+func TestRepository_Iterate_preloadError(t *testing.T) {
+	var (
+		user    User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		cur     = createCursor(1)
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", query.SortAsc("id").Limit(1000)).Return(cur, nil).Once()
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(&testCursor{}, err).Once()
+
+	it := repo.Iterate(context.TODO(), query, IteratePreload("address"))
+	assert.Equal(t, err, it.Next(&user))
+	it.Close()
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Iterate_preloadMap(t *testing.T) {
+	var (
+		record  Map
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		cur     = createCursor(1)
+	)
+
+	adapter.On("Query", query.SortAsc("id").Limit(1000)).Return(cur, nil).Once()
+
+	it := repo.Iterate(context.TODO(), query, IteratePreload("address"))
+	defer it.Close()
+
+	assert.PanicsWithValue(t, "rel: cannot preload association when iterating into map", func() {
+		_ = it.Next(&record)
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_IterateParallel(t *testing.T) {
+	var (
+		mutex   sync.Mutex
+		ops     []string
+		count   int
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		bounds  = &testCursor{}
+		cur1    = createIDCursor(1, 2)
+		cur2    = createIDCursor(3)
+		cur3    = createIDCursor(4, 5)
+		cur4    = createIDCursor(6)
+	)
+
+	repo.Instrumentation(func(ctx context.Context, op string, message string) func(err error) {
+		mutex.Lock()
+		ops = append(ops, op)
+		mutex.Unlock()
+		return func(err error) {}
+	})
+
+	bounds.On("Close").Return(nil).Once()
+	bounds.On("Fields").Return([]string{"min_id", "max_id"}, nil).Once()
+	bounds.On("Next").Return(true).Once()
+	bounds.MockScan(int64(1), int64(6)).Once()
+	bounds.On("Next").Return(false).Once()
+
+	adapter.On("Query", query.Select([]string{}...).SelectExpr(Expr("min", "id").As("min_id"), Expr("max", "id").As("max_id"))).Return(bounds, nil).Once()
+	adapter.On("Query", query.Where(Gte("id", 1).AndLte("id", 3)).SortAsc("id").Limit(2)).Return(cur1, nil).Once()
+	adapter.On("Query", query.Where(Gte("id", 1).AndLte("id", 3).AndGt("id", 2)).SortAsc("id").Limit(2)).Return(cur2, nil).Once()
+	adapter.On("Query", query.Where(Gte("id", 4).AndLte("id", 6)).SortAsc("id").Limit(2)).Return(cur3, nil).Once()
+	adapter.On("Query", query.Where(Gte("id", 4).AndLte("id", 6).AndGt("id", 5)).SortAsc("id").Limit(2)).Return(cur4, nil).Once()
+
+	err := repo.IterateParallel(context.TODO(), query, 2, func(ctx context.Context, user *User) error {
+		assert.NotZero(t, user.ID)
+
+		mutex.Lock()
+		count++
+		mutex.Unlock()
+		return nil
+	}, BatchSize(2), Start(100), SortBy(SortDesc("age")))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 6, count)
+	assert.ElementsMatch(t, []string{
+		"rel-iterate-parallel", "rel-aggregates",
+		"rel-iterate-partition", "rel-iterate-progress",
+		"rel-iterate-partition", "rel-iterate-progress",
+	}, ops)
+
+	// the last next is not called because it's already refetched.
+	// call here to make expectation pass.
+	cur1.Next()
+	cur3.Next()
+
+	adapter.AssertExpectations(t)
+	bounds.AssertExpectations(t)
+	cur1.AssertExpectations(t)
+	cur2.AssertExpectations(t)
+	cur3.AssertExpectations(t)
+	cur4.AssertExpectations(t)
+}
+
+func TestRepository_IterateParallel_empty(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		bounds  = &testCursor{}
+	)
+
+	bounds.On("Close").Return(nil).Once()
+	bounds.On("Fields").Return([]string{"min_id", "max_id"}, nil).Once()
+	bounds.On("Next").Return(true).Once()
+	bounds.MockScan(nil, nil).Once()
+	bounds.On("Next").Return(false).Once()
+
+	adapter.On("Query", query.Select([]string{}...).SelectExpr(Expr("min", "id").As("min_id"), Expr("max", "id").As("max_id"))).Return(bounds, nil).Once()
+
+	err := repo.IterateParallel(context.TODO(), query, 4, func(ctx context.Context, user *User) error {
+		t.Fatal("should not be called")
+		return nil
+	})
+
+	assert.Nil(t, err)
+	adapter.AssertExpectations(t)
+	bounds.AssertExpectations(t)
+}
+
+func TestRepository_IterateParallel_error(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		bounds  = &testCursor{}
+		cur     = createCursor(2)
+		err     = errors.New("error")
+	)
+
+	bounds.On("Close").Return(nil).Once()
+	bounds.On("Fields").Return([]string{"min_id", "max_id"}, nil).Once()
+	bounds.On("Next").Return(true).Once()
+	bounds.MockScan(int64(1), int64(2)).Once()
+	bounds.On("Next").Return(false).Once()
+
+	adapter.On("Query", query.Select([]string{}...).SelectExpr(Expr("min", "id").As("min_id"), Expr("max", "id").As("max_id"))).Return(bounds, nil).Once()
+	adapter.On("Query", query.Where(Gte("id", 1).AndLte("id", 2)).SortAsc("id").Limit(1000)).Return(cur, nil).Once()
+
+	perr := repo.IterateParallel(context.TODO(), query, 1, func(ctx context.Context, user *User) error {
+		return err
+	})
+
+	assert.Equal(t, err, perr)
+	adapter.AssertExpectations(t)
+	bounds.AssertExpectations(t)
+}
+
+func TestRepository_IterateParallel_panic(t *testing.T) {
+	var (
+		repo  = New(&testAdapter{})
+		query = From("users")
+	)
+
+	assert.PanicsWithValue(t, "rel: workers must be greater than zero", func() {
+		_ = repo.IterateParallel(context.TODO(), query, 0, func(ctx context.Context, user *User) error { return nil })
+	})
+
+	assert.PanicsWithValue(t, "rel: fn must be a func(context.Context, *T) error", func() {
+		_ = repo.IterateParallel(context.TODO(), query, 1, func(user *User) error { return nil })
+	})
+
+	assert.PanicsWithValue(t, "rel: parallel iteration requires a single integer primary field", func() {
+		_ = repo.IterateParallel(context.TODO(), query, 1, func(ctx context.Context, follow *Follow) error { return nil })
+	})
+
+	assert.PanicsWithValue(t, "rel: table is required to iterate into map, use rel.From to specify it", func() {
+		_ = repo.IterateParallel(context.TODO(), Query{}, 1, func(ctx context.Context, record *Map) error { return nil })
+	})
+}
+
 func TestRepository_Aggregate(t *testing.T) {
 	var (
 		adapter   = &testAdapter{}
@@ -138,6 +386,110 @@ func TestRepository_MustAggregate(t *testing.T) {
 	adapter.AssertExpectations(t)
 }
 
+func TestRepository_Aggregates(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Where(Gt("age", 20)).Group("gender").SortAsc("id").Limit(10).Offset(10)
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"count", "avg_age"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(int64(3), 27.5).Once()
+	cur.On("Next").Return(false).Once()
+
+	adapter.On("Query", From("users").Where(Gt("age", 20)).Select([]string{}...).SelectExpr(Expr("count").As("count"), Expr("avg", "age").As("avg_age"))).Return(cur, nil).Once()
+
+	result, err := repo.Aggregates(context.TODO(), query, Count("*"), Avg("age"))
+	assert.Nil(t, err)
+	assert.Equal(t, AggregateResult{"count": int64(3), "avg_age": 27.5}, result)
+	assert.Equal(t, 3, result.Int("count"))
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Aggregates_error(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", query.Select([]string{}...).SelectExpr(Expr("sum", "age").As("sum_age"))).Return(&testCursor{}, err).Once()
+
+	result, aerr := repo.Aggregates(context.TODO(), query, Sum("age"))
+	assert.Equal(t, err, aerr)
+	assert.Equal(t, AggregateResult{}, result)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_MustAggregates(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", query.Select([]string{}...).SelectExpr(Expr("max", "age").As("max_age"))).Return(&testCursor{}, err).Once()
+
+	assert.PanicsWithValue(t, err, func() {
+		repo.MustAggregates(context.TODO(), query, Max("age"))
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_AggregateGroups(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Group("gender").SortDesc("total")
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"gender", "total", "min_age"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan([]byte("male"), int64(2), int64(20)).Once()
+	cur.MockScan([]byte("female"), int64(1), int64(25)).Once()
+	cur.On("Next").Return(false).Once()
+
+	adapter.On("Query", query.Select("gender").SelectExpr(Expr("count").As("total"), Expr("min", "age").As("min_age"))).Return(cur, nil).Once()
+
+	results, err := repo.AggregateGroups(context.TODO(), query, Count("*").As("total"), Min("age"))
+	assert.Nil(t, err)
+	assert.Equal(t, []AggregateResult{
+		{"gender": "male", "total": int64(2), "min_age": int64(20)},
+		{"gender": "female", "total": int64(1), "min_age": int64(25)},
+	}, results)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_MustAggregateGroups(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Group("gender")
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", query.Select("gender").SelectExpr(Expr("count").As("count"))).Return(&testCursor{}, err).Once()
+
+	assert.PanicsWithValue(t, err, func() {
+		repo.MustAggregateGroups(context.TODO(), query, Count("*"))
+	})
+
+	adapter.AssertExpectations(t)
+}
+
 func TestRepository_Count(t *testing.T) {
 	var (
 		adapter = &testAdapter{}
@@ -154,21 +506,286 @@ func TestRepository_Count(t *testing.T) {
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_MustCount(t *testing.T) {
+func TestRepository_MustCount(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+	)
+
+	adapter.On("Aggregate", query, "count", "*").Return(1, nil).Once()
+
+	assert.NotPanics(t, func() {
+		count := repo.MustCount(context.TODO(), "users")
+		assert.Equal(t, 1, count)
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Pluck(t *testing.T) {
+	var (
+		names   []string
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Select("id", "name").Where(Eq("age", 20))
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"name"}, nil).Once()
+	cur.On("Next").Return(true).Times(3)
+	cur.MockScan("Del Piero").Once()
+	cur.MockScan(nil).Once()
+	cur.MockScan("Nedved").Once()
+	cur.On("Next").Return(false).Once()
+
+	adapter.On("Query", query.Select("name")).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Pluck(context.TODO(), "name", &names, query))
+	assert.Equal(t, []string{"Del Piero", "", "Nedved"}, names)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Pluck_ptrElemDistinct(t *testing.T) {
+	var (
+		ages    []*int
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Distinct()
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"age"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.On("Scan", mock.Anything).Return(func(scanners ...interface{}) error {
+		*scanners[0].(**int) = nil
+		return nil
+	}).Once()
+	cur.On("Scan", mock.Anything).Return(func(scanners ...interface{}) error {
+		age := 20
+		*scanners[0].(**int) = &age
+		return nil
+	}).Once()
+	cur.On("Next").Return(false).Once()
+
+	adapter.On("Query", query.Select("age").Distinct()).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Pluck(context.TODO(), "age", &ages, query))
+	assert.Len(t, ages, 2)
+	assert.Nil(t, ages[0])
+	assert.Equal(t, 20, *ages[1])
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Pluck_error(t *testing.T) {
+	var (
+		ids     []int
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", query.Select("id")).Return(&testCursor{}, err).Once()
+
+	assert.Equal(t, err, repo.Pluck(context.TODO(), "id", &ids, query))
+	assert.Nil(t, ids)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Pluck_invalid(t *testing.T) {
+	var (
+		ids  []int
+		repo = New(&testAdapter{})
+	)
+
+	assert.PanicsWithValue(t, "rel: pluck destination must be a pointer to slice", func() {
+		repo.Pluck(context.TODO(), "id", ids, From("users"))
+	})
+
+	assert.PanicsWithValue(t, "rel: table is required to pluck, use rel.From to specify it", func() {
+		repo.Pluck(context.TODO(), "id", &ids, Eq("id", 1))
+	})
+}
+
+func TestRepository_MustPluck(t *testing.T) {
+	var (
+		ids     []int
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		cur     = createCursor(2)
+	)
+
+	adapter.On("Query", query.Select("id")).Return(cur, nil).Once()
+
+	assert.NotPanics(t, func() {
+		repo.MustPluck(context.TODO(), "id", &ids, query)
+	})
+	assert.Equal(t, []int{10, 10}, ids)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Exists(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   int
+		result bool
+	}{
+		{"exists", 1, true},
+		{"not exists", 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				adapter = &testAdapter{}
+				repo    = New(adapter)
+				query   = From("users").Where(Eq("age", 20))
+				cur     = &testCursor{}
+			)
+
+			cur.On("Close").Return(nil).Once()
+			cur.On("Fields").Return([]string{"1"}, nil).Once()
+			cur.On("Next").Return(test.rows > 0).Once()
+
+			adapter.On("Query", query.Select("^1").Limit(1)).Return(cur, nil).Once()
+
+			exists, err := repo.Exists(context.TODO(), "users", Eq("age", 20), SortAsc("id"))
+			assert.Nil(t, err)
+			assert.Equal(t, test.result, exists)
+
+			adapter.AssertExpectations(t)
+			cur.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRepository_Exists_error(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		err     = errors.New("error")
+		cur     = &testCursor{}
+	)
+
+	adapter.On("Query", query.Select("^1").Limit(1)).Return(&testCursor{}, err).Once()
+
+	exists, eerr := repo.Exists(context.TODO(), "users")
+	assert.Equal(t, err, eerr)
+	assert.False(t, exists)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{}, err).Once()
+	adapter.On("Query", query.Select("^1").Limit(1)).Return(cur, nil).Once()
+
+	exists, eerr = repo.Exists(context.TODO(), "users")
+	assert.Equal(t, err, eerr)
+	assert.False(t, exists)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_MustExists(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", query.Select("^1").Limit(1)).Return(&testCursor{}, err).Once()
+
+	assert.PanicsWithValue(t, err, func() {
+		repo.MustExists(context.TODO(), "users")
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_First(t *testing.T) {
+	var (
+		user    User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		cur     = createCursor(1)
+	)
+
+	adapter.On("Query", From("users").Where(Gt("age", 20)).SortAsc("id").Limit(1)).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.First(context.TODO(), &user, Gt("age", 20), SortDesc("name")))
+	assert.Equal(t, 10, user.ID)
+	assert.False(t, cur.Next())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_MustFirst(t *testing.T) {
+	var (
+		user    User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		cur     = createCursor(0)
+	)
+
+	adapter.On("Query", From("users").SortAsc("id").Limit(1)).Return(cur, nil).Once()
+
+	assert.PanicsWithValue(t, NotFoundError{}, func() {
+		repo.MustFirst(context.TODO(), &user)
+	})
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Last(t *testing.T) {
+	var (
+		address Address
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		cur     = createCursor(1)
+	)
+
+	adapter.On("Query", From("user_addresses").Where(Nil("deleted_at")).SortDesc("id").Limit(1)).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Last(context.TODO(), &address))
+	assert.Equal(t, 10, address.ID)
+	assert.False(t, cur.Next())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_MustLast(t *testing.T) {
 	var (
+		user    User
 		adapter = &testAdapter{}
 		repo    = New(adapter)
-		query   = From("users")
+		cur     = createCursor(1)
 	)
 
-	adapter.On("Aggregate", query, "count", "*").Return(1, nil).Once()
+	adapter.On("Query", From("users").SortDesc("id").Limit(1)).Return(cur, nil).Once()
 
 	assert.NotPanics(t, func() {
-		count := repo.MustCount(context.TODO(), "users")
-		assert.Equal(t, 1, count)
+		repo.MustLast(context.TODO(), &user)
 	})
+	assert.Equal(t, 10, user.ID)
+	assert.False(t, cur.Next())
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
 func TestRepository_Find(t *testing.T) {
@@ -528,6 +1145,44 @@ func TestRepository_FindAll_withPreload(t *testing.T) {
 	curPreload.AssertExpectations(t)
 }
 
+func TestRepository_FindAll_combinedSoftDelete(t *testing.T) {
+	var (
+		addresses []Address
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		home      = From("user_addresses").Where(Eq("name", "home"))
+		office    = From("user_addresses").Where(Eq("name", "office"))
+		archived  = From("user_addresses").Where(Eq("user_id", 1)).Unscoped()
+		query     = home.Union(office).Except(archived)
+		cur       = createCursor(0)
+	)
+
+	adapter.On("Query", home.Where(Nil("deleted_at")).Union(office.Where(Nil("deleted_at"))).Except(archived)).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &addresses, query))
+	assert.Equal(t, From("user_addresses").Where(Eq("name", "office")), query.CombineQuery[0].Query)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_FindAll_combinedUnscoped(t *testing.T) {
+	var (
+		addresses []Address
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		query     = From("user_addresses").Intersect(From("user_addresses").Where(Eq("name", "home"))).Unscoped()
+		cur       = createCursor(0)
+	)
+
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &addresses, query))
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
 func TestRepository_FindAll_withPreloadPointer(t *testing.T) {
 	var (
 		addresses  []*Address
@@ -619,120 +1274,371 @@ func TestRepository_FindAll_error(t *testing.T) {
 		err     = errors.New("error")
 	)
 
-	adapter.On("Query", query).Return(&testCursor{}, err).Once()
+	adapter.On("Query", query).Return(&testCursor{}, err).Once()
+
+	assert.Equal(t, err, repo.FindAll(context.TODO(), &users, query))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_MustFindAll(t *testing.T) {
+	var (
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Limit(1)
+		cur     = createCursor(2)
+	)
+
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.NotPanics(t, func() {
+		repo.MustFindAll(context.TODO(), &users, query)
+	})
+
+	assert.Len(t, users, 2)
+	assert.Equal(t, 10, users[0].ID)
+	assert.Equal(t, 10, users[1].ID)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Find_map(t *testing.T) {
+	var (
+		record  Map
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Where(Eq("id", 10))
+		cur     = createCursor(1)
+	)
+
+	adapter.On("Query", query.Limit(1)).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Find(context.TODO(), &record, query))
+	assert.Equal(t, Map{"id": 10}, record)
+	assert.False(t, cur.Next())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Find_mapNotFound(t *testing.T) {
+	var (
+		record  Map
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		cur     = createCursor(0)
+	)
+
+	adapter.On("Query", query.Limit(1)).Return(cur, nil).Once()
+
+	assert.Equal(t, NotFoundError{}, repo.Find(context.TODO(), &record, query))
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Find_mapWithoutTable(t *testing.T) {
+	var (
+		record Map
+		repo   = New(&testAdapter{})
+	)
+
+	assert.PanicsWithValue(t, "rel: table is required to find into map, use rel.From to specify it", func() {
+		repo.Find(context.TODO(), &record, Eq("id", 1))
+	})
+}
+
+func TestRepository_FindAll_map(t *testing.T) {
+	var (
+		records = []Map{{"stale": true}}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Limit(2)
+		cur     = createCursor(2)
+	)
+
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.FindAll(context.TODO(), &records, query))
+	assert.Equal(t, []Map{{"id": 10}, {"id": 10}}, records)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_FindAll_mapQueryError(t *testing.T) {
+	var (
+		records []Map
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", query).Return(&testCursor{}, err).Once()
+
+	assert.Equal(t, err, repo.FindAll(context.TODO(), &records, query))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_FindAndCountAll(t *testing.T) {
+	var (
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Limit(10)
+		cur     = createCursor(2)
+	)
+
+	adapter.On("Query", query).Return(cur, nil).Once()
+	adapter.On("Aggregate", query.Limit(0), "count", "*").Return(2, nil).Once()
+
+	count, err := repo.FindAndCountAll(context.TODO(), &users, query)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+	assert.Len(t, users, 2)
+	assert.Equal(t, 10, users[0].ID)
+	assert.Equal(t, 10, users[1].ID)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_FindAndCountAll_softDelete(t *testing.T) {
+	var (
+		addresses []Address
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		query     = From("user_addresses").Limit(10)
+		cur       = createCursor(2)
+	)
+
+	adapter.On("Query", query.Where(Nil("deleted_at"))).Return(cur, nil).Once()
+	adapter.On("Aggregate", query.Where(Nil("deleted_at")).Limit(0), "count", "*").Return(2, nil).Once()
+
+	count, err := repo.FindAndCountAll(context.TODO(), &addresses, query)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+	assert.Len(t, addresses, 2)
+	assert.Equal(t, 10, addresses[0].ID)
+	assert.Equal(t, 10, addresses[1].ID)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_FindAndCountAll_error(t *testing.T) {
+	var (
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Limit(10)
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", query).Return(&testCursor{}, err).Once()
+
+	count, ferr := repo.FindAndCountAll(context.TODO(), &users, query)
+	assert.Equal(t, err, ferr)
+	assert.Equal(t, 0, count)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_MustFindAndCountAll(t *testing.T) {
+	var (
+		users   []User
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Limit(10)
+		cur     = createCursor(2)
+	)
+
+	adapter.On("Query", query).Return(cur, nil).Once()
+	adapter.On("Aggregate", query.Limit(0), "count", "*").Return(2, nil).Once()
+
+	assert.NotPanics(t, func() {
+		count := repo.MustFindAndCountAll(context.TODO(), &users, query)
+		assert.Equal(t, 2, count)
+	})
+
+	assert.Len(t, users, 2)
+	assert.Equal(t, 10, users[0].ID)
+	assert.Equal(t, 10, users[1].ID)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+type userSummary struct {
+	Name  string `db:"name"`
+	Total int    `db:"total"`
+}
+
+func TestRepository_Scan(t *testing.T) {
+	var (
+		summary userSummary
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Select("name", "count(id) AS total").Group("name")
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"name", "total", "extra"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan("Del Piero", 3, "ignored").Once()
+
+	adapter.On("Query", query.Limit(1)).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.Scan(context.TODO(), &summary, query))
+	assert.Equal(t, userSummary{Name: "Del Piero", Total: 3}, summary)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Scan_strict(t *testing.T) {
+	var (
+		summary userSummary
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users").Select("name", "count(id) AS total", "extra").Group("name")
+		cur     = &testCursor{}
+	)
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"name", "total", "extra"}, nil).Once()
+
+	adapter.On("Query", query.Limit(1)).Return(cur, nil).Once()
+
+	err := repo.Scan(context.TODO(), &summary, query, Strict(true))
+	assert.True(t, errors.Is(err, ErrUnknownColumn))
+	assert.Equal(t, "rel: unknown column extra in rel.userSummary", err.Error())
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Scan_notFound(t *testing.T) {
+	var (
+		summary userSummary
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = From("users")
+		cur     = createCursor(0)
+	)
+
+	adapter.On("Query", query.Limit(1)).Return(cur, nil).Once()
 
-	assert.Equal(t, err, repo.FindAll(context.TODO(), &users, query))
+	assert.Equal(t, NotFoundError{}, repo.Scan(context.TODO(), &summary, query))
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_MustFindAll(t *testing.T) {
+func TestRepository_Scan_queryError(t *testing.T) {
 	var (
-		users   []User
+		summary userSummary
 		adapter = &testAdapter{}
 		repo    = New(adapter)
-		query   = From("users").Limit(1)
-		cur     = createCursor(2)
+		query   = From("users")
+		err     = errors.New("error")
 	)
 
-	adapter.On("Query", query).Return(cur, nil).Once()
-
-	assert.NotPanics(t, func() {
-		repo.MustFindAll(context.TODO(), &users, query)
-	})
+	adapter.On("Query", query.Limit(1)).Return(&testCursor{}, err).Once()
 
-	assert.Len(t, users, 2)
-	assert.Equal(t, 10, users[0].ID)
-	assert.Equal(t, 10, users[1].ID)
+	assert.Equal(t, err, repo.Scan(context.TODO(), &summary, query))
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_FindAndCountAll(t *testing.T) {
+func TestRepository_MustScan(t *testing.T) {
 	var (
-		users   []User
+		summary userSummary
 		adapter = &testAdapter{}
 		repo    = New(adapter)
-		query   = From("users").Limit(10)
-		cur     = createCursor(2)
+		query   = From("users")
+		err     = errors.New("error")
 	)
 
-	adapter.On("Query", query).Return(cur, nil).Once()
-	adapter.On("Aggregate", query.Limit(0), "count", "*").Return(2, nil).Once()
+	adapter.On("Query", query.Limit(1)).Return(&testCursor{}, err).Once()
 
-	count, err := repo.FindAndCountAll(context.TODO(), &users, query)
-	assert.Nil(t, err)
-	assert.Equal(t, 2, count)
-	assert.Len(t, users, 2)
-	assert.Equal(t, 10, users[0].ID)
-	assert.Equal(t, 10, users[1].ID)
+	assert.PanicsWithValue(t, err, func() {
+		repo.MustScan(context.TODO(), &summary, query)
+	})
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_FindAndCountAll_softDelete(t *testing.T) {
+func TestRepository_ScanAll(t *testing.T) {
 	var (
-		addresses []Address
+		summaries = []userSummary{{Name: "stale"}}
 		adapter   = &testAdapter{}
 		repo      = New(adapter)
-		query     = From("user_addresses").Limit(10)
-		cur       = createCursor(2)
+		query     = From("users").Select("name", "count(id) AS total").Group("name")
+		cur       = &testCursor{}
 	)
 
-	adapter.On("Query", query.Where(Nil("deleted_at"))).Return(cur, nil).Once()
-	adapter.On("Aggregate", query.Where(Nil("deleted_at")).Limit(0), "count", "*").Return(2, nil).Once()
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"name", "total"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan("Del Piero", 3).Once()
+	cur.MockScan("Nedved", 2).Once()
+	cur.On("Next").Return(false).Once()
 
-	count, err := repo.FindAndCountAll(context.TODO(), &addresses, query)
-	assert.Nil(t, err)
-	assert.Equal(t, 2, count)
-	assert.Len(t, addresses, 2)
-	assert.Equal(t, 10, addresses[0].ID)
-	assert.Equal(t, 10, addresses[1].ID)
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.Nil(t, repo.ScanAll(context.TODO(), &summaries, query, Strict(true)))
+	assert.Equal(t, []userSummary{
+		{Name: "Del Piero", Total: 3},
+		{Name: "Nedved", Total: 2},
+	}, summaries)
 
 	adapter.AssertExpectations(t)
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_FindAndCountAll_error(t *testing.T) {
+func TestRepository_ScanAll_strict(t *testing.T) {
 	var (
-		users   []User
-		adapter = &testAdapter{}
-		repo    = New(adapter)
-		query   = From("users").Limit(10)
-		err     = errors.New("error")
+		summaries []userSummary
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		query     = From("users")
+		cur       = &testCursor{}
 	)
 
-	adapter.On("Query", query).Return(&testCursor{}, err).Once()
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"name", "age"}, nil).Once()
 
-	count, ferr := repo.FindAndCountAll(context.TODO(), &users, query)
-	assert.Equal(t, err, ferr)
-	assert.Equal(t, 0, count)
+	adapter.On("Query", query).Return(cur, nil).Once()
+
+	assert.True(t, errors.Is(repo.ScanAll(context.TODO(), &summaries, query, Strict(true)), ErrUnknownColumn))
+	assert.Len(t, summaries, 0)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_MustFindAndCountAll(t *testing.T) {
+func TestRepository_MustScanAll(t *testing.T) {
 	var (
-		users   []User
-		adapter = &testAdapter{}
-		repo    = New(adapter)
-		query   = From("users").Limit(10)
-		cur     = createCursor(2)
+		summaries []userSummary
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		query     = From("users")
+		cur       = createCursor(0)
 	)
 
 	adapter.On("Query", query).Return(cur, nil).Once()
-	adapter.On("Aggregate", query.Limit(0), "count", "*").Return(2, nil).Once()
 
 	assert.NotPanics(t, func() {
-		count := repo.MustFindAndCountAll(context.TODO(), &users, query)
-		assert.Equal(t, 2, count)
+		repo.MustScanAll(context.TODO(), &summaries, query)
 	})
-
-	assert.Len(t, users, 2)
-	assert.Equal(t, 10, users[0].ID)
-	assert.Equal(t, 10, users[1].ID)
+	assert.Len(t, summaries, 0)
 
 	adapter.AssertExpectations(t)
 	cur.AssertExpectations(t)
@@ -4117,3 +5023,263 @@ func TestRepository_Transaction_runtimeError(t *testing.T) {
 
 	adapter.AssertExpectations(t)
 }
+
+func TestRepository_Transaction_options(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("BeginWith", TransactionOptions{Isolation: Serializable, ReadOnly: true, Deferrable: true}).Return(nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	repo := New(adapter)
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		return nil
+	}, Serializable, ReadOnly(true), Deferrable(true))
+
+	assert.Nil(t, err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_optionsBeginError(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("BeginWith", TransactionOptions{ReadOnly: true}).Return(errors.New("error")).Once()
+
+	repo := New(adapter)
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		return nil
+	}, ReadOnly(true))
+
+	assert.Equal(t, errors.New("error"), err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_optionsNotSupported(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(struct{ Adapter }{adapter})
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		return nil
+	}, ReadOnly(false)))
+
+	assert.Equal(t, ErrTransactionOptionNotSupported, repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		return nil
+	}, RepeatableRead))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_optionsNested(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	repo := New(adapter)
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		assert.Equal(t, ErrNestedTransactionOption, repo.Transaction(ctx, func(ctx context.Context) error {
+			return nil
+		}, ReadOnly(true)))
+
+		return nil
+	})
+
+	assert.Nil(t, err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_retry(t *testing.T) {
+	var (
+		calls   int
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Times(3)
+	adapter.On("Rollback").Return(nil).Twice()
+	adapter.On("Commit").Return(nil).Once()
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		calls++
+		switch calls {
+		case 1:
+			return ConcurrencyError{Type: SerializationFailure, Err: errors.New("could not serialize access")}
+		case 2:
+			panic(ErrDeadlock)
+		default:
+			return nil
+		}
+	}, Retry(3, time.Millisecond))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, calls)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_retryLimit(t *testing.T) {
+	var (
+		calls   int
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Times(3)
+	adapter.On("Rollback").Return(nil).Times(3)
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		calls++
+		return ErrLockTimeout
+	}, Retry(2, 0))
+
+	assert.Equal(t, ErrLockTimeout, err)
+	assert.Equal(t, 3, calls)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_retryNotRetryable(t *testing.T) {
+	var (
+		calls   int
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		calls++
+		return ErrUniqueConstraint
+	}, Retry(2, time.Millisecond))
+
+	assert.Equal(t, ErrUniqueConstraint, err)
+	assert.Equal(t, 1, calls)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_retryContextDone(t *testing.T) {
+	var (
+		calls       int
+		adapter     = &testAdapter{}
+		repo        = New(adapter)
+		ctx, cancel = context.WithCancel(context.TODO())
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	err := repo.Transaction(ctx, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return ErrDeadlock
+	}, Retry(2, time.Minute))
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, calls)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_retryNested(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	repo := New(adapter)
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		return repo.Transaction(ctx, func(ctx context.Context) error {
+			return nil
+		}, Retry(1, 0))
+	})
+
+	assert.Equal(t, ErrNestedTransactionOption, err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_savepoint(t *testing.T) {
+	adapter := &testSavepointAdapter{}
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Savepoint", "rel_savepoint_1").Return(nil).Once()
+	adapter.On("Savepoint", "rel_savepoint_2").Return(nil).Once()
+	adapter.On("Release", "rel_savepoint_2").Return(nil).Once()
+	adapter.On("Release", "rel_savepoint_1").Return(nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	repo := New(adapter)
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		return repo.Transaction(ctx, func(ctx context.Context) error {
+			return repo.Transaction(ctx, func(ctx context.Context) error {
+				return nil
+			})
+		})
+	})
+
+	assert.Nil(t, err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_savepointRollback(t *testing.T) {
+	adapter := &testSavepointAdapter{}
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Savepoint", "rel_savepoint_1").Return(nil).Once()
+	adapter.On("RollbackTo", "rel_savepoint_1").Return(nil).Once()
+	adapter.On("Savepoint", "rel_savepoint_1").Return(nil).Once()
+	adapter.On("RollbackTo", "rel_savepoint_1").Return(nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	repo := New(adapter)
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		assert.Equal(t, errors.New("error"), repo.Transaction(ctx, func(ctx context.Context) error {
+			return errors.New("error")
+		}))
+
+		assert.Equal(t, errors.New("error"), repo.Transaction(ctx, func(ctx context.Context) error {
+			panic(errors.New("error"))
+		}))
+
+		return nil
+	})
+
+	assert.Nil(t, err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_savepointError(t *testing.T) {
+	adapter := &testSavepointAdapter{}
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Savepoint", "rel_savepoint_1").Return(errors.New("error")).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	repo := New(adapter)
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		return repo.Transaction(ctx, func(ctx context.Context) error {
+			return nil
+		})
+	})
+
+	assert.Equal(t, errors.New("error"), err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_nestedWithoutSavepoint(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("Begin").Return(nil).Twice()
+	adapter.On("Commit").Return(nil).Twice()
+
+	repo := New(adapter)
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		return repo.Transaction(ctx, func(ctx context.Context) error {
+			return nil
+		})
+	})
+
+	assert.Nil(t, err)
+	adapter.AssertExpectations(t)
+}
@@ -0,0 +1,61 @@
+package rel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownColumn returned by strict scan when the result contains column that has no matching field.
+var ErrUnknownColumn = errors.New("rel: unknown column")
+
+// ScanOption is used to configure scanning result into arbitrary struct, such as strict mode.
+type ScanOption interface {
+	apply(*scanConfig)
+}
+
+type scanConfig struct {
+	strict bool
+}
+
+func applyScanOptions(options []ScanOption) scanConfig {
+	var config scanConfig
+	for i := range options {
+		options[i].apply(&config)
+	}
+
+	return config
+}
+
+// Strict enable or disable strict scan.
+// When enabled, scan fails if the result contains column that has no matching field,
+// otherwise unknown columns are ignored.
+type Strict bool
+
+func (s Strict) apply(config *scanConfig) {
+	config.strict = bool(s)
+}
+
+// String representation.
+func (s Strict) String() string {
+	return fmt.Sprintf("rel.Strict(%t)", bool(s))
+}
+
+// checkFields returns error if any of the fields has no matching field in the document.
+func checkFields(meta DocumentMeta, fields []string) error {
+	for _, field := range fields {
+		if _, ok := meta.index[field]; ok {
+			continue
+		}
+
+		if split := strings.SplitN(field, ".", 2); len(split) == 2 {
+			if assoc, ok := meta.association(split[0]); ok && (assoc.Type() == BelongsTo || assoc.Type() == HasOne) {
+				continue
+			}
+		}
+
+		return fmt.Errorf("%w %s in %s", ErrUnknownColumn, field, meta.rt.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package rel
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrict_String(t *testing.T) {
+	assert.Equal(t, "rel.Strict(true)", Strict(true).String())
+}
+
+func TestCheckFields(t *testing.T) {
+	var (
+		meta = NewDocument(&User{}).Meta()
+	)
+
+	assert.Nil(t, checkFields(meta, []string{"id", "name", "address.street"}))
+	assert.True(t, errors.Is(checkFields(meta, []string{"id", "unknown"}), ErrUnknownColumn))
+	assert.True(t, errors.Is(checkFields(meta, []string{"transactions.id"}), ErrUnknownColumn))
+}
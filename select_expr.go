@@ -0,0 +1,166 @@
+package rel
+
+import (
+	"strings"
+)
+
+// SelectExpr defines structured select expression, such as function call or window function.
+// String argument is treated as field name, other arguments are passed as value.
+// Aggregate function without argument is applied to every row, eg: count(*).
+type SelectExpr struct {
+	Func      string
+	Arguments []interface{}
+	Distinct  bool
+	Window    *Window
+	Alias     string
+}
+
+// Build select expression.
+func (se SelectExpr) Build(query *Query) {
+	query.SelectQuery.Expressions = append(query.SelectQuery.Expressions, se)
+}
+
+// Over turns the function into window function using given window.
+func (se SelectExpr) Over(window Window) SelectExpr {
+	se.Window = &window
+	return se
+}
+
+// As sets the alias of the expression, it's used as the name of the result column.
+func (se SelectExpr) As(alias string) SelectExpr {
+	se.Alias = alias
+	return se
+}
+
+// String representation.
+func (se SelectExpr) String() string {
+	var builder strings.Builder
+	if se.Distinct {
+		builder.WriteString("rel.ExprDistinct(\"")
+	} else {
+		builder.WriteString("rel.Expr(\"")
+	}
+	builder.WriteString(se.Func)
+	builder.WriteByte('"')
+
+	if len(se.Arguments) > 0 {
+		builder.WriteString(", ")
+		builder.WriteString(fmtifaces(se.Arguments))
+	}
+
+	builder.WriteByte(')')
+
+	if se.Window != nil {
+		builder.WriteString(".Over(")
+		builder.WriteString(se.Window.String())
+		builder.WriteByte(')')
+	}
+
+	if se.Alias != "" {
+		builder.WriteString(".As(\"")
+		builder.WriteString(se.Alias)
+		builder.WriteString("\")")
+	}
+
+	return builder.String()
+}
+
+// Expr creates select expression that calls a function using arguments.
+func Expr(fn string, arguments ...interface{}) SelectExpr {
+	return SelectExpr{
+		Func:      fn,
+		Arguments: arguments,
+	}
+}
+
+// ExprDistinct creates select expression that calls a function using distinct values of arguments, eg: count(DISTINCT field).
+func ExprDistinct(fn string, arguments ...interface{}) SelectExpr {
+	return SelectExpr{
+		Func:      fn,
+		Arguments: arguments,
+		Distinct:  true,
+	}
+}
+
+// RowNumber returns sequential number of the row within its window partition.
+func RowNumber() SelectExpr {
+	return Expr("row_number")
+}
+
+// Rank returns rank of the row within its window partition, with gaps.
+func Rank() SelectExpr {
+	return Expr("rank")
+}
+
+// DenseRank returns rank of the row within its window partition, without gaps.
+func DenseRank() SelectExpr {
+	return Expr("dense_rank")
+}
+
+// Lag returns value of field from the row that is offset rows before the current row within its window partition.
+func Lag(field string, offset int) SelectExpr {
+	return Expr("lag", field, offset)
+}
+
+// Lead returns value of field from the row that is offset rows after the current row within its window partition.
+func Lead(field string, offset int) SelectExpr {
+	return Expr("lead", field, offset)
+}
+
+// Window defines window specification of window function.
+// Zero value window treats all rows as a single partition.
+// When sort is specified, aggregate function is evaluated from the first row up to the current row and its peers,
+// which can be used to calculate running total.
+type Window struct {
+	Partition []string
+	Sort      []SortQuery
+}
+
+// SortAsc sorts rows in window partition with ascending sort.
+func (w Window) SortAsc(fields ...string) Window {
+	for i := range fields {
+		w.Sort = append(w.Sort, SortAsc(fields[i]))
+	}
+
+	return w
+}
+
+// SortDesc sorts rows in window partition with descending sort.
+func (w Window) SortDesc(fields ...string) Window {
+	for i := range fields {
+		w.Sort = append(w.Sort, SortDesc(fields[i]))
+	}
+
+	return w
+}
+
+// String representation.
+func (w Window) String() string {
+	var builder strings.Builder
+	builder.WriteString("rel.PartitionBy(")
+	if len(w.Partition) > 0 {
+		builder.WriteByte('"')
+		builder.WriteString(strings.Join(w.Partition, "\", \""))
+		builder.WriteByte('"')
+	}
+	builder.WriteByte(')')
+
+	for _, sq := range w.Sort {
+		if sq.Asc() {
+			builder.WriteString(".SortAsc(\"")
+		} else {
+			builder.WriteString(".SortDesc(\"")
+		}
+		builder.WriteString(sq.Field)
+		builder.WriteString("\")")
+	}
+
+	return builder.String()
+}
+
+// PartitionBy creates window that divides rows into partitions by fields.
+func PartitionBy(fields ...string) Window {
+	return Window{
+		Partition: fields,
+	}
+}
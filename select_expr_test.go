@@ -0,0 +1,34 @@
+package rel_test
+
+import (
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectExpr(t *testing.T) {
+	assert.Equal(t, rel.SelectExpr{Func: "row_number"}, rel.RowNumber())
+	assert.Equal(t, rel.SelectExpr{Func: "rank"}, rel.Rank())
+	assert.Equal(t, rel.SelectExpr{Func: "dense_rank"}, rel.DenseRank())
+	assert.Equal(t, rel.SelectExpr{Func: "lag", Arguments: []interface{}{"price", 1}}, rel.Lag("price", 1))
+	assert.Equal(t, rel.SelectExpr{Func: "lead", Arguments: []interface{}{"price", 2}}, rel.Lead("price", 2))
+	assert.Equal(t, rel.SelectExpr{Func: "count", Arguments: []interface{}{"user_id"}, Distinct: true}, rel.ExprDistinct("count", "user_id"))
+
+	assert.Equal(t, rel.SelectExpr{
+		Func:      "sum",
+		Arguments: []interface{}{"amount"},
+		Window: &rel.Window{
+			Partition: []string{"account_id"},
+			Sort:      []rel.SortQuery{rel.SortAsc("created_at"), rel.SortDesc("id")},
+		},
+		Alias: "balance",
+	}, rel.Expr("sum", "amount").Over(rel.PartitionBy("account_id").SortAsc("created_at").SortDesc("id")).As("balance"))
+}
+
+func TestSelectExpr_String(t *testing.T) {
+	assert.Equal(t, "rel.Expr(\"count\", \"id\")", rel.Expr("count", "id").String())
+	assert.Equal(t, "rel.ExprDistinct(\"count\", \"id\").As(\"total\")", rel.ExprDistinct("count", "id").As("total").String())
+	assert.Equal(t, "rel.Expr(\"lag\", \"price\", 1).Over(rel.PartitionBy().SortAsc(\"date\")).As(\"previous\")",
+		rel.Lag("price", 1).Over(rel.Window{}.SortAsc("date")).As("previous").String())
+}
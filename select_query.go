@@ -1,9 +1,14 @@
 package rel
 
 // SelectQuery defines select clause of the query.
+// Expressions and sub queries are selected after fields,
+// all fields are selected when Fields is nil and only expressions or sub queries are specified,
+// while non nil empty Fields selects only the expressions and sub queries.
 type SelectQuery struct {
 	OnlyDistinct bool
 	Fields       []string
+	Expressions  []SelectExpr
+	SubQueries   []SubQuery
 }
 
 // Distinct select query.
@@ -0,0 +1,209 @@
+package shard
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-rel/rel"
+)
+
+// aggregation is a result column of aggregate function that is combined across shards.
+type aggregation struct {
+	column   string
+	function string
+}
+
+// merger combines rows of aggregate or distinct query returned by multiple shards,
+// rows with the same values of group fields are merged into a single row.
+type merger struct {
+	groups       []string
+	aggregations []aggregation
+	distinct     bool
+}
+
+// newMerger returns merger of the query, nil is returned when rows of the query can be concatenated as it is.
+// Only count, sum, max and min aggregate can be combined, error is returned for query that can't be merged correctly.
+func newMerger(query rel.Query) (*merger, error) {
+	var m merger
+
+	if len(query.CombineQuery) > 0 {
+		return nil, errors.New("shard: cross-shard combined query is not supported")
+	}
+
+	if !query.GroupQuery.Filter.None() {
+		return nil, errors.New("shard: cross-shard group filter is not supported")
+	}
+
+	for _, field := range query.SelectQuery.Fields {
+		if rawAggregate(field) {
+			return nil, errors.New("shard: cross-shard raw aggregate (" + field + ") is not supported, use rel.Expr instead")
+		}
+	}
+
+	for _, se := range query.SelectQuery.Expressions {
+		if se.Window != nil {
+			return nil, errors.New("shard: cross-shard window function is not supported")
+		}
+
+		function := strings.ToLower(se.Func)
+		switch function {
+		case "count", "sum":
+			if se.Distinct {
+				return nil, errors.New("shard: cross-shard " + function + " distinct aggregate is not supported")
+			}
+		case "max", "min":
+		case "avg":
+			return nil, errors.New("shard: cross-shard avg aggregate is not supported")
+		default:
+			continue
+		}
+
+		column := se.Alias
+		if column == "" {
+			column = function
+		}
+
+		m.aggregations = append(m.aggregations, aggregation{column: column, function: function})
+	}
+
+	if len(m.aggregations) == 0 && len(query.GroupQuery.Fields) == 0 && !query.SelectQuery.OnlyDistinct {
+		return nil, nil
+	}
+
+	m.groups = query.GroupQuery.Fields
+	// rows of distinct query without aggregate are merged using every selected column.
+	m.distinct = query.SelectQuery.OnlyDistinct && len(m.groups) == 0 && len(m.aggregations) == 0
+	return &m, nil
+}
+
+// rawAggregate reports whether the select field calls aggregate function, eg: count(*) AS total.
+func rawAggregate(field string) bool {
+	field = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(field, "^")))
+	for _, function := range []string{"count", "sum", "avg", "max", "min"} {
+		if strings.HasPrefix(field, function) && strings.HasPrefix(strings.TrimSpace(field[len(function):]), "(") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m merger) merge(c *cursor) error {
+	var (
+		groups       = make([]int, len(m.groups))
+		aggregations = make([]int, len(m.aggregations))
+		index        = make(map[string]int)
+		rows         [][]interface{}
+	)
+
+	if m.distinct {
+		groups = make([]int, len(c.fields))
+		for i := range groups {
+			groups[i] = i
+		}
+	}
+
+	for i, group := range m.groups {
+		if groups[i] = c.column(group); groups[i] < 0 {
+			return errors.New("shard: group field " + group + " must be selected to merge cross-shard query")
+		}
+	}
+
+	for i, agg := range m.aggregations {
+		aggregations[i] = -1
+		for j := range c.fields {
+			if c.fields[j] == agg.column {
+				aggregations[i] = j
+				break
+			}
+		}
+	}
+
+	for _, row := range c.rows {
+		key := make([]interface{}, len(groups))
+		for i := range groups {
+			key[i] = row[groups[i]]
+		}
+
+		k := fmt.Sprintf("%#v", key)
+		j, ok := index[k]
+		if !ok {
+			index[k] = len(rows)
+			rows = append(rows, row)
+			continue
+		}
+
+		for i, agg := range m.aggregations {
+			if aggregations[i] < 0 {
+				continue
+			}
+
+			value, err := combine(agg.function, rows[j][aggregations[i]], row[aggregations[i]])
+			if err != nil {
+				return err
+			}
+
+			rows[j][aggregations[i]] = value
+		}
+	}
+
+	c.rows = rows
+	return nil
+}
+
+// combine aggregate values of two shards, null is ignored.
+func combine(function string, a interface{}, b interface{}) (interface{}, error) {
+	switch {
+	case a == nil:
+		return b, nil
+	case b == nil:
+		return a, nil
+	}
+
+	switch function {
+	case "count", "sum":
+		return add(a, b)
+	case "max":
+		if compare(b, a) > 0 {
+			return b, nil
+		}
+	case "min":
+		if compare(b, a) < 0 {
+			return b, nil
+		}
+	}
+
+	return a, nil
+}
+
+func add(a interface{}, b interface{}) (interface{}, error) {
+	if x, ok := a.(int64); ok {
+		if y, ok := b.(int64); ok {
+			return x + y, nil
+		}
+	}
+
+	x, ok1 := toNumber(a)
+	y, ok2 := toNumber(b)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("shard: cannot sum non numeric value %v and %v", a, b)
+	}
+
+	return x + y, nil
+}
+
+// toNumber converts numeric value to float64, decimal is usually returned by database as string.
+func toNumber(v interface{}) (float64, bool) {
+	if f, ok := toFloat(v); ok {
+		return f, true
+	}
+
+	if s, ok := toString(v); ok {
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
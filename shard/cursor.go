@@ -0,0 +1,321 @@
+package shard
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-rel/rel"
+)
+
+// queryAll performs query on every shards and merges the result, rows of aggregate query are combined.
+func queryAll(ctx context.Context, shards []rel.Adapter, query rel.Query) (rel.Cursor, error) {
+	var (
+		offset = int(query.OffsetQuery)
+		limit  = int(query.LimitQuery)
+		result = &cursor{index: -1}
+	)
+
+	m, err := newMerger(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// every shard needs to return enough rows, offset is applied after merging.
+	// rows of aggregate query can only be limited after they are merged.
+	query.OffsetQuery = 0
+	if m != nil {
+		query.LimitQuery = 0
+	} else if limit > 0 {
+		query.LimitQuery = rel.Limit(offset + limit)
+	}
+
+	for i := range shards {
+		cur, err := shards[i].Query(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		err = result.read(cur)
+		if e := cur.Close(); err == nil {
+			err = e
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if m != nil {
+		if err := m.merge(result); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := result.sort(query.SortQuery); err != nil {
+		return nil, err
+	}
+
+	if offset >= len(result.rows) {
+		result.rows = nil
+	} else {
+		result.rows = result.rows[offset:]
+	}
+
+	if limit > 0 && limit < len(result.rows) {
+		result.rows = result.rows[:limit]
+	}
+
+	return result, nil
+}
+
+// cursor of rows that are read from multiple shards.
+type cursor struct {
+	fields []string
+	rows   [][]interface{}
+	index  int
+}
+
+var _ rel.Cursor = (*cursor)(nil)
+
+func (c *cursor) read(cur rel.Cursor) error {
+	fields, err := cur.Fields()
+	if err != nil {
+		return err
+	}
+
+	if c.fields == nil {
+		c.fields = fields
+	}
+
+	// position of each field in merged rows, as shards may return fields in different order.
+	positions, err := c.positions(fields)
+	if err != nil {
+		return err
+	}
+
+	for cur.Next() {
+		var (
+			row  = make([]interface{}, len(fields))
+			dest = make([]interface{}, len(fields))
+		)
+
+		for i := range dest {
+			dest[i] = &row[positions[i]]
+		}
+
+		if err := cur.Scan(dest...); err != nil {
+			return err
+		}
+
+		c.rows = append(c.rows, row)
+	}
+
+	return nil
+}
+
+func (c *cursor) positions(fields []string) ([]int, error) {
+	if len(c.fields) != len(fields) {
+		return nil, errors.New("shard: shards returned different fields")
+	}
+
+	positions := make([]int, len(fields))
+	for i := range fields {
+		positions[i] = -1
+		for j := range c.fields {
+			if c.fields[j] == fields[i] {
+				positions[i] = j
+				break
+			}
+		}
+
+		if positions[i] < 0 {
+			return nil, errors.New("shard: shards returned different fields")
+		}
+	}
+
+	return positions, nil
+}
+
+func (c *cursor) sort(sorts []rel.SortQuery) error {
+	if len(sorts) == 0 {
+		return nil
+	}
+
+	indexes := make([]int, len(sorts))
+	for i := range sorts {
+		if indexes[i] = c.column(sorts[i].Field); indexes[i] < 0 {
+			return errors.New("shard: sort field " + sorts[i].Field + " must be selected to merge cross-shard query")
+		}
+	}
+
+	sort.SliceStable(c.rows, func(a, b int) bool {
+		for i := range sorts {
+			if cmp := compare(c.rows[a][indexes[i]], c.rows[b][indexes[i]]); cmp != 0 {
+				return (cmp < 0) == sorts[i].Asc()
+			}
+		}
+
+		return false
+	})
+
+	return nil
+}
+
+// column returns position of the field in merged rows, -1 is returned when the field is not selected.
+func (c *cursor) column(field string) int {
+	for i := range c.fields {
+		if matchField(field, c.fields[i]) || matchField(c.fields[i], field) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (c *cursor) Close() error {
+	c.rows = nil
+	return nil
+}
+
+func (c *cursor) Fields() ([]string, error) {
+	return c.fields, nil
+}
+
+func (c *cursor) Next() bool {
+	c.index++
+	return c.index < len(c.rows)
+}
+
+func (c *cursor) Scan(dest ...interface{}) error {
+	if c.index < 0 || c.index >= len(c.rows) {
+		return errors.New("shard: scan called without calling next")
+	}
+
+	row := c.rows[c.index]
+	if len(dest) != len(row) {
+		return errors.New("shard: invalid number of scan destination")
+	}
+
+	for i := range dest {
+		if err := scan(dest[i], row[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *cursor) NopScanner() interface{} {
+	return &sql.RawBytes{}
+}
+
+func scan(dest interface{}, value interface{}) error {
+	if s, ok := dest.(sql.Scanner); ok {
+		return s.Scan(value)
+	}
+
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Ptr {
+		if value == nil {
+			rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+			return nil
+		}
+
+		ptr := reflect.New(rv.Elem().Type().Elem())
+		if err := scan(ptr.Interface(), value); err != nil {
+			return err
+		}
+
+		rv.Elem().Set(ptr)
+		return nil
+	}
+
+	return rel.Nullable(dest).(sql.Scanner).Scan(value)
+}
+
+// compare two values returned by database, nil is ordered first.
+func compare(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch x := a.(type) {
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			switch {
+			case x.Before(y):
+				return -1
+			case x.After(y):
+				return 1
+			}
+		}
+
+		return 0
+	case bool:
+		if y, ok := b.(bool); ok && x != y {
+			if !x {
+				return -1
+			}
+
+			return 1
+		}
+
+		return 0
+	}
+
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+		}
+
+		return 0
+	}
+
+	x, ok1 := toString(a)
+	y, ok2 := toString(b)
+	if ok1 && ok2 {
+		return strings.Compare(x, y)
+	}
+
+	return 0
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+
+	return 0, false
+}
+
+func toString(v interface{}) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case []byte:
+		return string(s), true
+	}
+
+	return "", false
+}
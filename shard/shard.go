@@ -0,0 +1,430 @@
+// Package shard provides rel.Adapter that routes operations to several databases using a shard resolver.
+//
+// Shard of an operation is resolved from context (see WithShard) or using the configured Resolver.
+// Operations that cannot be resolved to a single shard are fanned out to all resolved shards,
+// query results are merged respecting sort, offset and limit of the query,
+// and rows of count, sum, max and min aggregate are combined by group.
+// Query that can't be merged correctly, such as combined query or window function, is rejected.
+// Update and delete must be resolved to a single shard, unless it's explicitly broadcasted using WithAllShards.
+// Transaction must be resolved to a single shard using context, cross-shard transaction is rejected.
+package shard
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-rel/rel"
+)
+
+type contextKey int8
+
+const (
+	shardKey contextKey = iota
+	allShardsKey
+)
+
+var (
+	// ErrCrossShardTransaction returned when transaction is started without selecting a shard using WithShard.
+	ErrCrossShardTransaction = errors.New("shard: cross-shard transaction is not supported, use shard.WithShard to select a shard")
+
+	// ErrShardNotResolved returned when operation that requires a single shard cannot be resolved.
+	ErrShardNotResolved = errors.New("shard: unable to resolve shard")
+)
+
+// Resolver returns index of shards that the operation targets.
+// mutates is only specified for insert operation.
+// Returning no shard means the operation targets all shards.
+type Resolver func(ctx context.Context, query rel.Query, mutates map[string]rel.Mutate) ([]int, error)
+
+// FieldResolver resolves shard using the value of field in insert mutates,
+// or in equal and inclusion filter that must be matched by every result of the query.
+// fn is used to map the value to the shard index.
+func FieldResolver(field string, fn func(value interface{}) int) Resolver {
+	return func(ctx context.Context, query rel.Query, mutates map[string]rel.Mutate) ([]int, error) {
+		var values []interface{}
+
+		if mutates != nil {
+			if mut, ok := mutates[field]; ok && mut.Type == rel.ChangeSetOp {
+				values = append(values, mut.Value)
+			}
+		} else {
+			values = filterValues(query.WhereQuery, field)
+		}
+
+		var (
+			shards []int
+			seen   = make(map[int]struct{}, len(values))
+		)
+
+		for i := range values {
+			index := fn(values[i])
+			if _, ok := seen[index]; !ok {
+				seen[index] = struct{}{}
+				shards = append(shards, index)
+			}
+		}
+
+		return shards, nil
+	}
+}
+
+// filterValues returns values of field from equal and inclusion filter that are combined using and operator.
+func filterValues(filter rel.FilterQuery, field string) []interface{} {
+	switch filter.Type {
+	case rel.FilterAndOp:
+		for i := range filter.Inner {
+			if values := filterValues(filter.Inner[i], field); len(values) > 0 {
+				return values
+			}
+		}
+	case rel.FilterEqOp:
+		if matchField(filter.Field, field) {
+			return []interface{}{filter.Value}
+		}
+	case rel.FilterInOp:
+		if values, ok := filter.Value.([]interface{}); ok && matchField(filter.Field, field) {
+			return values
+		}
+	}
+
+	return nil
+}
+
+func matchField(name string, field string) bool {
+	return name == field || strings.HasSuffix(name, "."+field)
+}
+
+// WithShard returns context that routes every operation to the shard.
+// It's required to start a transaction.
+func WithShard(ctx context.Context, index int) context.Context {
+	return context.WithValue(ctx, shardKey, index)
+}
+
+// WithAllShards returns context that allows update and delete to be broadcasted to every resolved shard,
+// otherwise write that can't be resolved to a single shard returns ErrShardNotResolved.
+func WithAllShards(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allShardsKey, true)
+}
+
+// Adapter that routes operations to shards.
+type Adapter struct {
+	shards   []rel.Adapter
+	resolver Resolver
+}
+
+var (
+	_ rel.Adapter             = (*Adapter)(nil)
+	_ rel.TransactionBeginner = (*Adapter)(nil)
+)
+
+// Close all shards connection.
+func (a *Adapter) Close() error {
+	var err error
+	for i := range a.shards {
+		if e := a.shards[i].Close(); err == nil {
+			err = e
+		}
+	}
+
+	return err
+}
+
+// Instrumentation set instrumenter for all shards.
+func (a *Adapter) Instrumentation(instrumenter rel.Instrumenter) {
+	for i := range a.shards {
+		a.shards[i].Instrumentation(instrumenter)
+	}
+}
+
+// Ping all shards.
+func (a *Adapter) Ping(ctx context.Context) error {
+	for i := range a.shards {
+		if err := a.shards[i].Ping(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Aggregate records in resolved shards.
+// Aggregate of multiple shards is combined for count, sum, max and min mode.
+func (a *Adapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string) (int, error) {
+	shards, err := a.resolve(ctx, query, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(shards) == 1 {
+		return shards[0].Aggregate(ctx, query, mode, field)
+	}
+
+	var result int
+	for i := range shards {
+		value, err := shards[i].Aggregate(ctx, query, mode, field)
+		if err != nil {
+			return 0, err
+		}
+
+		switch mode {
+		case "count", "sum":
+			result += value
+		case "max":
+			if i == 0 || value > result {
+				result = value
+			}
+		case "min":
+			if i == 0 || value < result {
+				result = value
+			}
+		default:
+			return 0, errors.New("shard: cross-shard " + mode + " aggregate is not supported")
+		}
+	}
+
+	return result, nil
+}
+
+// Query records in resolved shards.
+func (a *Adapter) Query(ctx context.Context, query rel.Query) (rel.Cursor, error) {
+	shards, err := a.resolve(ctx, query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(shards) == 1 {
+		return shards[0].Query(ctx, query)
+	}
+
+	return queryAll(ctx, shards, query)
+}
+
+// Insert a record to the resolved shard.
+func (a *Adapter) Insert(ctx context.Context, query rel.Query, primaryField string, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (interface{}, error) {
+	shard, err := a.resolveOne(ctx, query, mutates)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.Insert(ctx, query, primaryField, mutates, onConflict)
+}
+
+// InsertAll records, each record is inserted to its resolved shard.
+func (a *Adapter) InsertAll(ctx context.Context, query rel.Query, primaryField string, fields []string, bulkMutates []map[string]rel.Mutate, onConflict rel.OnConflict) ([]interface{}, error) {
+	var (
+		order   []rel.Adapter
+		indexes = make(map[rel.Adapter][]int)
+		ids     = make([]interface{}, len(bulkMutates))
+	)
+
+	for i := range bulkMutates {
+		shard, err := a.resolveOne(ctx, query, bulkMutates[i])
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := indexes[shard]; !ok {
+			order = append(order, shard)
+		}
+
+		indexes[shard] = append(indexes[shard], i)
+	}
+
+	for _, shard := range order {
+		bulk := make([]map[string]rel.Mutate, len(indexes[shard]))
+		for i, index := range indexes[shard] {
+			bulk[i] = bulkMutates[index]
+		}
+
+		result, err := shard.InsertAll(ctx, query, primaryField, fields, bulk, onConflict)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, index := range indexes[shard] {
+			ids[index] = result[i]
+		}
+	}
+
+	return ids, nil
+}
+
+// Update records in the resolved shard, or in every resolved shard when it's broadcasted using WithAllShards.
+func (a *Adapter) Update(ctx context.Context, query rel.Query, primaryField string, mutates map[string]rel.Mutate) (int, error) {
+	shards, err := a.resolveWrite(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	for i := range shards {
+		n, err := shards[i].Update(ctx, query, primaryField, mutates)
+		if err != nil {
+			return count, err
+		}
+
+		count += n
+	}
+
+	return count, nil
+}
+
+// Delete records in the resolved shard, or in every resolved shard when it's broadcasted using WithAllShards.
+func (a *Adapter) Delete(ctx context.Context, query rel.Query) (int, error) {
+	shards, err := a.resolveWrite(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	for i := range shards {
+		n, err := shards[i].Delete(ctx, query)
+		if err != nil {
+			return count, err
+		}
+
+		count += n
+	}
+
+	return count, nil
+}
+
+// Exec raw statement on the shard selected using context.
+func (a *Adapter) Exec(ctx context.Context, stmt string, args []interface{}) (int64, int64, error) {
+	shard, ok := a.selected(ctx)
+	if !ok {
+		return 0, 0, ErrShardNotResolved
+	}
+
+	return shard.Exec(ctx, stmt, args)
+}
+
+// Begin a transaction on the shard selected using context.
+func (a *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	shard, ok := a.selected(ctx)
+	if !ok {
+		return nil, ErrCrossShardTransaction
+	}
+
+	return shard.Begin(ctx)
+}
+
+// BeginWith begins a transaction with options on the shard selected using context.
+func (a *Adapter) BeginWith(ctx context.Context, options rel.TransactionOptions) (rel.Adapter, error) {
+	shard, ok := a.selected(ctx)
+	if !ok {
+		return nil, ErrCrossShardTransaction
+	}
+
+	if tb, ok := shard.(rel.TransactionBeginner); ok {
+		return tb.BeginWith(ctx, options)
+	}
+
+	return nil, rel.ErrTransactionOptionNotSupported
+}
+
+// Commit is not supported outside transaction.
+func (a *Adapter) Commit(ctx context.Context) error {
+	return errors.New("shard: unable to commit outside transaction")
+}
+
+// Rollback is not supported outside transaction.
+func (a *Adapter) Rollback(ctx context.Context) error {
+	return errors.New("shard: unable to rollback outside transaction")
+}
+
+// Apply migration to the shard selected using context, or to all shards.
+func (a *Adapter) Apply(ctx context.Context, migration rel.Migration) error {
+	if shard, ok := a.selected(ctx); ok {
+		return shard.Apply(ctx, migration)
+	}
+
+	for i := range a.shards {
+		if err := a.shards[i].Apply(ctx, migration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Shards adapter.
+func (a *Adapter) Shards() []rel.Adapter {
+	return a.shards
+}
+
+// selected returns shard selected using context, or the only shard.
+func (a *Adapter) selected(ctx context.Context) (rel.Adapter, bool) {
+	if index, ok := ctx.Value(shardKey).(int); ok && index >= 0 && index < len(a.shards) {
+		return a.shards[index], true
+	}
+
+	if len(a.shards) == 1 {
+		return a.shards[0], true
+	}
+
+	return nil, false
+}
+
+func (a *Adapter) resolve(ctx context.Context, query rel.Query, mutates map[string]rel.Mutate) ([]rel.Adapter, error) {
+	if shard, ok := a.selected(ctx); ok {
+		return []rel.Adapter{shard}, nil
+	}
+
+	if a.resolver == nil {
+		return a.shards, nil
+	}
+
+	indexes, err := a.resolver(ctx, query, mutates)
+	if err != nil || len(indexes) == 0 {
+		return a.shards, err
+	}
+
+	shards := make([]rel.Adapter, len(indexes))
+	for i, index := range indexes {
+		if index < 0 || index >= len(a.shards) {
+			return nil, ErrShardNotResolved
+		}
+
+		shards[i] = a.shards[index]
+	}
+
+	return shards, nil
+}
+
+func (a *Adapter) resolveOne(ctx context.Context, query rel.Query, mutates map[string]rel.Mutate) (rel.Adapter, error) {
+	shards, err := a.resolve(ctx, query, mutates)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(shards) != 1 {
+		return nil, ErrShardNotResolved
+	}
+
+	return shards[0], nil
+}
+
+// resolveWrite resolves shards of update and delete, which must be a single shard unless broadcast is allowed.
+func (a *Adapter) resolveWrite(ctx context.Context, query rel.Query) ([]rel.Adapter, error) {
+	if broadcast, _ := ctx.Value(allShardsKey).(bool); broadcast {
+		return a.resolve(ctx, query, nil)
+	}
+
+	shard, err := a.resolveOne(ctx, query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return []rel.Adapter{shard}, nil
+}
+
+// New sharding adapter.
+// When resolver is nil, shard must be selected using context for operation that requires a single shard.
+func New(shards []rel.Adapter, resolver Resolver) *Adapter {
+	return &Adapter{
+		shards:   shards,
+		resolver: resolver,
+	}
+}
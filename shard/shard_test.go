@@ -0,0 +1,237 @@
+package shard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/adaptertest"
+	"github.com/go-rel/rel/memadapter"
+	"github.com/go-rel/rel/sort"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+type User struct {
+	ID       int
+	TenantID int
+	Name     string
+	Age      int
+}
+
+func tenantResolver() Resolver {
+	return FieldResolver("tenant_id", func(value interface{}) int {
+		return value.(int) % 2
+	})
+}
+
+func setup(t *testing.T) (*Adapter, rel.Repository) {
+	var (
+		adapter = New([]rel.Adapter{memadapter.New(), memadapter.New()}, tenantResolver())
+		repo    = rel.New(adapter)
+		users   = []User{
+			{TenantID: 1, Name: "John", Age: 20},
+			{TenantID: 2, Name: "Jane", Age: 30},
+			{TenantID: 3, Name: "Doe", Age: 40},
+			{TenantID: 4, Name: "Mary", Age: 50},
+		}
+	)
+
+	assert.Nil(t, repo.InsertAll(context.TODO(), &users))
+	assert.Equal(t, []int{1, 1, 2, 2}, []int{users[0].ID, users[1].ID, users[2].ID, users[3].ID})
+
+	return adapter, repo
+}
+
+func TestAdapter(t *testing.T) {
+	adaptertest.Run(t, New([]rel.Adapter{memadapter.New()}, tenantResolver()))
+}
+
+func TestAdapter_resolve(t *testing.T) {
+	var (
+		ctx           = context.TODO()
+		adapter, repo = setup(t)
+		user          User
+	)
+
+	assert.Equal(t, 2, rel.New(adapter.Shards()[0]).MustCount(ctx, "users"))
+	assert.Equal(t, 2, rel.New(adapter.Shards()[1]).MustCount(ctx, "users"))
+
+	assert.Nil(t, repo.Find(ctx, &user, where.Eq("tenant_id", 3).AndEq("id", 2)))
+	assert.Equal(t, "Doe", user.Name)
+
+	assert.Nil(t, repo.Find(ctx, &user, where.Eq("id", 1), rel.Where(where.Eq("tenant_id", 2))))
+	assert.Equal(t, "Jane", user.Name)
+
+	assert.Equal(t, 1, repo.MustCount(ctx, "users", where.Eq("tenant_id", 4)))
+
+	assert.Nil(t, repo.Find(WithShard(ctx, 1), &user, where.Eq("id", 1)))
+	assert.Equal(t, "John", user.Name)
+}
+
+func TestAdapter_fanOut(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		_, repo = setup(t)
+		users   []User
+	)
+
+	assert.Nil(t, repo.FindAll(ctx, &users, sort.Desc("age")))
+	assert.Len(t, users, 4)
+	assert.Equal(t, "Mary", users[0].Name)
+	assert.Equal(t, "John", users[3].Name)
+
+	assert.Nil(t, repo.FindAll(ctx, &users, sort.Asc("name"), rel.Offset(1), rel.Limit(2)))
+	assert.Equal(t, []string{"Jane", "John"}, []string{users[0].Name, users[1].Name})
+
+	assert.Nil(t, repo.FindAll(ctx, &users, where.In("tenant_id", 1, 2), sort.Asc("age"), rel.Limit(10)))
+	assert.Equal(t, []string{"John", "Jane"}, []string{users[0].Name, users[1].Name})
+
+	assert.Nil(t, repo.FindAll(ctx, &users, rel.Offset(10)))
+	assert.Len(t, users, 0)
+
+	assert.Equal(t, 4, repo.MustCount(ctx, "users"))
+	assert.Equal(t, 140, repo.MustAggregate(ctx, rel.From("users"), "sum", "age"))
+	assert.Equal(t, 50, repo.MustAggregate(ctx, rel.From("users"), "max", "age"))
+	assert.Equal(t, 20, repo.MustAggregate(ctx, rel.From("users"), "min", "age"))
+
+	_, err := repo.Aggregate(ctx, rel.From("users"), "avg", "age")
+	assert.EqualError(t, err, "shard: cross-shard avg aggregate is not supported")
+
+	assert.EqualError(t, repo.FindAll(ctx, &users, rel.Select("name"), sort.Asc("age")),
+		"shard: sort field age must be selected to merge cross-shard query")
+
+	_, err = repo.UpdateAny(ctx, rel.From("users"), rel.Inc("age"))
+	assert.Equal(t, ErrShardNotResolved, err)
+
+	assert.Equal(t, 4, repo.MustUpdateAny(WithAllShards(ctx), rel.From("users"), rel.Inc("age")))
+	assert.Equal(t, 2, repo.MustDeleteAny(WithAllShards(ctx), rel.From("users").Where(where.Gt("age", 35))))
+	assert.Equal(t, 2, repo.MustCount(ctx, "users"))
+}
+
+func TestAdapter_aggregates(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		_, repo = setup(t)
+	)
+
+	repo.MustInsert(ctx, &User{TenantID: 6, Name: "John", Age: 60})
+
+	result, err := repo.Aggregates(ctx, rel.From("users"), rel.Count("*"), rel.Sum("age"), rel.Max("age"), rel.Min("name"))
+	assert.Nil(t, err)
+	assert.Equal(t, 5, result.Int("count"))
+	assert.Equal(t, 200, result.Int("sum_age"))
+	assert.Equal(t, 60, result.Int("max_age"))
+	assert.Equal(t, "Doe", result["min_name"])
+
+	results, err := repo.AggregateGroups(ctx, rel.From("users").Group("name").SortDesc("total").Limit(1), rel.Count("*").As("total"), rel.Sum("age"))
+	assert.Nil(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "John", results[0]["name"])
+	assert.Equal(t, 2, results[0].Int("total"))
+	assert.Equal(t, 80, results[0].Int("sum_age"))
+
+	_, err = repo.Aggregates(ctx, rel.From("users"), rel.Avg("age"))
+	assert.EqualError(t, err, "shard: cross-shard avg aggregate is not supported")
+
+	_, err = repo.Aggregates(ctx, rel.From("users"), rel.CountDistinct("name"))
+	assert.EqualError(t, err, "shard: cross-shard count distinct aggregate is not supported")
+}
+
+func TestAdapter_fanOutMerge(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		_, repo = setup(t)
+		names   []string
+		users   []User
+	)
+
+	repo.MustInsert(ctx, &User{TenantID: 6, Name: "John", Age: 60})
+
+	assert.Nil(t, repo.Pluck(ctx, "name", &names, rel.From("users").Distinct().SortAsc("name")))
+	assert.Equal(t, []string{"Doe", "Jane", "John", "Mary"}, names)
+
+	tests := []struct {
+		query rel.Query
+		err   string
+	}{
+		{rel.Select("name", "count(*) AS total").Group("name"), "shard: cross-shard raw aggregate (count(*) AS total) is not supported, use rel.Expr instead"},
+		{rel.Select("name").Group("name").Having(where.Gt("age", 20)), "shard: cross-shard group filter is not supported"},
+		{rel.Select().SelectExpr(rel.RowNumber().Over(rel.PartitionBy("name")).As("rank")), "shard: cross-shard window function is not supported"},
+		{rel.Select("name").UnionAll(rel.Select("name").From("users")), "shard: cross-shard combined query is not supported"},
+		{rel.Select("age").Group("name"), "shard: group field name must be selected to merge cross-shard query"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.err, func(t *testing.T) {
+			assert.EqualError(t, repo.FindAll(ctx, &users, test.query), test.err)
+		})
+	}
+}
+
+func TestAdapter_writePrimary(t *testing.T) {
+	var (
+		ctx           = context.TODO()
+		adapter, repo = setup(t)
+		user          = User{ID: 1, TenantID: 2, Name: "Jane", Age: 31}
+		other         User
+	)
+
+	// record is updated and deleted using primary key only, which exists in both shards.
+	assert.Equal(t, ErrShardNotResolved, repo.Update(ctx, &user))
+	assert.Equal(t, ErrShardNotResolved, repo.Delete(ctx, &user))
+
+	assert.Nil(t, rel.New(adapter.Shards()[1]).Find(ctx, &other, where.Eq("id", 1)))
+	assert.Equal(t, User{ID: 1, TenantID: 1, Name: "John", Age: 20}, other)
+	assert.Equal(t, 4, repo.MustCount(ctx, "users"))
+
+	assert.Nil(t, repo.Update(WithShard(ctx, 0), &user))
+	assert.Nil(t, repo.Delete(WithShard(ctx, 0), &user))
+
+	assert.Nil(t, rel.New(adapter.Shards()[1]).Find(ctx, &other, where.Eq("id", 1)))
+	assert.Equal(t, "John", other.Name)
+	assert.Equal(t, 3, repo.MustCount(ctx, "users"))
+}
+
+func TestAdapter_transaction(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		_, repo = setup(t)
+	)
+
+	assert.Equal(t, ErrCrossShardTransaction, repo.Transaction(ctx, func(ctx context.Context) error {
+		return nil
+	}))
+
+	assert.Equal(t, ErrCrossShardTransaction, repo.Transaction(ctx, func(ctx context.Context) error {
+		return nil
+	}, rel.ReadOnly(true)))
+
+	assert.Nil(t, repo.Transaction(WithShard(ctx, 0), func(ctx context.Context) error {
+		repo.MustInsert(ctx, &User{TenantID: 6, Name: "Kate"})
+		assert.Equal(t, 3, repo.MustCount(ctx, "users"))
+		return nil
+	}))
+
+	assert.Equal(t, 5, repo.MustCount(ctx, "users"))
+}
+
+func TestAdapter_notResolved(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		_, repo = setup(t)
+	)
+
+	assert.Equal(t, ErrShardNotResolved, repo.Insert(ctx, &struct {
+		ID   int
+		Name string
+	}{Name: "no tenant"}))
+
+	_, _, err := New([]rel.Adapter{memadapter.New(), memadapter.New()}, nil).Exec(ctx, "SELECT 1", nil)
+	assert.Equal(t, ErrShardNotResolved, err)
+
+	users := []User{{TenantID: 1}}
+	assert.Equal(t, ErrShardNotResolved, rel.New(New([]rel.Adapter{memadapter.New(), memadapter.New()}, func(ctx context.Context, query rel.Query, mutates map[string]rel.Mutate) ([]int, error) {
+		return []int{5}, nil
+	})).InsertAll(ctx, &users))
+}
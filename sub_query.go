@@ -1,9 +1,31 @@
 package rel
 
 // SubQuery warps a query into: Prefix (Query)
+//
+// Sub query with alias can be used as derived table using FromSub, or as scalar sub query using SelectSub.
 type SubQuery struct {
 	Prefix string
 	Query  Query
+	Alias  string
+}
+
+// String representation.
+func (sq SubQuery) String() string {
+	var str string
+	switch sq.Prefix {
+	case "ALL":
+		str = "rel.All(" + sq.Query.String() + ")"
+	case "ANY":
+		str = "rel.Any(" + sq.Query.String() + ")"
+	default:
+		str = sq.Query.String()
+	}
+
+	if sq.Alias != "" {
+		str += ".As(\"" + sq.Alias + "\")"
+	}
+
+	return str
 }
 
 // All warp a query into ALL(sub-query)
@@ -25,3 +47,11 @@ func Any(sub Query) SubQuery {
 		Query:  sub,
 	}
 }
+
+// Ref references a field of the outer query, it's used as filter value inside correlated sub query.
+type Ref string
+
+// String representation.
+func (r Ref) String() string {
+	return "rel.Ref(\"" + string(r) + "\")"
+}
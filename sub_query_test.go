@@ -19,3 +19,13 @@ func TestAny(t *testing.T) {
 		Query:  Query{},
 	}, Any(Query{}))
 }
+
+func TestSubQuery_String(t *testing.T) {
+	assert.Equal(t, "rel.All(rel.From(\"users\"))", All(From("users")).String())
+	assert.Equal(t, "rel.Any(rel.From(\"users\"))", Any(From("users")).String())
+	assert.Equal(t, "rel.From(\"users\").As(\"u\")", From("users").As("u").String())
+}
+
+func TestRef_String(t *testing.T) {
+	assert.Equal(t, "rel.Ref(\"users.id\")", Ref("users.id").String())
+}
@@ -0,0 +1,227 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrTransactionOptionNotSupported returned when transaction options is requested,
+	// but the adapter doesn't implements TransactionBeginner.
+	ErrTransactionOptionNotSupported = errors.New("rel: transaction options is not supported by adapter")
+
+	// ErrNestedTransactionOption returned when transaction options is used in nested transaction.
+	ErrNestedTransactionOption = errors.New("rel: transaction options cannot be used in nested transaction")
+)
+
+// TransactionOptions holds options used to begin a transaction.
+type TransactionOptions struct {
+	Isolation  IsolationLevel
+	ReadOnly   bool
+	Deferrable bool
+}
+
+// None returns true when no option is specified.
+func (to TransactionOptions) None() bool {
+	return to == TransactionOptions{}
+}
+
+// TransactionOption is used to configure transaction, such as isolation level, read only mode and retry.
+type TransactionOption interface {
+	apply(*transactionConfig)
+}
+
+type transactionConfig struct {
+	options TransactionOptions
+	retry   int
+	backoff time.Duration
+}
+
+func applyTransactionOptions(options []TransactionOption) transactionConfig {
+	var config transactionConfig
+	for i := range options {
+		options[i].apply(&config)
+	}
+
+	return config
+}
+
+// none returns true when no option is specified.
+func (tc transactionConfig) none() bool {
+	return tc.options.None() && tc.retry == 0
+}
+
+// shouldRetry returns true when error is caused by concurrent access and retry limit is not reached yet.
+func (tc transactionConfig) shouldRetry(err error, attempt int) bool {
+	return attempt <= tc.retry &&
+		(errors.Is(err, ErrSerializationFailure) || errors.Is(err, ErrDeadlock) || errors.Is(err, ErrLockTimeout))
+}
+
+// wait before next attempt using exponential backoff, returns error when context is done.
+func (tc transactionConfig) wait(ctx context.Context, attempt int) error {
+	if tc.backoff <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(tc.delay(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// maxBackoff limits the wait between attempts, unless the initial backoff is already longer.
+const maxBackoff = time.Minute
+
+// delay returns backoff of the attempt, it's doubled on every attempt until it reaches maxBackoff.
+func (tc transactionConfig) delay(attempt int) time.Duration {
+	delay := tc.backoff
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxBackoff && tc.backoff <= maxBackoff {
+		delay = maxBackoff
+	}
+
+	return delay
+}
+
+// IsolationLevel of transaction.
+// Empty isolation level means default isolation level of the database will be used.
+type IsolationLevel string
+
+const (
+	// ReadUncommitted isolation level.
+	ReadUncommitted IsolationLevel = "READ UNCOMMITTED"
+	// ReadCommitted isolation level.
+	ReadCommitted IsolationLevel = "READ COMMITTED"
+	// RepeatableRead isolation level.
+	RepeatableRead IsolationLevel = "REPEATABLE READ"
+	// Serializable isolation level.
+	Serializable IsolationLevel = "SERIALIZABLE"
+)
+
+func (il IsolationLevel) apply(config *transactionConfig) {
+	config.options.Isolation = il
+}
+
+// ReadOnly starts transaction in read only mode.
+type ReadOnly bool
+
+func (ro ReadOnly) apply(config *transactionConfig) {
+	config.options.ReadOnly = bool(ro)
+}
+
+// Deferrable starts a deferrable transaction.
+// It's only meaningful for serializable and read only transaction.
+type Deferrable bool
+
+func (d Deferrable) apply(config *transactionConfig) {
+	config.options.Deferrable = bool(d)
+}
+
+type retry struct {
+	limit   int
+	backoff time.Duration
+}
+
+func (r retry) apply(config *transactionConfig) {
+	config.retry = r.limit
+	config.backoff = r.backoff
+}
+
+// String representation.
+func (r retry) String() string {
+	return fmt.Sprintf("rel.Retry(%d, %s)", r.limit, r.backoff)
+}
+
+// Retry transaction when it fails with serialization failure, deadlock or lock timeout error.
+// The whole transaction function will be called again at most limit times,
+// and the wait between attempts starts from backoff and doubled on every retry, up to one minute.
+func Retry(limit int, backoff time.Duration) TransactionOption {
+	return retry{limit: limit, backoff: backoff}
+}
+
+// AfterCommit registers fn to be called after the outermost transaction in context is committed.
+// Context passed to fn is the context used to start the transaction.
+// fn is called immediately when context is not inside a transaction.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if tc, ok := ctx.Value(callbackKey).(*transactionCallbacks); ok {
+		tc.add(fn, nil)
+		return
+	}
+
+	fn(ctx)
+}
+
+// AfterRollback registers fn to be called after the outermost transaction in context is finished,
+// when the changes it's registered from are rolled back.
+// Context passed to fn is the context used to start the transaction.
+// fn is called immediately when context is not inside a transaction.
+func AfterRollback(ctx context.Context, fn func(ctx context.Context)) {
+	if tc, ok := ctx.Value(callbackKey).(*transactionCallbacks); ok {
+		tc.add(nil, fn)
+		return
+	}
+
+	fn(ctx)
+}
+
+// transactionCallbacks queues callbacks registered inside a transaction.
+type transactionCallbacks struct {
+	mu       sync.Mutex
+	commit   []func(ctx context.Context)
+	rollback []func(ctx context.Context)
+}
+
+func (tc *transactionCallbacks) add(commit func(ctx context.Context), rollback func(ctx context.Context)) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if commit != nil {
+		tc.commit = append(tc.commit, commit)
+	}
+
+	if rollback != nil {
+		tc.rollback = append(tc.rollback, rollback)
+	}
+}
+
+// finish calls queued callbacks when it's the outermost transaction,
+// otherwise the callbacks are moved to parent transaction.
+// Rollback callbacks of rolled back nested transaction will always be called when the outermost transaction finished.
+func (tc *transactionCallbacks) finish(ctx context.Context, parent *transactionCallbacks, committed bool) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if parent != nil {
+		parent.mu.Lock()
+		defer parent.mu.Unlock()
+
+		if committed {
+			parent.commit = append(parent.commit, tc.commit...)
+		} else {
+			parent.commit = append(parent.commit, tc.rollback...)
+		}
+
+		parent.rollback = append(parent.rollback, tc.rollback...)
+		return
+	}
+
+	callbacks := tc.rollback
+	if committed {
+		callbacks = tc.commit
+	}
+
+	for i := range callbacks {
+		callbacks[i](ctx)
+	}
+}
@@ -0,0 +1,184 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAfterCommit(t *testing.T) {
+	var (
+		calls   []string
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		AfterCommit(ctx, func(ctx context.Context) {
+			assert.False(t, fetchContext(ctx, adapter).inTransaction())
+			calls = append(calls, "commit")
+		})
+
+		AfterRollback(ctx, func(ctx context.Context) {
+			calls = append(calls, "rollback")
+		})
+
+		assert.Nil(t, calls)
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"commit"}, calls)
+	adapter.AssertExpectations(t)
+}
+
+func TestAfterRollback(t *testing.T) {
+	var (
+		calls   []string
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		AfterCommit(ctx, func(ctx context.Context) {
+			calls = append(calls, "commit")
+		})
+
+		AfterRollback(ctx, func(ctx context.Context) {
+			calls = append(calls, "rollback")
+		})
+
+		return errors.New("error")
+	})
+
+	assert.Equal(t, errors.New("error"), err)
+	assert.Equal(t, []string{"rollback"}, calls)
+	adapter.AssertExpectations(t)
+}
+
+func TestAfterRollback_commitError(t *testing.T) {
+	var (
+		calls   []string
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Commit").Return(errors.New("error")).Once()
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		AfterCommit(ctx, func(ctx context.Context) {
+			calls = append(calls, "commit")
+		})
+
+		AfterRollback(ctx, func(ctx context.Context) {
+			calls = append(calls, "rollback")
+		})
+
+		return nil
+	})
+
+	assert.Equal(t, errors.New("error"), err)
+	assert.Equal(t, []string{"rollback"}, calls)
+	adapter.AssertExpectations(t)
+}
+
+func TestAfterCommit_nested(t *testing.T) {
+	var (
+		calls   []string
+		adapter = &testSavepointAdapter{}
+		repo    = New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Savepoint", "rel_savepoint_1").Return(nil).Twice()
+	adapter.On("Release", "rel_savepoint_1").Return(nil).Once()
+	adapter.On("RollbackTo", "rel_savepoint_1").Return(nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		AfterCommit(ctx, func(ctx context.Context) {
+			calls = append(calls, "outer commit")
+		})
+
+		assert.Nil(t, repo.Transaction(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, func(ctx context.Context) {
+				calls = append(calls, "released commit")
+			})
+
+			AfterRollback(ctx, func(ctx context.Context) {
+				calls = append(calls, "released rollback")
+			})
+
+			return nil
+		}))
+
+		assert.NotNil(t, repo.Transaction(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, func(ctx context.Context) {
+				calls = append(calls, "rolled back commit")
+			})
+
+			AfterRollback(ctx, func(ctx context.Context) {
+				calls = append(calls, "rolled back rollback")
+			})
+
+			return errors.New("error")
+		}))
+
+		assert.Nil(t, calls)
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"outer commit", "released commit", "rolled back rollback"}, calls)
+	adapter.AssertExpectations(t)
+}
+
+func TestAfterCommit_outsideTransaction(t *testing.T) {
+	var calls []string
+
+	AfterCommit(context.TODO(), func(ctx context.Context) {
+		calls = append(calls, "commit")
+	})
+
+	AfterRollback(context.TODO(), func(ctx context.Context) {
+		calls = append(calls, "rollback")
+	})
+
+	assert.Equal(t, []string{"commit", "rollback"}, calls)
+}
+
+func TestTransactionConfig_delay(t *testing.T) {
+	tc := transactionConfig{backoff: time.Second}
+
+	assert.Equal(t, time.Second, tc.delay(1))
+	assert.Equal(t, 4*time.Second, tc.delay(3))
+	assert.Equal(t, maxBackoff, tc.delay(7))
+	assert.Equal(t, maxBackoff, tc.delay(100))
+	assert.Equal(t, maxBackoff, tc.delay(math.MaxInt32))
+
+	tc.backoff = 2 * time.Minute
+	assert.Equal(t, 2*time.Minute, tc.delay(100))
+}
+
+func TestTransactionConfig_waitLargeAttempt(t *testing.T) {
+	var (
+		tc          = transactionConfig{backoff: time.Millisecond}
+		ctx, cancel = context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	)
+
+	defer cancel()
+
+	// overflowed backoff would be negative and returns immediately.
+	assert.Equal(t, context.DeadlineExceeded, tc.wait(ctx, 100))
+}
@@ -70,6 +70,12 @@ var (
 	// NotLike compares value of field to not match string pattern.
 	NotLike = rel.NotLike
 
+	// Exists check whether sub query returns any row.
+	Exists = rel.Exists
+
+	// NotExists check whether sub query returns no row.
+	NotExists = rel.NotExists
+
 	// Fragment add custom filter.
 	Fragment = rel.FilterFragment
 )
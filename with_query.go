@@ -0,0 +1,43 @@
+package rel
+
+// WithQuery defines common table expression of the query.
+// Recursive query is combined with the anchor query using UNION ALL,
+// and it can refer to its own name to access rows produced by the previous iteration.
+type WithQuery struct {
+	Name           string
+	Query          Query
+	Recursive      bool
+	RecursiveQuery Query
+}
+
+// Build query.
+func (wq WithQuery) Build(query *Query) {
+	query.WithQuery = append(query.WithQuery, wq)
+}
+
+// String representation.
+func (wq WithQuery) String() string {
+	if wq.Recursive {
+		return ".WithRecursive(\"" + wq.Name + "\", " + wq.Query.String() + ", " + wq.RecursiveQuery.String() + ")"
+	}
+
+	return ".With(\"" + wq.Name + "\", " + wq.Query.String() + ")"
+}
+
+// NewWith common table expression.
+func NewWith(name string, query Query) WithQuery {
+	return WithQuery{
+		Name:  name,
+		Query: query,
+	}
+}
+
+// NewWithRecursive recursive common table expression.
+func NewWithRecursive(name string, anchor Query, recursive Query) WithQuery {
+	return WithQuery{
+		Name:           name,
+		Query:          anchor,
+		Recursive:      true,
+		RecursiveQuery: recursive,
+	}
+}
@@ -0,0 +1,36 @@
+package rel_test
+
+import (
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWith(t *testing.T) {
+	assert.Equal(t, rel.WithQuery{
+		Name:  "active_users",
+		Query: rel.From("users").Where(where.Eq("active", true)),
+	}, rel.NewWith("active_users", rel.From("users").Where(where.Eq("active", true))))
+}
+
+func TestWithRecursive(t *testing.T) {
+	var (
+		anchor    = rel.From("categories").Where(where.Nil("parent_id"))
+		recursive = rel.From("categories").JoinOn("tree", "categories.parent_id", "tree.id")
+	)
+
+	assert.Equal(t, rel.WithQuery{
+		Name:           "tree",
+		Query:          anchor,
+		Recursive:      true,
+		RecursiveQuery: recursive,
+	}, rel.NewWithRecursive("tree", anchor, recursive))
+}
+
+func TestWithQuery_String(t *testing.T) {
+	assert.Equal(t, ".With(\"active_users\", rel.From(\"users\"))", rel.NewWith("active_users", rel.From("users")).String())
+	assert.Equal(t, ".WithRecursive(\"tree\", rel.From(\"categories\"), rel.From(\"tree\"))",
+		rel.NewWithRecursive("tree", rel.From("categories"), rel.From("tree")).String())
+}